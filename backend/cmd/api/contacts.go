@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/mailer"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+// lookupTargetUser resolves the user named in a request and writes the
+// error response itself, so handlers only need to check ok.
+func (app *application) lookupTargetUser(w http.ResponseWriter, r *http.Request, username string) (*model.User, bool) {
+	v := validator.New()
+	if data.ValidateUsername(v, username); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil, false
+	}
+
+	target, err := app.models.Users.GetByUsername(username)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	if target.UserPid == app.contextGetUser(r).UserPid {
+		v.AddError("username", "must not be your own username")
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil, false
+	}
+
+	return target, true
+}
+
+func (app *application) listContactsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	v := validator.New()
+	filters := app.readFilters(r.URL.Query(), "username", []string{"username", "-username"}, v)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	contacts, metadata, err := app.models.Contacts.GetAll(user.UserPid, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"contacts": contacts, "metadata": metadata}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listContactRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	direction := app.readString(r.URL.Query(), "direction", "incoming")
+
+	v := validator.New()
+	if v.Check(validator.In(direction, "incoming", "outgoing"), "direction", "must be incoming or outgoing"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	requests, err := app.models.Contacts.GetRequests(user.UserPid, direction == "incoming")
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"requests": requests}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) sendContactRequestHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Username string `json:"username"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	target, ok := app.lookupTargetUser(w, r, input.Username)
+	if !ok {
+		return
+	}
+
+	contact, err := app.models.Contacts.Request(user.UserPid, target.UserPid)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRequestPending):
+			app.conflictResponse(w, r, "a contact request is already pending")
+		case errors.Is(err, data.ErrContactExists):
+			app.conflictResponse(w, r, "this user is already in your contacts")
+		case errors.Is(err, data.ErrContactBlocked):
+			app.notPermittedResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if contact.Status == model.ContactAccepted {
+		app.notify(target.UserPid, user.UserPid, model.NotificationContactAccepted, map[string]any{"username": user.Username})
+	} else {
+		app.notify(target.UserPid, user.UserPid, model.NotificationContactRequest, map[string]any{"username": user.Username})
+
+		if app.config.notifications.email {
+			job := mailer.EmailJob{
+				Recipient: target.Email,
+				Template:  "contact_request.tmpl",
+				Data: map[string]any{
+					"Name":      target.Username,
+					"Requester": user.Username,
+					"Mail":      target.Email,
+				},
+			}
+			if err := mailer.EnqueueEmail(app.models.Notifications.Redis, job); err != nil {
+				app.logError(r, err)
+			}
+		}
+	}
+
+	if err = app.writeJson(w, http.StatusCreated, envelope{"contact": contact}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) acceptContactRequestHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	requester, ok := app.lookupTargetUser(w, r, app.readStringPath(r, "username", ""))
+	if !ok {
+		return
+	}
+
+	contact, err := app.models.Contacts.Accept(user.UserPid, requester.UserPid)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.notify(requester.UserPid, user.UserPid, model.NotificationContactAccepted, map[string]any{"username": user.Username})
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"contact": contact}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) declineContactRequestHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	requester, ok := app.lookupTargetUser(w, r, app.readStringPath(r, "username", ""))
+	if !ok {
+		return
+	}
+
+	if err := app.models.Contacts.Decline(user.UserPid, requester.UserPid); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "contact request declined"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) cancelContactRequestHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	addressee, ok := app.lookupTargetUser(w, r, app.readStringPath(r, "username", ""))
+	if !ok {
+		return
+	}
+
+	if err := app.models.Contacts.Cancel(user.UserPid, addressee.UserPid); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "contact request cancelled"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) removeContactHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	contact, ok := app.lookupTargetUser(w, r, app.readStringPath(r, "username", ""))
+	if !ok {
+		return
+	}
+
+	if err := app.models.Contacts.Remove(user.UserPid, contact.UserPid); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "contact removed"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listBlockedUsersHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	blocked, err := app.models.Contacts.GetBlocked(user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"blocked": blocked}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) blockUserHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	target, ok := app.lookupTargetUser(w, r, app.readStringPath(r, "username", ""))
+	if !ok {
+		return
+	}
+
+	if err := app.models.Contacts.Block(user.UserPid, target.UserPid); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "user blocked"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) unblockUserHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	target, ok := app.lookupTargetUser(w, r, app.readStringPath(r, "username", ""))
+	if !ok {
+		return
+	}
+
+	if err := app.models.Contacts.Unblock(user.UserPid, target.UserPid); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "user unblocked"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// readConversation loads the conversation named in the path for the current
+// user. Conversations the user isn't part of are reported as not found.
+func (app *application) readConversation(w http.ResponseWriter, r *http.Request) (*model.Conversation, bool) {
+	conversationID, err := app.readIDPath(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	user := app.contextGetUser(r)
+
+	conversation, err := app.models.Conversations.Get(conversationID, user.UserPid)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	if err := app.attachUnread(user.UserPid, conversation); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return nil, false
+	}
+
+	return conversation, true
+}
+
+// readMembership resolves the conversation named in the path to its cached
+// membership, which is enough to authorize most requests. Non-participants
+// get a not found response.
+func (app *application) readMembership(w http.ResponseWriter, r *http.Request) (int64, *model.Membership, bool) {
+	conversationID, err := app.readIDPath(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return 0, nil, false
+	}
+
+	membership, err := app.models.Conversations.Membership(conversationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return 0, nil, false
+	}
+
+	if !membership.IsMember(app.contextGetUser(r).UserPid) {
+		app.notFoundResponse(w, r)
+		return 0, nil, false
+	}
+
+	return conversationID, membership, true
+}
+
+// canMessage reports whether the recipient's privacy settings and blocks let
+// the sender start or continue a direct conversation with them.
+func (app *application) canMessage(senderID, recipientID int64) (bool, error) {
+	rel, err := app.models.Contacts.Relationship(recipientID, senderID)
+	if err != nil {
+		return false, err
+	}
+
+	privacy, err := app.models.Privacy.Get(recipientID)
+	if err != nil {
+		return false, err
+	}
+
+	return privacy.Allows(privacy.WhoCanMessage, rel), nil
+}
+
+func (app *application) openDirectConversationHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Username string `json:"username"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	target, ok := app.lookupTargetUser(w, r, input.Username)
+	if !ok {
+		return
+	}
+
+	allowed, err := app.canMessage(user.UserPid, target.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !allowed {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	conversation, created, err := app.models.Conversations.GetOrCreateDirect(user.UserPid, target.UserPid, user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.attachUnread(user.UserPid, conversation); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+		app.publishConversationUpdated(conversation.ConversationID)
+	}
+
+	if err = app.writeJson(w, status, envelope{"conversation": conversation}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listConversationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	v := validator.New()
+	filters := app.readFilters(r.URL.Query(), "-activity", []string{"-activity"}, v)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	conversations, metadata, err := app.models.Conversations.GetAllForUser(user.UserPid, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.attachUnread(user.UserPid, conversations...); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"conversations": conversations, "metadata": metadata}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) getConversationHandler(w http.ResponseWriter, r *http.Request) {
+	conversation, ok := app.readConversation(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"conversation": conversation}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) sendMessageHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Body        string   `json:"body"`
+		ReplyTo     string   `json:"replyTo"`
+		ThreadID    string   `json:"threadId"`
+		Attachments []string `json:"attachments"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	conversationID, membership, ok := app.readMembership(w, r)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	if input.Body != "" || len(input.Attachments) == 0 {
+		data.ValidateMessageBody(v, input.Body)
+	}
+
+	attachments, err := app.readAttachments(user.UserPid, input.Attachments, v)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	mentions, err := app.readMentions(conversationID, user.UserPid, membership, input.Body, v)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var root, parent *model.Message
+
+	if input.ThreadID != "" {
+		root, err = app.readReplyTarget(conversationID, input.ThreadID, "threadId", v)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if root != nil {
+			v.Check(root.IsThreadRoot(), "threadId", "must be the first message of a thread")
+		}
+	}
+
+	if input.ReplyTo != "" {
+		parent, err = app.readReplyTarget(conversationID, input.ReplyTo, "replyTo", v)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if parent != nil && root != nil {
+			inThread := parent.MessageID == root.MessageID || parent.ThreadID.Int64 == root.MessageID
+			v.Check(inThread, "replyTo", "must be a message in the same thread")
+		}
+		if parent != nil && root == nil {
+			v.Check(parent.IsThreadRoot(), "replyTo", "must be a message in the same thread")
+		}
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	allowed, err := app.canSend(user.UserPid, membership)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !allowed {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	message := &model.Message{
+		Message: db.Message{
+			ConversationID: conversationID,
+			SenderPid:      pgtype.Int8{Int64: user.UserPid, Valid: true},
+			Kind:           model.MessageText,
+			Body:           input.Body,
+		},
+	}
+
+	if parent != nil {
+		message.ParentID = pgtype.Int8{Int64: parent.MessageID, Valid: true}
+
+		message.Quote, err = model.NewQuote(parent)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	if root != nil {
+		message.ThreadID = pgtype.Int8{Int64: root.MessageID, Valid: true}
+	}
+
+	message.Entities, err = model.NewEntities(mentions)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	mentionedIDs, err := app.mentionedUserIDs(user.UserPid, mentions, membership)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The message and its attachments are stored together, so a message is
+	// never announced without the files it was sent with.
+	err = app.models.Transaction(func(tx data.Models) error {
+		if err := tx.Messages.Insert(message); err != nil {
+			return err
+		}
+
+		if len(attachments) > 0 {
+			return tx.Attachments.Link(message, attachments)
+		}
+
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.announceMessage(message, root, membership, mentionedIDs)
+
+	if err := app.writeJson(w, http.StatusCreated, envelope{"message": message}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	conversationID, _, ok := app.readMembership(w, r)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	query := app.readHistoryQuery(r.URL.Query(), v)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	messages, cursors, err := app.models.Messages.GetHistory(conversationID, app.contextGetUser(r).UserPid, query)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"messages": messages, "cursors": cursors}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// canChangeSettings reports whether userID may change the settings of a
+// conversation: anyone in a direct conversation, admins in a group.
+func canChangeSettings(conversation *model.Conversation, userID int64) bool {
+	participant := conversation.Participant(userID)
+	if participant == nil {
+		return false
+	}
+
+	return conversation.Kind == model.ConversationDirect || model.IsManager(participant.Role)
+}
+
+func (app *application) updateConversationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	conversation, ok := app.readConversation(w, r)
+	if !ok {
+		return
+	}
+
+	if !canChangeSettings(conversation, user.UserPid) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	var input struct {
+		LinkPreviews    *bool   `json:"linkPreviews"`
+		MentionAll      *string `json:"mentionAll"`
+		MessageTimer    *string `json:"messageTimer"`
+		AllowForwarding *bool   `json:"allowForwarding"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if input.LinkPreviews != nil {
+		conversation.LinkPreviews = *input.LinkPreviews
+	}
+
+	if input.AllowForwarding != nil {
+		conversation.AllowForwarding = *input.AllowForwarding
+	}
+
+	if input.MentionAll != nil {
+		v.Check(conversation.Kind == model.ConversationGroup, "mentionAll", "can only be set in groups")
+		v.Check(validator.In(*input.MentionAll, model.MentionAllEveryone, model.MentionAllAdmins), "mentionAll", "must be everyone or admins")
+		conversation.MentionAll = *input.MentionAll
+	}
+
+	previousTTL := conversation.MessageTtl
+	if input.MessageTimer != nil {
+		ttl, ok := model.MessageTimers[*input.MessageTimer]
+		v.Check(ok, "messageTimer", "must be off, 1h, 24h or 7d")
+		conversation.MessageTtl = ttl
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Conversations.UpdateSettings(conversation); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Messages keep the expiry they were sent with, so a new timer only
+	// applies from here on.
+	if conversation.MessageTtl != previousTTL {
+		event := model.NewSystemEvent(model.SystemTimerChanged, user.UserPid)
+		event.Timer = model.MessageTimer(conversation.MessageTtl)
+		app.postSystemMessage(conversation.ConversationID, event)
+	} else {
+		app.publishConversationUpdated(conversation.ConversationID)
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"conversation": conversation}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// muteConversationHandler mutes a conversation for the current user until
+// the given time, or indefinitely if none is given. Muting silences thread
+// reply notifications; mentions still notify.
+func (app *application) muteConversationHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Until *time.Time `json:"until"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	until := pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true}
+	if input.Until != nil {
+		v := validator.New()
+		if v.Check(input.Until.After(time.Now()), "until", "must be in the future"); !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+		until = pgtype.Timestamptz{Time: *input.Until, Valid: true}
+	}
+
+	app.setMuted(w, r, until)
+}
+
+func (app *application) unmuteConversationHandler(w http.ResponseWriter, r *http.Request) {
+	app.setMuted(w, r, pgtype.Timestamptz{})
+}
+
+func (app *application) setMuted(w http.ResponseWriter, r *http.Request, until pgtype.Timestamptz) {
+	conversationID, err := app.readIDPath(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if err := app.models.Conversations.Mute(conversationID, user.UserPid, until); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"mutedUntil": until}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+)
+
+// reapBatchSize is how many expired messages are deleted per query.
+const reapBatchSize = 500
+
+// reapExpiredMessages periodically deletes disappearing messages whose
+// timer ran out. History queries already hide them, this frees the space
+// and tells clients to drop their copies. Every instance runs it; rows
+// another instance is deleting are skipped.
+func (app *application) reapExpiredMessages() {
+	ticker := time.NewTicker(app.config.messages.reapInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for {
+			expired, n, err := app.models.Messages.DeleteExpired(reapBatchSize)
+			if err != nil {
+				app.logger.PrintError(err, nil)
+				break
+			}
+
+			for conversationID, messages := range expired {
+				app.announceExpired(conversationID, messages)
+			}
+
+			if n < reapBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// announceExpired broadcasts the deletion of expired messages and of the
+// pins they took with them, and recounts the unread counters they may have
+// been part of.
+func (app *application) announceExpired(conversationID int64, expired *data.ExpiredMessages) {
+	membership, err := app.models.Conversations.Membership(conversationID)
+	if err != nil {
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			app.logger.PrintError(err, map[string]any{"conversation_id": conversationID})
+		}
+		return
+	}
+
+	for _, messageID := range expired.MessageIDs {
+		app.publish(membership.UserIDs(), realtime.EventMessageDeleted, &model.MessageDeletedEvent{
+			ConversationID: conversationID,
+			MessageID:      messageID,
+			Scope:          model.DeleteScopeExpired,
+		})
+	}
+
+	for _, messageID := range expired.UnpinnedIDs {
+		app.publish(membership.UserIDs(), realtime.EventPinRemoved, &model.PinEvent{
+			ConversationID: conversationID,
+			MessageID:      messageID,
+		})
+	}
+
+	for _, userID := range membership.UserIDs() {
+		app.recountUnread(conversationID, userID)
+	}
+}
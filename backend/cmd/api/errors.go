@@ -91,3 +91,8 @@ func (app *application) notPermittedResponse(w http.ResponseWriter, r *http.Requ
 	message := "your user account doesn't have the necessary permissions to access the resource"
 	app.errorResponse(w, r, http.StatusForbidden, message)
 }
+
+func (app *application) editWindowExpiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "this message can no longer be changed"
+	app.errorResponse(w, r, http.StatusForbidden, message)
+}
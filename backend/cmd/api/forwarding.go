@@ -0,0 +1,233 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	maxForwardMessages = 20
+	maxForwardTargets  = 5
+)
+
+type forwardTarget struct {
+	conversationID int64
+	membership     *model.Membership
+}
+
+// readForwardSources loads the messages to forward from a conversation,
+// oldest first so they arrive in their original order.
+func (app *application) readForwardSources(conversationID int64, ids []string, v *validator.Validator) ([]*model.Message, error) {
+	sources := make([]*model.Message, 0, len(ids))
+
+	for _, id := range ids {
+		messageID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil || messageID < 1 {
+			v.AddError("messageIds", "must contain valid message ids")
+			return nil, nil
+		}
+
+		message, err := app.models.Messages.Get(conversationID, messageID)
+		if err != nil {
+			if errors.Is(err, data.ErrRecordNotFound) {
+				v.AddError("messageIds", "must be messages in this conversation")
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		if message.Kind != model.MessageText || message.IsDeleted() {
+			v.AddError("messageIds", "must not contain messages that can't be forwarded")
+			return nil, nil
+		}
+
+		sources = append(sources, message)
+	}
+
+	slices.SortFunc(sources, func(a, b *model.Message) int {
+		return cmp.Compare(a.MessageID, b.MessageID)
+	})
+
+	return sources, nil
+}
+
+// readForwardTargets resolves the conversations to forward into. The user
+// must belong to each of them and be allowed to send there.
+func (app *application) readForwardTargets(userID int64, ids []string, v *validator.Validator) ([]*forwardTarget, error) {
+	targets := make([]*forwardTarget, 0, len(ids))
+
+	for _, id := range ids {
+		conversationID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil || conversationID < 1 {
+			v.AddError("conversationIds", "must contain valid conversation ids")
+			return nil, nil
+		}
+
+		membership, err := app.models.Conversations.Membership(conversationID)
+		if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		if membership == nil || !membership.IsMember(userID) {
+			v.AddError("conversationIds", "must be conversations you belong to")
+			return nil, nil
+		}
+
+		allowed, err := app.canSend(userID, membership)
+		if err != nil {
+			return nil, err
+		}
+
+		if !allowed {
+			v.AddError("conversationIds", "must not contain conversations you can't send messages to")
+			return nil, nil
+		}
+
+		targets = append(targets, &forwardTarget{conversationID: conversationID, membership: membership})
+	}
+
+	return targets, nil
+}
+
+// forwardOrigin builds the origin marker of a forwarded message, naming the
+// original sender unless their privacy settings hide it.
+func (app *application) forwardOrigin(source *model.Message) ([]byte, error) {
+	showSender := false
+
+	if source.SenderPid.Valid {
+		settings, err := app.models.Privacy.Get(source.SenderPid.Int64)
+		if err != nil {
+			return nil, err
+		}
+		showSender = settings.ForwardAttribution
+	}
+
+	return model.NewForward(source, showSender)
+}
+
+// forwardMessagesHandler copies messages of a conversation into other
+// conversations of the user's. Attachments are shared rather than
+// uploaded again. Mentions, replies and threads are not carried over.
+func (app *application) forwardMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		MessageIDs      []string `json:"messageIds"`
+		ConversationIDs []string `json:"conversationIds"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	conversationID, membership, ok := app.readMembership(w, r)
+	if !ok {
+		return
+	}
+
+	if membership.ForwardingRestricted {
+		app.errorResponse(w, r, http.StatusForbidden, "forwarding is turned off in this conversation")
+		return
+	}
+
+	v := validator.New()
+	v.Check(len(input.MessageIDs) > 0, "messageIds", "must contain at least one message")
+	v.Check(len(input.MessageIDs) <= maxForwardMessages, "messageIds", "must not contain more than 20 messages")
+	v.Check(validator.Unique(input.MessageIDs), "messageIds", "must not contain duplicate values")
+	v.Check(len(input.ConversationIDs) > 0, "conversationIds", "must contain at least one conversation")
+	v.Check(len(input.ConversationIDs) <= maxForwardTargets, "conversationIds", "must not contain more than 5 conversations")
+	v.Check(validator.Unique(input.ConversationIDs), "conversationIds", "must not contain duplicate values")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	sources, err := app.readForwardSources(conversationID, input.MessageIDs, v)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	targets, err := app.readForwardTargets(user.UserPid, input.ConversationIDs, v)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	origins := make([][]byte, 0, len(sources))
+	for _, source := range sources {
+		origin, err := app.forwardOrigin(source)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		origins = append(origins, origin)
+	}
+
+	messages := make([]*model.Message, 0, len(sources)*len(targets))
+	memberships := make([]*model.Membership, 0, len(sources)*len(targets))
+
+	// Every copy is made in one transaction, so a failure part way doesn't
+	// leave the forward half done. Nothing is announced until it commits.
+	err = app.models.Transaction(func(tx data.Models) error {
+		for _, target := range targets {
+			for i, source := range sources {
+				message := &model.Message{
+					Message: db.Message{
+						ConversationID: target.conversationID,
+						SenderPid:      pgtype.Int8{Int64: user.UserPid, Valid: true},
+						Kind:           model.MessageText,
+						Body:           source.Body,
+						Forward:        origins[i],
+					},
+				}
+
+				if err := tx.Messages.Insert(message); err != nil {
+					return err
+				}
+
+				if err := tx.Attachments.Copy(source, message, app.config.uploads.quota); err != nil {
+					return err
+				}
+
+				messages = append(messages, message)
+				memberships = append(memberships, target.membership)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrQuotaExceeded):
+			app.errorResponse(w, r, http.StatusRequestEntityTooLarge, err.Error())
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	for i, message := range messages {
+		app.announceMessage(message, nil, memberships[i], nil)
+	}
+
+	if err := app.writeJson(w, http.StatusCreated, envelope{"messages": messages}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,505 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// postSystemMessage records a timeline event and pushes it to the
+// participants. The change it describes has already been applied, so failures
+// are logged instead of failing the request.
+func (app *application) postSystemMessage(conversationID int64, event *model.SystemEvent, extraUserIDs ...int64) {
+	message, err := model.NewSystemMessage(conversationID, event)
+	if err != nil {
+		app.logger.PrintError(err, nil)
+		return
+	}
+
+	if err := app.models.Messages.Insert(message); err != nil {
+		app.logger.PrintError(err, map[string]any{"event": event.Event})
+		return
+	}
+
+	app.publishToConversation(conversationID, realtime.EventMessageCreated, message, extraUserIDs...)
+	app.publishConversationUpdated(conversationID, extraUserIDs...)
+}
+
+// lookupMembers resolves usernames of users to add to a group, rejecting
+// anyone who doesn't accept messages from the current user.
+func (app *application) lookupMembers(w http.ResponseWriter, r *http.Request, usernames []string) ([]int64, bool) {
+	user := app.contextGetUser(r)
+
+	v := validator.New()
+	v.Check(validator.Unique(usernames), "usernames", "must not contain duplicate values")
+	for _, username := range usernames {
+		data.ValidateUsername(v, username)
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil, false
+	}
+
+	ids := make([]int64, 0, len(usernames))
+
+	for _, username := range usernames {
+		member, err := app.models.Users.GetByUsername(username)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				v.AddError("usernames", username+" does not exist")
+				app.failedValidationResponse(w, r, v.Errors)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return nil, false
+		}
+
+		if member.UserPid == user.UserPid {
+			continue
+		}
+
+		allowed, err := app.canMessage(user.UserPid, member.UserPid)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return nil, false
+		}
+
+		if !allowed {
+			v.AddError("usernames", username+" can't be added by you")
+			app.failedValidationResponse(w, r, v.Errors)
+			return nil, false
+		}
+
+		ids = append(ids, member.UserPid)
+	}
+
+	return ids, true
+}
+
+// readGroupMembership is readMembership for endpoints that only make sense in
+// group conversations.
+func (app *application) readGroupMembership(w http.ResponseWriter, r *http.Request) (int64, *model.Membership, bool) {
+	conversationID, membership, ok := app.readMembership(w, r)
+	if !ok {
+		return 0, nil, false
+	}
+
+	if membership.Kind != model.ConversationGroup {
+		app.notFoundResponse(w, r)
+		return 0, nil, false
+	}
+
+	return conversationID, membership, true
+}
+
+// readGroupMember resolves the member named in the path of a group request.
+func (app *application) readGroupMember(w http.ResponseWriter, r *http.Request, membership *model.Membership) (*model.User, bool) {
+	target, ok := app.lookupTargetUser(w, r, app.readStringPath(r, "username", ""))
+	if !ok {
+		return nil, false
+	}
+
+	if !membership.IsMember(target.UserPid) {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	return target, true
+}
+
+func (app *application) writeConversation(w http.ResponseWriter, r *http.Request, status int, conversationID int64) {
+	user := app.contextGetUser(r)
+
+	conversation, err := app.models.Conversations.Get(conversationID, user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.attachUnread(user.UserPid, conversation); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, status, envelope{"conversation": conversation}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) createGroupHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		Avatar      string   `json:"avatar"`
+		Usernames   []string `json:"usernames"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	conversation := &model.Conversation{}
+	conversation.Title = pgtype.Text{String: input.Title, Valid: true}
+	conversation.Description = pgtype.Text{String: input.Description, Valid: true}
+	conversation.Avatar = pgtype.Text{String: input.Avatar, Valid: true}
+
+	v := validator.New()
+	data.ValidateGroup(v, conversation)
+	v.Check(len(input.Usernames) < data.MaxGroupMembers, "usernames", "must not contain more than 255 users")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	memberIDs, ok := app.lookupMembers(w, r, input.Usernames)
+	if !ok {
+		return
+	}
+
+	if err := app.models.Conversations.CreateGroup(conversation, user.UserPid, memberIDs); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.postSystemMessage(conversation.ConversationID, model.NewSystemEvent(model.SystemGroupCreated, user.UserPid, memberIDs...))
+
+	app.writeConversation(w, r, http.StatusCreated, conversation.ConversationID)
+}
+
+func (app *application) updateGroupHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	conversation, ok := app.readConversation(w, r)
+	if !ok {
+		return
+	}
+
+	if conversation.Kind != model.ConversationGroup {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if participant := conversation.Participant(user.UserPid); participant == nil || !model.IsManager(participant.Role) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Title       *string `json:"title"`
+		Description *string `json:"description"`
+		Avatar      *string `json:"avatar"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	oldTitle := conversation.Title.String
+
+	if input.Title != nil {
+		conversation.Title = pgtype.Text{String: *input.Title, Valid: true}
+	}
+	if input.Description != nil {
+		conversation.Description = pgtype.Text{String: *input.Description, Valid: true}
+	}
+	if input.Avatar != nil {
+		conversation.Avatar = pgtype.Text{String: *input.Avatar, Valid: true}
+	}
+
+	v := validator.New()
+	if data.ValidateGroup(v, conversation); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Conversations.UpdateGroup(conversation); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if conversation.Title.String != oldTitle {
+		event := model.NewSystemEvent(model.SystemGroupRenamed, user.UserPid)
+		event.Title = conversation.Title.String
+		app.postSystemMessage(conversation.ConversationID, event)
+	} else if input.Description != nil || input.Avatar != nil {
+		app.postSystemMessage(conversation.ConversationID, model.NewSystemEvent(model.SystemGroupUpdated, user.UserPid))
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"conversation": conversation}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) addGroupMembersHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Usernames []string `json:"usernames"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	conversationID, membership, ok := app.readGroupMembership(w, r)
+	if !ok {
+		return
+	}
+
+	if !model.IsManager(membership.Role(user.UserPid)) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	v := validator.New()
+	v.Check(len(input.Usernames) > 0, "usernames", "must contain at least 1 user")
+	v.Check(len(membership.Roles)+len(input.Usernames) <= data.MaxGroupMembers, "usernames", "would make the group larger than 256 members")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	memberIDs, ok := app.lookupMembers(w, r, input.Usernames)
+	if !ok {
+		return
+	}
+
+	added, err := app.models.Conversations.AddMembers(conversationID, memberIDs)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if len(added) > 0 {
+		app.postSystemMessage(conversationID, model.NewSystemEvent(model.SystemMemberAdded, user.UserPid, added...))
+	}
+
+	app.writeConversation(w, r, http.StatusOK, conversationID)
+}
+
+func (app *application) removeGroupMemberHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	conversationID, membership, ok := app.readGroupMembership(w, r)
+	if !ok {
+		return
+	}
+
+	target, ok := app.readGroupMember(w, r, membership)
+	if !ok {
+		return
+	}
+
+	role := membership.Role(user.UserPid)
+	if !model.IsManager(role) || model.RoleRank(role) <= model.RoleRank(membership.Role(target.UserPid)) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	if err := app.models.Conversations.RemoveMember(conversationID, target.UserPid); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.cancelScheduledMessages(conversationID, target.UserPid)
+
+	app.postSystemMessage(conversationID, model.NewSystemEvent(model.SystemMemberRemoved, user.UserPid, target.UserPid), target.UserPid)
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "member removed"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) promoteGroupMemberHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	conversationID, membership, ok := app.readGroupMembership(w, r)
+	if !ok {
+		return
+	}
+
+	target, ok := app.readGroupMember(w, r, membership)
+	if !ok {
+		return
+	}
+
+	if !model.IsManager(membership.Role(user.UserPid)) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	if membership.Role(target.UserPid) != model.RoleMember {
+		app.conflictResponse(w, r, "this user is already an admin")
+		return
+	}
+
+	if err := app.models.Conversations.SetRole(conversationID, target.UserPid, model.RoleMember, model.RoleAdmin); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.postSystemMessage(conversationID, model.NewSystemEvent(model.SystemMemberPromoted, user.UserPid, target.UserPid))
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "member promoted to admin"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) demoteGroupAdminHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	conversationID, membership, ok := app.readGroupMembership(w, r)
+	if !ok {
+		return
+	}
+
+	target, ok := app.readGroupMember(w, r, membership)
+	if !ok {
+		return
+	}
+
+	if membership.Role(target.UserPid) != model.RoleAdmin {
+		app.conflictResponse(w, r, "this user is not an admin")
+		return
+	}
+
+	if model.RoleRank(membership.Role(user.UserPid)) <= model.RoleRank(model.RoleAdmin) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	if err := app.models.Conversations.SetRole(conversationID, target.UserPid, model.RoleAdmin, model.RoleMember); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.postSystemMessage(conversationID, model.NewSystemEvent(model.SystemMemberDemoted, user.UserPid, target.UserPid))
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "admin demoted to member"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) transferGroupOwnershipHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Username string `json:"username"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	conversationID, membership, ok := app.readGroupMembership(w, r)
+	if !ok {
+		return
+	}
+
+	if membership.Role(user.UserPid) != model.RoleOwner {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	target, ok := app.lookupTargetUser(w, r, input.Username)
+	if !ok {
+		return
+	}
+
+	if !membership.IsMember(target.UserPid) {
+		v := validator.New()
+		v.AddError("username", "must be a member of the group")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Conversations.TransferOwnership(conversationID, user.UserPid, target.UserPid); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.postSystemMessage(conversationID, model.NewSystemEvent(model.SystemOwnershipTransferred, user.UserPid, target.UserPid))
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "ownership transferred"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) leaveGroupHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	conversationID, membership, ok := app.readGroupMembership(w, r)
+	if !ok {
+		return
+	}
+
+	if membership.Role(user.UserPid) == model.RoleOwner {
+		if len(membership.Roles) > 1 {
+			app.conflictResponse(w, r, "transfer ownership before leaving the group")
+			return
+		}
+
+		if err := app.models.Conversations.Delete(conversationID); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		app.publish([]int64{user.UserPid}, realtime.EventConversationUpdated, map[string]any{"conversationId": strconv.FormatInt(conversationID, 10)})
+	} else {
+		if err := app.models.Conversations.RemoveMember(conversationID, user.UserPid); err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				app.notFoundResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		app.cancelScheduledMessages(conversationID, user.UserPid)
+
+		app.postSystemMessage(conversationID, model.NewSystemEvent(model.SystemMemberLeft, user.UserPid), user.UserPid)
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "you left the group"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -10,9 +10,10 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/RickinShah/BuzzChat/internal/data"
 	"github.com/RickinShah/BuzzChat/internal/validator"
-	"github.com/sony/sonyflake/v2"
 )
 
 type envelope map[string]any
@@ -99,6 +100,36 @@ func (app *application) readInt(qs url.Values, key string, defaultValue int, v *
 	return i
 }
 
+func (app *application) readID(qs url.Values, key string, v *validator.Validator) int64 {
+	s := qs.Get(key)
+	if s == "" {
+		return 0
+	}
+
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id < 1 {
+		v.AddError(key, "must be a valid id")
+		return 0
+	}
+
+	return id
+}
+
+func (app *application) readTime(qs url.Values, key string, v *validator.Validator) time.Time {
+	s := qs.Get(key)
+	if s == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		v.AddError(key, "must be an RFC 3339 timestamp")
+		return time.Time{}
+	}
+
+	return t
+}
+
 func (app *application) readString(qs url.Values, key string, defaultValue string) string {
 	s := qs.Get(key)
 
@@ -119,6 +150,20 @@ func (app *application) readCSV(qs url.Values, key string, defaultValue []string
 	return strings.Split(csv, ",")
 }
 
+func (app *application) readFilters(qs url.Values, defaultSort string, sortSafelist []string, v *validator.Validator) data.Filters {
+	filters := data.Filters{
+		Page:         app.readInt(qs, "page", 1, v),
+		PageSize:     app.readInt(qs, "page_size", 20, v),
+		Sort:         app.readString(qs, "sort", defaultSort),
+		SortSafelist: sortSafelist,
+		Cursor:       app.readString(qs, "cursor", ""),
+	}
+
+	data.ValidateFilters(v, filters)
+
+	return filters
+}
+
 func (app *application) readStringPath(r *http.Request, key string, defaultValue string) string {
 	value := r.PathValue(key)
 	if value == "" {
@@ -128,12 +173,17 @@ func (app *application) readStringPath(r *http.Request, key string, defaultValue
 	return value
 }
 
-func (app *application) generateID() (int64, error) {
-	sf, err := sonyflake.New(sonyflake.Settings{})
-	if err != nil {
-		return 0, err
+func (app *application) readIDPath(r *http.Request, key string) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue(key), 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid %s parameter", key)
 	}
-	return sf.NextID()
+
+	return id, nil
+}
+
+func (app *application) generateID() (int64, error) {
+	return app.ids.NextID()
 }
 
 func (app *application) background(fn func()) {
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+	"github.com/RickinShah/BuzzChat/internal/unfurl"
+)
+
+const jobUnfurlLink = "link.unfurl"
+
+type unfurlLinkPayload struct {
+	ConversationID int64  `json:"conversationId"`
+	MessageID      int64  `json:"messageId"`
+	URL            string `json:"url"`
+}
+
+// enqueueLinkPreview queues a preview of the first link in a message, unless
+// the conversation has previews turned off.
+func (app *application) enqueueLinkPreview(message *model.Message, membership *model.Membership) {
+	if !membership.LinkPreviews {
+		return
+	}
+
+	url := unfurl.FirstURL(message.Body)
+	if url == "" {
+		return
+	}
+
+	payload := unfurlLinkPayload{
+		ConversationID: message.ConversationID,
+		MessageID:      message.MessageID,
+		URL:            url,
+	}
+
+	if err := app.jobs.Enqueue(jobUnfurlLink, payload); err != nil {
+		app.logger.PrintError(err, map[string]any{"message_id": message.MessageID})
+	}
+}
+
+// updateLinkPreview is called after an edit. It drops the old preview if the
+// first link changed and queues one for the new link.
+func (app *application) updateLinkPreview(message *model.Message, previousBody string, membership *model.Membership) {
+	if unfurl.FirstURL(message.Body) == unfurl.FirstURL(previousBody) {
+		return
+	}
+
+	removed, err := app.models.LinkPreviews.Delete(message.MessageID)
+	if err != nil {
+		app.logger.PrintError(err, map[string]any{"message_id": message.MessageID})
+		return
+	}
+
+	if removed {
+		update := &model.LinkPreviewUpdate{ConversationID: message.ConversationID, MessageID: message.MessageID}
+		app.publish(membership.UserIDs(), realtime.EventMessagePreview, update)
+	}
+
+	app.enqueueLinkPreview(message, membership)
+}
+
+// unfurlLinkJob fetches a preview, or takes it from the cache, and attaches
+// it to the message. Failed fetches aren't retried: most are pages without
+// metadata or hosts we refuse to talk to, and retrying won't change that.
+func (app *application) unfurlLinkJob(ctx context.Context, payload json.RawMessage) error {
+	var input unfurlLinkPayload
+	if err := json.Unmarshal(payload, &input); err != nil {
+		return err
+	}
+
+	membership, err := app.models.Conversations.Membership(input.ConversationID)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// Previews may have been turned off, or the message edited or deleted,
+	// while the job was queued.
+	if !membership.LinkPreviews {
+		return nil
+	}
+
+	message, err := app.models.Messages.Get(input.ConversationID, input.MessageID)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if message.IsDeleted() || unfurl.FirstURL(message.Body) != input.URL {
+		return nil
+	}
+
+	preview, found := app.models.LinkPreviews.GetCached(input.URL)
+	if !found {
+		fetched, err := app.unfurler.Fetch(ctx, input.URL)
+		if err != nil {
+			app.logger.PrintInfo("no link preview: "+err.Error(), map[string]any{"message_id": input.MessageID})
+		} else {
+			preview = (*model.LinkPreview)(fetched)
+		}
+
+		app.models.LinkPreviews.Cache(input.URL, preview)
+	}
+
+	if preview == nil {
+		return nil
+	}
+
+	if err := app.models.LinkPreviews.Set(input.MessageID, preview); err != nil {
+		return err
+	}
+
+	update := &model.LinkPreviewUpdate{
+		ConversationID: input.ConversationID,
+		MessageID:      input.MessageID,
+		LinkPreview:    preview,
+	}
+	app.publish(membership.UserIDs(), realtime.EventMessagePreview, update)
+
+	return nil
+}
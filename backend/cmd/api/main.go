@@ -7,12 +7,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/RickinShah/BuzzChat/internal/blob"
 	"github.com/RickinShah/BuzzChat/internal/data"
-	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/jobs"
 	"github.com/RickinShah/BuzzChat/internal/jsonlog"
 	"github.com/RickinShah/BuzzChat/internal/mailer"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+	"github.com/RickinShah/BuzzChat/internal/unfurl"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
+	"github.com/sony/sonyflake/v2"
 )
 
 type config struct {
@@ -29,6 +33,43 @@ type config struct {
 		password string
 		sender   string
 	}
+	notifications struct {
+		email bool
+	}
+	events struct {
+		bus string
+	}
+	messages struct {
+		editWindow   time.Duration
+		deleteWindow time.Duration
+		reapInterval time.Duration
+	}
+	unread struct {
+		reconcileInterval time.Duration
+	}
+	storage struct {
+		backend string
+		path    string
+		s3      blob.S3Config
+	}
+	uploads struct {
+		maxSize    int64
+		quota      int64
+		gcInterval time.Duration
+	}
+	jobs struct {
+		workers int
+	}
+	linkPreviews struct {
+		timeout      time.Duration
+		allowPrivate bool
+	}
+	scheduler struct {
+		interval time.Duration
+	}
+	polls struct {
+		closeInterval time.Duration
+	}
 	port          int
 	env           string
 	clients       []string
@@ -36,10 +77,14 @@ type config struct {
 }
 
 type application struct {
-	config config
-	logger *jsonlog.Logger
-	mailer mailer.Mailer
-	models data.Models
+	config   config
+	logger   *jsonlog.Logger
+	mailer   mailer.Mailer
+	models   data.Models
+	hub      *realtime.Hub
+	jobs     *jobs.Queue
+	unfurler *unfurl.Fetcher
+	ids      *sonyflake.Sonyflake
 }
 
 func main() {
@@ -56,6 +101,37 @@ func main() {
 	flag.StringVar(&cfg.smtp.password, "smtp-password", "", "SMTP password")
 	flag.StringVar(&cfg.smtp.sender, "smtp-sender", "BuzzChat <no-reply@gmail.com>", "SMTP sender")
 
+	flag.BoolVar(&cfg.notifications.email, "notification-emails", false, "Send emails for contact requests")
+
+	flag.StringVar(&cfg.events.bus, "event-bus", "redis", "Real-time event bus (redis|memory)")
+
+	flag.DurationVar(&cfg.messages.editWindow, "message-edit-window", 15*time.Minute, "How long senders can edit their messages")
+	flag.DurationVar(&cfg.messages.deleteWindow, "message-delete-window", time.Hour, "How long senders can delete their messages for everyone")
+	flag.DurationVar(&cfg.messages.reapInterval, "message-reap-interval", time.Minute, "How often expired disappearing messages are deleted")
+
+	flag.DurationVar(&cfg.unread.reconcileInterval, "unread-reconcile-interval", 15*time.Minute, "How often unread counters are rebuilt from the database")
+
+	flag.StringVar(&cfg.storage.backend, "storage-backend", "local", "Attachment storage (local|s3)")
+	flag.StringVar(&cfg.storage.path, "storage-path", "./storage", "Directory for local attachment storage")
+	flag.StringVar(&cfg.storage.s3.Endpoint, "s3-endpoint", "localhost:9000", "S3 endpoint")
+	flag.StringVar(&cfg.storage.s3.Bucket, "s3-bucket", "buzzchat", "S3 bucket")
+	flag.StringVar(&cfg.storage.s3.AccessKey, "s3-access-key", "", "S3 access key")
+	flag.StringVar(&cfg.storage.s3.SecretKey, "s3-secret-key", "", "S3 secret key")
+	flag.BoolVar(&cfg.storage.s3.UseSSL, "s3-use-ssl", false, "Use TLS for S3")
+
+	flag.Int64Var(&cfg.uploads.maxSize, "upload-max-size", 100<<20, "Largest file that can be uploaded, in bytes")
+	flag.Int64Var(&cfg.uploads.quota, "upload-quota", 1<<30, "Storage quota per user, in bytes")
+	flag.DurationVar(&cfg.uploads.gcInterval, "upload-gc-interval", time.Hour, "How often expired uploads and orphaned files are removed")
+
+	flag.IntVar(&cfg.jobs.workers, "job-workers", 2, "Background jobs run at the same time")
+
+	flag.DurationVar(&cfg.linkPreviews.timeout, "link-preview-timeout", 5*time.Second, "How long fetching a link preview may take")
+	flag.BoolVar(&cfg.linkPreviews.allowPrivate, "link-preview-allow-private", false, "Allow link previews of private and loopback addresses (for local testing only)")
+
+	flag.DurationVar(&cfg.scheduler.interval, "scheduler-interval", 5*time.Second, "How often due scheduled messages are sent")
+
+	flag.DurationVar(&cfg.polls.closeInterval, "poll-close-interval", 10*time.Second, "How often polls past their close time are closed")
+
 	clients := flag.String("clients", "http://localhost:5173", "Client URLs for CORS")
 
 	flag.Parse()
@@ -80,22 +156,95 @@ func main() {
 
 	logger.PrintInfo("valkey redis connection established", nil)
 
+	store, err := openStore(cfg)
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	// One generator is shared by the whole process. Separate generators
+	// on the same machine hand out the same ids within a time tick.
+	ids, err := sonyflake.New(sonyflake.Settings{})
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
 	app := application{
 		config: cfg,
 		logger: logger,
 		mailer: mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender),
-		models: data.NewModels(db.New(dbPool), redis),
+		models: data.NewModels(dbPool, redis, store),
+		hub:    realtime.NewHub(newEventBus(cfg, redis)),
+		jobs:   jobs.New(redis),
+		unfurler: unfurl.New(unfurl.Options{
+			Timeout:      cfg.linkPreviews.timeout,
+			MaxBytes:     512 << 10,
+			MaxRedirects: 3,
+			AllowPrivate: cfg.linkPreviews.allowPrivate,
+			UserAgent:    "BuzzChatBot/1.0 (link previews)",
+		}),
+		ids: ids,
 	}
 
+	app.jobs.Handle(jobProcessMedia, app.processMediaJob)
+	app.jobs.Handle(jobUnfurlLink, app.unfurlLinkJob)
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobsDone := make(chan struct{})
+
+	app.background(func() {
+		defer close(jobsDone)
+		app.jobs.Run(jobsCtx, cfg.jobs.workers)
+	})
+
 	app.background(func() {
 		mailer.StartEmailWorker(&app.mailer, redis)
 	})
 
-	if err = app.serve(); err != nil {
+	app.background(func() {
+		if err := app.hub.Run(context.Background()); err != nil {
+			logger.PrintFatal(err, nil)
+		}
+	})
+
+	app.background(app.reconcileUnread)
+
+	app.background(app.collectGarbage)
+
+	app.background(app.sendScheduledMessages)
+
+	app.background(app.reapExpiredMessages)
+
+	app.background(app.closeDuePolls)
+
+	// Once the server has stopped, running jobs are interrupted and put
+	// back on the queue so another instance picks them up.
+	drain := func() {
+		stopJobs()
+		<-jobsDone
+	}
+
+	if err = app.serve(drain); err != nil {
 		logger.PrintFatal(err, nil)
 	}
 }
 
+func newEventBus(cfg config, rdb *redis.Client) realtime.Bus {
+	if cfg.events.bus == "memory" {
+		return realtime.NewMemoryBus()
+	}
+	return realtime.NewRedisBus(rdb)
+}
+
+func openStore(cfg config) (blob.Store, error) {
+	if cfg.storage.backend == "s3" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		return blob.NewS3Store(ctx, cfg.storage.s3)
+	}
+	return blob.NewLocalStore(cfg.storage.path)
+}
+
 func openDB(cfg config) (*pgxpool.Pool, error) {
 	db, err := pgxpool.New(context.Background(), cfg.db.dsn)
 	if err != nil {
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/RickinShah/BuzzChat/internal/jobs"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+)
+
+const jobProcessMedia = "media.process"
+
+type processMediaPayload struct {
+	Sha256 string `json:"sha256"`
+}
+
+// enqueueMediaProcessing queues the media processing for an attachment that
+// still needs it.
+func (app *application) enqueueMediaProcessing(attachment *model.Attachment) {
+	if attachment.Media == nil || attachment.Media.Status != model.MediaPending {
+		return
+	}
+
+	if err := app.jobs.Enqueue(jobProcessMedia, processMediaPayload{Sha256: attachment.Sha256}); err != nil {
+		app.logger.PrintError(err, map[string]any{"attachment_id": attachment.AttachmentID})
+	}
+}
+
+// processMediaJob processes a blob and tells everyone who can see one of
+// its attachments. If the last attempt fails too the blob is marked failed,
+// so clients stop waiting for it.
+func (app *application) processMediaJob(ctx context.Context, payload json.RawMessage) error {
+	var input processMediaPayload
+	if err := json.Unmarshal(payload, &input); err != nil {
+		return err
+	}
+
+	processed, err := app.models.Attachments.ProcessMedia(ctx, input.Sha256)
+	if err != nil {
+		if !jobs.IsFinalAttempt(ctx) {
+			return err
+		}
+
+		app.logger.PrintError(err, map[string]any{"sha256": input.Sha256})
+
+		processed, err = app.models.Attachments.FailMedia(ctx, input.Sha256)
+		if err != nil {
+			return err
+		}
+	}
+
+	if processed {
+		app.announceMedia(input.Sha256)
+	}
+
+	return nil
+}
+
+// announceMedia sends the processed attachments of a blob to everyone who
+// can see them: the uploader while it's unsent, the conversation once it's
+// been sent.
+func (app *application) announceMedia(sum string) {
+	attachments, err := app.models.Attachments.GetForBlob(sum)
+	if err != nil {
+		app.logger.PrintError(err, map[string]any{"sha256": sum})
+		return
+	}
+
+	for _, attachment := range attachments {
+		if attachment.ConversationID != 0 {
+			app.publishToConversation(attachment.ConversationID, realtime.EventAttachmentProcessed, attachment)
+		} else {
+			app.publish([]int64{attachment.UserPid}, realtime.EventAttachmentProcessed, attachment)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+// readMentions parses and resolves the mentions in a message body. Using
+// @here or @all without the group's permission is a validation error.
+func (app *application) readMentions(conversationID, senderID int64, membership *model.Membership, body string, v *validator.Validator) ([]*model.Mention, error) {
+	mentions := model.ParseMentions(body, membership.Kind)
+
+	for _, mention := range mentions {
+		if mention.Kind != model.MentionUser && !membership.CanMentionAll(senderID) {
+			v.AddError("body", "@"+mention.Kind+" can only be used by admins in this group")
+			return nil, nil
+		}
+	}
+
+	return app.models.Messages.ResolveMentions(conversationID, mentions)
+}
+
+// mentionedUserIDs expands mentions into the members they reach: @all is
+// everyone, @here everyone connected right now. The sender is left out.
+func (app *application) mentionedUserIDs(senderID int64, mentions []*model.Mention, membership *model.Membership) ([]int64, error) {
+	var userIDs []int64
+
+	for _, mention := range mentions {
+		switch mention.Kind {
+		case model.MentionUser:
+			userIDs = append(userIDs, mention.UserID)
+		case model.MentionAll:
+			userIDs = append(userIDs, membership.UserIDs()...)
+		case model.MentionHere:
+			onlineIDs, err := app.models.Presence.GetOnlineIDs(membership.UserIDs())
+			if err != nil {
+				return nil, err
+			}
+			userIDs = append(userIDs, onlineIDs...)
+		}
+	}
+
+	slices.Sort(userIDs)
+	userIDs = slices.Compact(userIDs)
+
+	return slices.DeleteFunc(userIDs, func(userID int64) bool { return userID == senderID }), nil
+}
+
+// notifyMentions records the mentions of a new message and notifies the
+// mentioned users. Mentions notify even in muted conversations.
+func (app *application) notifyMentions(message *model.Message, mentionedIDs []int64) {
+	if len(mentionedIDs) == 0 {
+		return
+	}
+
+	if err := app.models.Messages.AddMentions(message.MessageID, mentionedIDs); err != nil {
+		app.logger.PrintError(err, map[string]any{"message_id": message.MessageID})
+		return
+	}
+
+	payload := map[string]any{
+		"conversationId": strconv.FormatInt(message.ConversationID, 10),
+		"messageId":      strconv.FormatInt(message.MessageID, 10),
+	}
+	if message.ThreadID.Valid {
+		payload["threadId"] = strconv.FormatInt(message.ThreadID.Int64, 10)
+	}
+
+	for _, userID := range mentionedIDs {
+		app.notify(userID, message.SenderPid.Int64, model.NotificationMention, payload)
+	}
+}
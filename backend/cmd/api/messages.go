@@ -0,0 +1,315 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+// readMessage loads the message named in the path from a conversation the
+// current user takes part in.
+func (app *application) readMessage(w http.ResponseWriter, r *http.Request) (*model.Message, *model.Membership, bool) {
+	conversationID, membership, ok := app.readMembership(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+
+	messageID, err := app.readIDPath(r, "messageId")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, nil, false
+	}
+
+	message, err := app.models.Messages.Get(conversationID, messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, nil, false
+	}
+
+	return message, membership, true
+}
+
+// readHistoryQuery reads the paging parameters shared by the conversation and
+// thread timelines.
+func (app *application) readHistoryQuery(qs url.Values, v *validator.Validator) data.HistoryQuery {
+	query := data.HistoryQuery{
+		Before: app.readID(qs, "before", v),
+		After:  app.readID(qs, "after", v),
+		Around: app.readID(qs, "around", v),
+		Limit:  app.readInt(qs, "limit", 50, v),
+	}
+
+	if at := app.readTime(qs, "at", v); !at.IsZero() {
+		v.Check(query.Before == 0 && query.After == 0 && query.Around == 0, "at", "only one of before, after, around and at can be provided")
+		query.After = max(data.IDFromTime(at)-1, 1)
+	}
+
+	data.ValidateHistoryQuery(v, query)
+
+	return query
+}
+
+// readReplyTarget loads the message named by id for a reply. Replies can
+// only point at visible, non-system messages in the same conversation, so
+// anything else is reported as a validation error on key.
+func (app *application) readReplyTarget(conversationID int64, id, key string, v *validator.Validator) (*model.Message, error) {
+	messageID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || messageID < 1 {
+		v.AddError(key, "must be a valid message id")
+		return nil, nil
+	}
+
+	message, err := app.models.Messages.Get(conversationID, messageID)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			v.AddError(key, "must be a message in this conversation")
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if message.Kind == model.MessageSystem || message.IsDeleted() {
+		v.AddError(key, "can't be replied to")
+		return nil, nil
+	}
+
+	return message, nil
+}
+
+// canSend reports whether senderID may post in a conversation. Groups are
+// open to every member, direct conversations depend on the recipient's
+// privacy settings and blocks.
+func (app *application) canSend(senderID int64, membership *model.Membership) (bool, error) {
+	if membership.Kind != model.ConversationDirect {
+		return true, nil
+	}
+
+	for _, participantID := range membership.UserIDs() {
+		if participantID == senderID {
+			continue
+		}
+
+		allowed, err := app.canMessage(senderID, participantID)
+		if err != nil || !allowed {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// announceMessage pushes a newly stored message to the participants and
+// starts the work that follows it: link previews, mention notifications,
+// thread notifications for replies and unread counters.
+func (app *application) announceMessage(message, root *model.Message, membership *model.Membership, mentionedIDs []int64) {
+	app.publish(membership.UserIDs(), realtime.EventMessageCreated, message)
+
+	app.enqueueLinkPreview(message, membership)
+
+	app.notifyMentions(message, mentionedIDs)
+
+	if root != nil {
+		app.notifyThreadReply(root, message, membership, mentionedIDs)
+	} else {
+		app.countUnread(message, membership.UserIDs(), mentionedIDs)
+	}
+}
+
+func (app *application) editMessageHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Body string `json:"body"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	message, membership, ok := app.readMessage(w, r)
+	if !ok {
+		return
+	}
+
+	// Forwarded messages can't be edited, so they stay true to their origin.
+	if message.Kind != model.MessageText || message.IsDeleted() || !message.IsSentBy(user.UserPid) || message.Forward != nil {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	if time.Since(message.CreatedAt.Time) > app.config.messages.editWindow {
+		app.editWindowExpiredResponse(w, r)
+		return
+	}
+
+	v := validator.New()
+	data.ValidateMessageBody(v, input.Body)
+
+	// Mentions added by an edit are highlighted, but don't notify anyone
+	// or count towards mention counters.
+	mentions, err := app.readMentions(message.ConversationID, user.UserPid, membership, input.Body, v)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if input.Body != message.Body {
+		previousBody := message.Body
+		message.Body = input.Body
+
+		message.Entities, err = model.NewEntities(mentions)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if err := app.models.Messages.Edit(message, user.UserPid); err != nil {
+			switch {
+			case errors.Is(err, data.ErrEditConflict):
+				app.editConflictResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		app.publish(membership.UserIDs(), realtime.EventMessageUpdated, message)
+
+		app.updateLinkPreview(message, previousBody, membership)
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": message}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listMessageEditsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	message, membership, ok := app.readMessage(w, r)
+	if !ok {
+		return
+	}
+
+	if !membership.CanViewEdits(user.UserPid, message) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	edits, err := app.models.Messages.GetEdits(message.MessageID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"message": message, "edits": edits}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteMessageHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	scope := app.readString(r.URL.Query(), "scope", model.DeleteScopeMe)
+
+	v := validator.New()
+	if v.Check(validator.In(scope, model.DeleteScopeMe, model.DeleteScopeEveryone), "scope", "must be me or everyone"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	message, membership, ok := app.readMessage(w, r)
+	if !ok {
+		return
+	}
+
+	if scope == model.DeleteScopeMe {
+		if err := app.models.Messages.Hide(user.UserPid, message.MessageID); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		app.publish([]int64{user.UserPid}, realtime.EventMessageDeleted, &model.MessageDeletedEvent{
+			ConversationID: message.ConversationID,
+			MessageID:      message.MessageID,
+			Scope:          model.DeleteScopeMe,
+		})
+		app.recountUnread(message.ConversationID, user.UserPid)
+
+		if err := app.writeJson(w, http.StatusOK, envelope{"message": "message deleted for you"}, nil); err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if message.Kind == model.MessageSystem || message.IsDeleted() {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	// Group admins can remove any message. Everyone else can only remove
+	// their own, and only for a while after sending it.
+	isAdmin := membership.Kind == model.ConversationGroup && model.IsManager(membership.Role(user.UserPid))
+	if !isAdmin {
+		if !message.IsSentBy(user.UserPid) {
+			app.notPermittedResponse(w, r)
+			return
+		}
+
+		if time.Since(message.CreatedAt.Time) > app.config.messages.deleteWindow {
+			app.editWindowExpiredResponse(w, r)
+			return
+		}
+	}
+
+	unpinned, err := app.models.Messages.DeleteForEveryone(message, user.UserPid)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.publish(membership.UserIDs(), realtime.EventMessageDeleted, &model.MessageDeletedEvent{
+		ConversationID: message.ConversationID,
+		MessageID:      message.MessageID,
+		Scope:          model.DeleteScopeEveryone,
+	})
+
+	if unpinned {
+		app.publish(membership.UserIDs(), realtime.EventPinRemoved, &model.PinEvent{
+			ConversationID: message.ConversationID,
+			MessageID:      message.MessageID,
+			UserID:         user.UserPid,
+		})
+	}
+
+	for _, userID := range membership.UserIDs() {
+		app.recountUnread(message.ConversationID, userID)
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": message}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+func (app *application) notify(userID, actorID int64, kind string, payload any) {
+	app.background(func() {
+		notification, err := model.NewNotification(userID, actorID, kind, payload)
+		if err != nil {
+			app.logger.PrintError(err, nil)
+			return
+		}
+
+		if err := app.models.Notifications.Insert(notification); err != nil {
+			app.logger.PrintError(err, map[string]any{"kind": kind})
+		}
+	})
+}
+
+func (app *application) listNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	qs := r.URL.Query()
+
+	v := validator.New()
+	filters := app.readFilters(qs, "-id", []string{"id", "-id"}, v)
+	unreadOnly := app.readString(qs, "unread", "false") == "true"
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	notifications, metadata, err := app.models.Notifications.GetAll(user.UserPid, unreadOnly, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	unread, err := app.models.Notifications.CountUnread(user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{
+		"notifications": notifications,
+		"unread":        unread,
+		"metadata":      metadata,
+	}
+
+	if err = app.writeJson(w, http.StatusOK, env, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) readNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	notificationID, err := app.readIDPath(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if err = app.models.Notifications.MarkRead(user.UserPid, notificationID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"message": "notification marked as read"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) readAllNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	if err := app.models.Notifications.MarkAllRead(user.UserPid); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "all notifications marked as read"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
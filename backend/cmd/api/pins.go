@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+)
+
+func (app *application) pinMessageHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	message, membership, ok := app.readMessage(w, r)
+	if !ok {
+		return
+	}
+
+	if !membership.CanPin(user.UserPid) || message.Kind == model.MessageSystem || message.IsDeleted() {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	pinned, err := app.models.Messages.Pin(message, user.UserPid)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrTooManyPins):
+			app.conflictResponse(w, r, "this conversation has too many pinned messages")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if pinned {
+		status = http.StatusCreated
+		app.publish(membership.UserIDs(), realtime.EventPinAdded, &model.PinEvent{
+			ConversationID: message.ConversationID,
+			MessageID:      message.MessageID,
+			UserID:         user.UserPid,
+		})
+
+		event := model.NewSystemEvent(model.SystemMessagePinned, user.UserPid)
+		event.MessageID = strconv.FormatInt(message.MessageID, 10)
+		app.postSystemMessage(message.ConversationID, event)
+	}
+
+	if err = app.writeJson(w, status, envelope{"message": "message pinned"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) unpinMessageHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	message, membership, ok := app.readMessage(w, r)
+	if !ok {
+		return
+	}
+
+	if !membership.CanPin(user.UserPid) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	if err := app.models.Messages.Unpin(message); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.publish(membership.UserIDs(), realtime.EventPinRemoved, &model.PinEvent{
+		ConversationID: message.ConversationID,
+		MessageID:      message.MessageID,
+		UserID:         user.UserPid,
+	})
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "message unpinned"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listPinsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	conversationID, _, ok := app.readMembership(w, r)
+	if !ok {
+		return
+	}
+
+	pins, err := app.models.Messages.GetPins(conversationID, user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"pins": pins}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// pollCloseBatchSize is how many due polls are closed per query.
+const pollCloseBatchSize = 100
+
+// readPoll resolves the poll message named in the path along with its
+// tallies and the current user's votes.
+func (app *application) readPoll(w http.ResponseWriter, r *http.Request) (*model.Message, *model.Poll, *model.Membership, bool) {
+	message, membership, ok := app.readMessage(w, r)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	if message.Kind != model.MessagePoll || message.IsDeleted() {
+		app.notFoundResponse(w, r)
+		return nil, nil, nil, false
+	}
+
+	poll, err := app.models.Polls.Get(message.MessageID, app.contextGetUser(r).UserPid)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, nil, nil, false
+	}
+
+	return message, poll, membership, true
+}
+
+func (app *application) createPollHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Question       string     `json:"question"`
+		Options        []string   `json:"options"`
+		MultipleChoice bool       `json:"multipleChoice"`
+		Anonymous      bool       `json:"anonymous"`
+		ClosesAt       *time.Time `json:"closesAt"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	conversationID, membership, ok := app.readMembership(w, r)
+	if !ok {
+		return
+	}
+
+	if membership.Kind != model.ConversationGroup {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	poll := &model.Poll{
+		Poll: db.Poll{
+			ConversationID: conversationID,
+			Question:       input.Question,
+			Options:        input.Options,
+			MultipleChoice: input.MultipleChoice,
+			Anonymous:      input.Anonymous,
+		},
+	}
+
+	if input.ClosesAt != nil {
+		poll.ClosesAt = pgtype.Timestamptz{Time: *input.ClosesAt, Valid: true}
+	}
+
+	v := validator.New()
+	if data.ValidatePoll(v, poll); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	message := &model.Message{
+		Message: db.Message{
+			ConversationID: conversationID,
+			SenderPid:      pgtype.Int8{Int64: user.UserPid, Valid: true},
+			Kind:           model.MessagePoll,
+			Body:           input.Question,
+		},
+	}
+
+	err := app.models.Transaction(func(tx data.Models) error {
+		if err := tx.Messages.Insert(message); err != nil {
+			return err
+		}
+
+		poll.MessageID = message.MessageID
+
+		return tx.Polls.Insert(poll)
+	})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	message.Poll = poll
+
+	app.announceMessage(message, nil, membership, nil)
+
+	if err := app.writeJson(w, http.StatusCreated, envelope{"message": message}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getPollHandler returns a poll with its tallies, and who voted for what
+// unless the poll is anonymous.
+func (app *application) getPollHandler(w http.ResponseWriter, r *http.Request) {
+	_, poll, _, ok := app.readPoll(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.models.Polls.GetVoters(poll); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"poll": poll}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) votePollHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Options []int32 `json:"options"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	message, poll, membership, ok := app.readPoll(w, r)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	v.Check(len(input.Options) > 0, "options", "must contain at least one option")
+	if data.ValidateVote(v, poll, input.Options); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	app.castVote(w, r, message, poll, membership, input.Options)
+}
+
+func (app *application) retractVoteHandler(w http.ResponseWriter, r *http.Request) {
+	message, poll, membership, ok := app.readPoll(w, r)
+	if !ok {
+		return
+	}
+
+	app.castVote(w, r, message, poll, membership, []int32{})
+}
+
+// castVote replaces the current user's choices in a poll and pushes the new
+// tallies to the participants if they changed.
+func (app *application) castVote(w http.ResponseWriter, r *http.Request, message *model.Message, poll *model.Poll, membership *model.Membership, options []int32) {
+	user := app.contextGetUser(r)
+
+	if poll.IsClosed() {
+		app.conflictResponse(w, r, "this poll is closed")
+		return
+	}
+
+	changed, err := app.models.Polls.Vote(poll, user.UserPid, options)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrPollClosed):
+			app.conflictResponse(w, r, "this poll is closed")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if changed {
+		app.publish(membership.UserIDs(), realtime.EventPollUpdated, &model.PollEvent{
+			ConversationID: message.ConversationID,
+			MessageID:      message.MessageID,
+			Poll:           poll.Tallies(),
+			UserID:         user.UserPid,
+			Options:        options,
+		})
+	}
+
+	poll.Voted = options
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"poll": poll}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// closePollHandler ends voting early. Polls can be closed by whoever
+// created them and by group admins.
+func (app *application) closePollHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	message, poll, membership, ok := app.readPoll(w, r)
+	if !ok {
+		return
+	}
+
+	if !message.IsSentBy(user.UserPid) && !model.IsManager(membership.Role(user.UserPid)) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	if err := app.models.Polls.Close(poll); err != nil {
+		switch {
+		case errors.Is(err, data.ErrPollClosed):
+			app.conflictResponse(w, r, "this poll is already closed")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.publishPollClosed(poll, membership)
+
+	if err := app.models.Polls.GetVoters(poll); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"poll": poll}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// publishPollClosed pushes the final results of a poll to the participants.
+func (app *application) publishPollClosed(poll *model.Poll, membership *model.Membership) {
+	app.publish(membership.UserIDs(), realtime.EventPollClosed, &model.PollEvent{
+		ConversationID: poll.ConversationID,
+		MessageID:      poll.MessageID,
+		Poll:           poll.Tallies(),
+	})
+}
+
+// closeDuePolls periodically closes polls whose close time has passed and
+// announces their results. Every instance runs it; polls another instance
+// is closing are skipped.
+func (app *application) closeDuePolls() {
+	ticker := time.NewTicker(app.config.polls.closeInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for {
+			polls, err := app.models.Polls.CloseDue(pollCloseBatchSize)
+			if err != nil {
+				app.logger.PrintError(err, nil)
+				break
+			}
+
+			for _, poll := range polls {
+				membership, err := app.models.Conversations.Membership(poll.ConversationID)
+				if err != nil {
+					if !errors.Is(err, data.ErrRecordNotFound) {
+						app.logger.PrintError(err, map[string]any{"message_id": poll.MessageID})
+					}
+					continue
+				}
+
+				app.publishPollClosed(poll, membership)
+			}
+
+			if len(polls) < pollCloseBatchSize {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+// trackPresence counts a new connection towards the user being online. The
+// connection is refreshed well within its TTL for as long as the socket is
+// alive, which the socket's own ping/pong already guarantees. The returned
+// function must be called once the connection closes.
+func (app *application) trackPresence(userID, connectionID int64) func() {
+	online, err := app.models.Presence.Connect(userID, connectionID)
+	if err != nil {
+		app.logger.PrintError(err, map[string]any{"user_id": userID})
+	}
+
+	if online {
+		app.publishPresence(&model.Presence{UserID: userID, Online: true})
+	}
+
+	done := make(chan struct{})
+
+	app.background(func() {
+		ticker := time.NewTicker(data.PresenceTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := app.models.Presence.Heartbeat(userID, connectionID); err != nil {
+					app.logger.PrintError(err, map[string]any{"user_id": userID})
+				}
+			}
+		}
+	})
+
+	return func() {
+		close(done)
+
+		presence, err := app.models.Presence.Disconnect(userID, connectionID)
+		if err != nil {
+			app.logger.PrintError(err, map[string]any{"user_id": userID})
+			return
+		}
+
+		if presence != nil {
+			app.publishPresence(presence)
+		}
+	}
+}
+
+// publishPresence tells a user's contacts that they came online or went
+// offline, unless the user hides their last seen from contacts.
+func (app *application) publishPresence(presence *model.Presence) {
+	settings, err := app.models.Privacy.Get(presence.UserID)
+	if err != nil {
+		app.logger.PrintError(err, map[string]any{"user_id": presence.UserID})
+		return
+	}
+
+	if !settings.Allows(settings.LastSeenVisibility, model.RelationContact) {
+		return
+	}
+
+	contactIDs, err := app.models.Contacts.GetIDs(presence.UserID)
+	if err != nil {
+		app.logger.PrintError(err, map[string]any{"user_id": presence.UserID})
+		return
+	}
+
+	app.publish(contactIDs, realtime.EventPresenceUpdated, presence)
+}
+
+func (app *application) listPresenceHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	v := validator.New()
+
+	ids := app.readCSV(r.URL.Query(), "ids", nil)
+	userIDs := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		userID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil || userID < 1 {
+			v.AddError("ids", "must contain valid user ids")
+			break
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if data.ValidatePresenceQuery(v, userIDs); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	presences, err := app.models.Presence.GetAll(user.UserPid, userIDs)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"presence": presences}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
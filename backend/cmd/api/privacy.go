@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+func (app *application) getPrivacySettingsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	settings, err := app.models.Privacy.Get(user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"privacy": settings}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updatePrivacySettingsHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		BioVisibility        *string `json:"bioVisibility"`
+		LastSeenVisibility   *string `json:"lastSeenVisibility"`
+		ProfilePicVisibility *string `json:"profilePicVisibility"`
+		EmailVisibility      *string `json:"emailVisibility"`
+		WhoCanMessage        *string `json:"whoCanMessage"`
+		Searchable           *bool   `json:"searchable"`
+		ReadReceipts         *bool   `json:"readReceipts"`
+		ForwardAttribution   *bool   `json:"forwardAttribution"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	settings, err := app.models.Privacy.Get(user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if input.BioVisibility != nil {
+		settings.BioVisibility = *input.BioVisibility
+	}
+	if input.LastSeenVisibility != nil {
+		settings.LastSeenVisibility = *input.LastSeenVisibility
+	}
+	if input.ProfilePicVisibility != nil {
+		settings.ProfilePicVisibility = *input.ProfilePicVisibility
+	}
+	if input.EmailVisibility != nil {
+		settings.EmailVisibility = *input.EmailVisibility
+	}
+	if input.WhoCanMessage != nil {
+		settings.WhoCanMessage = *input.WhoCanMessage
+	}
+	if input.Searchable != nil {
+		settings.Searchable = *input.Searchable
+	}
+	if input.ReadReceipts != nil {
+		settings.ReadReceipts = *input.ReadReceipts
+	}
+	if input.ForwardAttribution != nil {
+		settings.ForwardAttribution = *input.ForwardAttribution
+	}
+
+	v := validator.New()
+	if data.ValidatePrivacySettings(v, settings); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err = app.models.Privacy.Update(settings); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"privacy": settings}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+// readReaction resolves the message and emoji named in the path of a
+// reaction request.
+func (app *application) readReaction(w http.ResponseWriter, r *http.Request) (*model.Message, *model.Membership, string, bool) {
+	message, membership, ok := app.readMessage(w, r)
+	if !ok {
+		return nil, nil, "", false
+	}
+
+	emoji := app.readStringPath(r, "emoji", "")
+
+	v := validator.New()
+	if data.ValidateEmoji(v, emoji); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil, nil, "", false
+	}
+
+	return message, membership, emoji, true
+}
+
+func (app *application) addReactionHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	message, membership, emoji, ok := app.readReaction(w, r)
+	if !ok {
+		return
+	}
+
+	if message.Kind == model.MessageSystem || message.IsDeleted() {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	added, err := app.models.Reactions.Add(message.MessageID, user.UserPid, emoji)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrTooManyReactions):
+			app.conflictResponse(w, r, "this message has too many different reactions")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+		app.publish(membership.UserIDs(), realtime.EventReactionAdded, &model.ReactionEvent{
+			ConversationID: message.ConversationID,
+			MessageID:      message.MessageID,
+			UserID:         user.UserPid,
+			Emoji:          emoji,
+		})
+	}
+
+	if err = app.writeJson(w, status, envelope{"message": "reaction added"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) removeReactionHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	message, membership, emoji, ok := app.readReaction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.models.Reactions.Remove(message.MessageID, user.UserPid, emoji); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.publish(membership.UserIDs(), realtime.EventReactionRemoved, &model.ReactionEvent{
+		ConversationID: message.ConversationID,
+		MessageID:      message.MessageID,
+		UserID:         user.UserPid,
+		Emoji:          emoji,
+	})
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "reaction removed"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listReactionUsersHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	message, _, emoji, ok := app.readReaction(w, r)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	filters := app.readFilters(r.URL.Query(), "username", []string{"username", "-username"}, v)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	users, metadata, err := app.models.Reactions.GetUsers(message.MessageID, emoji, user.UserPid, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"users": users, "metadata": metadata}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+// advanceReceipt moves the current user's delivery or read watermark up to
+// the message in the request body and tells the other participants about it.
+func (app *application) advanceReceipt(w http.ResponseWriter, r *http.Request, read bool) {
+	var input struct {
+		MessageID string `json:"messageId"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	conversationID, membership, ok := app.readMembership(w, r)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+
+	messageID, err := strconv.ParseInt(input.MessageID, 10, 64)
+	if v.Check(err == nil && messageID > 0, "messageId", "must be a valid message id"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if _, err := app.models.Messages.Get(conversationID, messageID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("messageId", "must be a message in this conversation")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	mark := app.models.Receipts.MarkDelivered
+	if read {
+		mark = app.models.Receipts.MarkRead
+	}
+
+	receipt, advanced, err := mark(conversationID, user.UserPid, messageID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if advanced {
+		settings, err := app.models.Privacy.Get(user.UserPid)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		others := make([]int64, 0, len(membership.Roles))
+		for _, participantID := range membership.UserIDs() {
+			if participantID != user.UserPid {
+				others = append(others, participantID)
+			}
+		}
+
+		app.publish([]int64{user.UserPid}, realtime.EventReceiptUpdated, receipt)
+		app.publish(others, realtime.EventReceiptUpdated, receipt.Redacted(settings.ReadReceipts))
+
+		if read {
+			app.recountUnread(conversationID, user.UserPid)
+		}
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"receipt": receipt}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) markConversationDeliveredHandler(w http.ResponseWriter, r *http.Request) {
+	app.advanceReceipt(w, r, false)
+}
+
+func (app *application) markConversationReadHandler(w http.ResponseWriter, r *http.Request) {
+	app.advanceReceipt(w, r, true)
+}
+
+func (app *application) listMessageReceiptsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	message, _, ok := app.readMessage(w, r)
+	if !ok {
+		return
+	}
+
+	if message.Kind == model.MessageSystem || !message.IsSentBy(user.UserPid) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	receipts, err := app.models.Receipts.GetForMessage(message, user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"receipts": receipts}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -6,13 +6,84 @@ func (app *application) routes() http.Handler {
 	router := http.NewServeMux()
 
 	router.HandleFunc("GET /v1/healthcheck", app.healthcheckHandler)
+	router.HandleFunc("GET /v1/ws", app.requireAuthenticatedUser(app.wsHandler))
 	router.HandleFunc("GET /v1/users/{username}", app.requireAuthenticatedUser(app.getUserHandler))
 	router.HandleFunc("POST /v1/auth/register", app.registerUserHandler)
 	router.HandleFunc("GET /v1/users/me", app.requireAuthenticatedUser(app.getProfileHandler))
+	router.HandleFunc("GET /v1/users/presence", app.requireAuthenticatedUser(app.listPresenceHandler))
+	router.HandleFunc("GET /v1/users/me/badge", app.requireAuthenticatedUser(app.getBadgeHandler))
+	router.HandleFunc("GET /v1/users/me/privacy", app.requireAuthenticatedUser(app.getPrivacySettingsHandler))
+	router.HandleFunc("PATCH /v1/users/me/privacy", app.requireAuthenticatedUser(app.updatePrivacySettingsHandler))
+	router.HandleFunc("GET /v1/users", app.requireAuthenticatedUser(app.searchUsersHandler))
 	router.HandleFunc("POST /v1/auth/login", app.authenticationHandler)
 	router.HandleFunc("GET /v1/users/check-email", app.checkEmailHandler)
 	router.HandleFunc("GET /v1/users/check-username", app.checkUsernameHandler)
 	router.HandleFunc("POST /v1/auth/otp", app.generateOtpHandler)
 	router.HandleFunc("POST /v1/auth/otp/validate", app.validateOtpHandler)
+
+	router.HandleFunc("GET /v1/contacts", app.requireAuthenticatedUser(app.listContactsHandler))
+	router.HandleFunc("DELETE /v1/contacts/{username}", app.requireAuthenticatedUser(app.removeContactHandler))
+	router.HandleFunc("GET /v1/contacts/requests", app.requireAuthenticatedUser(app.listContactRequestsHandler))
+	router.HandleFunc("POST /v1/contacts/requests", app.requireAuthenticatedUser(app.sendContactRequestHandler))
+	router.HandleFunc("POST /v1/contacts/requests/{username}/accept", app.requireAuthenticatedUser(app.acceptContactRequestHandler))
+	router.HandleFunc("POST /v1/contacts/requests/{username}/decline", app.requireAuthenticatedUser(app.declineContactRequestHandler))
+	router.HandleFunc("DELETE /v1/contacts/requests/{username}", app.requireAuthenticatedUser(app.cancelContactRequestHandler))
+	router.HandleFunc("GET /v1/contacts/blocks", app.requireAuthenticatedUser(app.listBlockedUsersHandler))
+	router.HandleFunc("PUT /v1/contacts/blocks/{username}", app.requireAuthenticatedUser(app.blockUserHandler))
+	router.HandleFunc("DELETE /v1/contacts/blocks/{username}", app.requireAuthenticatedUser(app.unblockUserHandler))
+
+	router.HandleFunc("GET /v1/notifications", app.requireAuthenticatedUser(app.listNotificationsHandler))
+	router.HandleFunc("POST /v1/notifications/read", app.requireAuthenticatedUser(app.readAllNotificationsHandler))
+	router.HandleFunc("POST /v1/notifications/{id}/read", app.requireAuthenticatedUser(app.readNotificationHandler))
+
+	router.HandleFunc("POST /v1/uploads", app.requireAuthenticatedUser(app.createUploadHandler))
+	router.HandleFunc("GET /v1/uploads/{id}", app.requireAuthenticatedUser(app.getUploadHandler))
+	router.HandleFunc("PATCH /v1/uploads/{id}", app.requireAuthenticatedUser(app.writeUploadChunkHandler))
+	router.HandleFunc("POST /v1/uploads/{id}/finalize", app.requireAuthenticatedUser(app.finalizeUploadHandler))
+	router.HandleFunc("DELETE /v1/uploads/{id}", app.requireAuthenticatedUser(app.cancelUploadHandler))
+	router.HandleFunc("GET /v1/attachments/{id}/download", app.downloadAttachmentHandler)
+	router.HandleFunc("GET /v1/attachments/{id}/thumbnails/{size}", app.downloadThumbnailHandler)
+
+	router.HandleFunc("GET /v1/conversations", app.requireAuthenticatedUser(app.listConversationsHandler))
+	router.HandleFunc("POST /v1/conversations/direct", app.requireAuthenticatedUser(app.openDirectConversationHandler))
+	router.HandleFunc("POST /v1/conversations/groups", app.requireAuthenticatedUser(app.createGroupHandler))
+	router.HandleFunc("GET /v1/conversations/{id}", app.requireAuthenticatedUser(app.getConversationHandler))
+	router.HandleFunc("PATCH /v1/conversations/{id}", app.requireAuthenticatedUser(app.updateGroupHandler))
+	router.HandleFunc("PATCH /v1/conversations/{id}/settings", app.requireAuthenticatedUser(app.updateConversationSettingsHandler))
+	router.HandleFunc("PUT /v1/conversations/{id}/mute", app.requireAuthenticatedUser(app.muteConversationHandler))
+	router.HandleFunc("DELETE /v1/conversations/{id}/mute", app.requireAuthenticatedUser(app.unmuteConversationHandler))
+	router.HandleFunc("POST /v1/conversations/{id}/delivered", app.requireAuthenticatedUser(app.markConversationDeliveredHandler))
+	router.HandleFunc("POST /v1/conversations/{id}/read", app.requireAuthenticatedUser(app.markConversationReadHandler))
+	router.HandleFunc("POST /v1/conversations/{id}/polls", app.requireAuthenticatedUser(app.createPollHandler))
+	router.HandleFunc("GET /v1/conversations/{id}/pins", app.requireAuthenticatedUser(app.listPinsHandler))
+	router.HandleFunc("GET /v1/conversations/{id}/scheduled", app.requireAuthenticatedUser(app.listScheduledMessagesHandler))
+	router.HandleFunc("POST /v1/conversations/{id}/scheduled", app.requireAuthenticatedUser(app.createScheduledMessageHandler))
+	router.HandleFunc("PATCH /v1/conversations/{id}/scheduled/{scheduledId}", app.requireAuthenticatedUser(app.updateScheduledMessageHandler))
+	router.HandleFunc("DELETE /v1/conversations/{id}/scheduled/{scheduledId}", app.requireAuthenticatedUser(app.cancelScheduledMessageHandler))
+	router.HandleFunc("GET /v1/conversations/{id}/messages", app.requireAuthenticatedUser(app.listMessagesHandler))
+	router.HandleFunc("POST /v1/conversations/{id}/messages", app.requireAuthenticatedUser(app.sendMessageHandler))
+	router.HandleFunc("POST /v1/conversations/{id}/messages/forward", app.requireAuthenticatedUser(app.forwardMessagesHandler))
+	router.HandleFunc("PATCH /v1/conversations/{id}/messages/{messageId}", app.requireAuthenticatedUser(app.editMessageHandler))
+	router.HandleFunc("DELETE /v1/conversations/{id}/messages/{messageId}", app.requireAuthenticatedUser(app.deleteMessageHandler))
+	router.HandleFunc("GET /v1/conversations/{id}/messages/{messageId}/edits", app.requireAuthenticatedUser(app.listMessageEditsHandler))
+	router.HandleFunc("GET /v1/conversations/{id}/messages/{messageId}/receipts", app.requireAuthenticatedUser(app.listMessageReceiptsHandler))
+	router.HandleFunc("GET /v1/conversations/{id}/messages/{messageId}/thread", app.requireAuthenticatedUser(app.listThreadHandler))
+	router.HandleFunc("PUT /v1/conversations/{id}/messages/{messageId}/follow", app.requireAuthenticatedUser(app.followThreadHandler))
+	router.HandleFunc("DELETE /v1/conversations/{id}/messages/{messageId}/follow", app.requireAuthenticatedUser(app.unfollowThreadHandler))
+	router.HandleFunc("GET /v1/conversations/{id}/messages/{messageId}/poll", app.requireAuthenticatedUser(app.getPollHandler))
+	router.HandleFunc("PUT /v1/conversations/{id}/messages/{messageId}/poll/vote", app.requireAuthenticatedUser(app.votePollHandler))
+	router.HandleFunc("DELETE /v1/conversations/{id}/messages/{messageId}/poll/vote", app.requireAuthenticatedUser(app.retractVoteHandler))
+	router.HandleFunc("POST /v1/conversations/{id}/messages/{messageId}/poll/close", app.requireAuthenticatedUser(app.closePollHandler))
+	router.HandleFunc("PUT /v1/conversations/{id}/messages/{messageId}/pin", app.requireAuthenticatedUser(app.pinMessageHandler))
+	router.HandleFunc("DELETE /v1/conversations/{id}/messages/{messageId}/pin", app.requireAuthenticatedUser(app.unpinMessageHandler))
+	router.HandleFunc("GET /v1/conversations/{id}/messages/{messageId}/reactions/{emoji}", app.requireAuthenticatedUser(app.listReactionUsersHandler))
+	router.HandleFunc("PUT /v1/conversations/{id}/messages/{messageId}/reactions/{emoji}", app.requireAuthenticatedUser(app.addReactionHandler))
+	router.HandleFunc("DELETE /v1/conversations/{id}/messages/{messageId}/reactions/{emoji}", app.requireAuthenticatedUser(app.removeReactionHandler))
+	router.HandleFunc("POST /v1/conversations/{id}/members", app.requireAuthenticatedUser(app.addGroupMembersHandler))
+	router.HandleFunc("DELETE /v1/conversations/{id}/members/{username}", app.requireAuthenticatedUser(app.removeGroupMemberHandler))
+	router.HandleFunc("PUT /v1/conversations/{id}/admins/{username}", app.requireAuthenticatedUser(app.promoteGroupMemberHandler))
+	router.HandleFunc("DELETE /v1/conversations/{id}/admins/{username}", app.requireAuthenticatedUser(app.demoteGroupAdminHandler))
+	router.HandleFunc("POST /v1/conversations/{id}/owner", app.requireAuthenticatedUser(app.transferGroupOwnershipHandler))
+	router.HandleFunc("POST /v1/conversations/{id}/leave", app.requireAuthenticatedUser(app.leaveGroupHandler))
 	return app.enableCORS(app.authenticate(router))
 }
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// scheduledBatchSize is how many due messages one instance claims per
+	// tick.
+	scheduledBatchSize = 100
+
+	// scheduledClaimTimeout is how long a claimed message may stay unsent
+	// before another instance takes it over.
+	scheduledClaimTimeout = 5 * time.Minute
+)
+
+// readScheduledMessage resolves the scheduled message named in the path.
+// Only its sender can see it.
+func (app *application) readScheduledMessage(w http.ResponseWriter, r *http.Request) (*model.ScheduledMessage, *model.Membership, bool) {
+	conversationID, membership, ok := app.readMembership(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+
+	scheduledID, err := app.readIDPath(r, "scheduledId")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, nil, false
+	}
+
+	message, err := app.models.Scheduled.Get(conversationID, scheduledID, app.contextGetUser(r).UserPid)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, nil, false
+	}
+
+	return message, membership, true
+}
+
+func (app *application) createScheduledMessageHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Body   string    `json:"body"`
+		SendAt time.Time `json:"sendAt"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	conversationID, membership, ok := app.readMembership(w, r)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	data.ValidateMessageBody(v, input.Body)
+	data.ValidateSendAt(v, input.SendAt)
+
+	// Mentions are resolved again when the message is sent, this only
+	// rejects the ones the sender isn't allowed to use.
+	if _, err := app.readMentions(conversationID, user.UserPid, membership, input.Body, v); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	allowed, err := app.canSend(user.UserPid, membership)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !allowed {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	message := &model.ScheduledMessage{
+		ScheduledMessage: db.ScheduledMessage{
+			ConversationID: conversationID,
+			SenderPid:      user.UserPid,
+			Body:           input.Body,
+			SendAt:         pgtype.Timestamptz{Time: input.SendAt, Valid: true},
+		},
+	}
+
+	if err = app.models.Scheduled.Insert(message); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusCreated, envelope{"scheduledMessage": message}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listScheduledMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	conversationID, _, ok := app.readMembership(w, r)
+	if !ok {
+		return
+	}
+
+	messages, err := app.models.Scheduled.GetAll(conversationID, user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"scheduledMessages": messages}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updateScheduledMessageHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Body   *string    `json:"body"`
+		SendAt *time.Time `json:"sendAt"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	message, membership, ok := app.readScheduledMessage(w, r)
+	if !ok {
+		return
+	}
+
+	if message.Status == model.ScheduledSending {
+		app.conflictResponse(w, r, "this message is already being sent")
+		return
+	}
+
+	if input.Body != nil {
+		message.Body = *input.Body
+	}
+
+	v := validator.New()
+	data.ValidateMessageBody(v, message.Body)
+
+	// A failed message has to be given a new time to be sent again.
+	if input.SendAt != nil {
+		message.SendAt = pgtype.Timestamptz{Time: *input.SendAt, Valid: true}
+		data.ValidateSendAt(v, *input.SendAt)
+	} else if message.Status == model.ScheduledFailed || message.SendAt.Time.Before(time.Now()) {
+		v.AddError("sendAt", "must be provided")
+	}
+
+	if _, err := app.readMentions(message.ConversationID, user.UserPid, membership, message.Body, v); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Scheduled.Update(message); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"scheduledMessage": message}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) cancelScheduledMessageHandler(w http.ResponseWriter, r *http.Request) {
+	message, _, ok := app.readScheduledMessage(w, r)
+	if !ok {
+		return
+	}
+
+	if message.Status == model.ScheduledSending {
+		app.conflictResponse(w, r, "this message is already being sent")
+		return
+	}
+
+	if err := app.models.Scheduled.Delete(message); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "scheduled message cancelled"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// cancelScheduledMessages drops the scheduled messages of someone who is no
+// longer a member of a conversation and tells their devices.
+func (app *application) cancelScheduledMessages(conversationID, userID int64) {
+	scheduledIDs, err := app.models.Scheduled.CancelAll(conversationID, userID)
+	if err != nil {
+		app.logger.PrintError(err, map[string]any{"conversation_id": conversationID, "user_id": userID})
+		return
+	}
+
+	if len(scheduledIDs) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(scheduledIDs))
+	for _, id := range scheduledIDs {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+
+	app.publish([]int64{userID}, realtime.EventScheduledCancelled, map[string]any{
+		"conversationId": strconv.FormatInt(conversationID, 10),
+		"scheduledIds":   ids,
+	})
+}
+
+// sendScheduledMessages delivers due scheduled messages. Every instance runs
+// it; claiming with SKIP LOCKED keeps them from sending the same message
+// twice.
+func (app *application) sendScheduledMessages() {
+	ticker := time.NewTicker(app.config.scheduler.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		messages, err := app.models.Scheduled.Claim(scheduledBatchSize, scheduledClaimTimeout)
+		if err != nil {
+			app.logger.PrintError(err, nil)
+			continue
+		}
+
+		for _, message := range messages {
+			if err := app.sendScheduledMessage(message); err != nil {
+				// The claim is left in place, so the message is retried
+				// once it goes stale.
+				app.logger.PrintError(err, map[string]any{"scheduled_id": message.ScheduledID})
+			}
+		}
+	}
+}
+
+// sendScheduledMessage posts a claimed message as if its sender had sent it
+// just now. Messages that can no longer be sent are marked as failed and
+// kept for the sender to fix or cancel.
+func (app *application) sendScheduledMessage(scheduled *model.ScheduledMessage) error {
+	membership, err := app.models.Conversations.Membership(scheduled.ConversationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			return app.models.Scheduled.Complete(scheduled)
+		default:
+			return err
+		}
+	}
+
+	if !membership.IsMember(scheduled.SenderPid) {
+		return app.models.Scheduled.Complete(scheduled)
+	}
+
+	allowed, err := app.canSend(scheduled.SenderPid, membership)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return app.failScheduledMessage(scheduled, "you can't message this user")
+	}
+
+	v := validator.New()
+	data.ValidateMessageBody(v, scheduled.Body)
+
+	mentions, err := app.readMentions(scheduled.ConversationID, scheduled.SenderPid, membership, scheduled.Body, v)
+	if err != nil {
+		return err
+	}
+
+	if !v.Valid() {
+		return app.failScheduledMessage(scheduled, v.Errors["body"])
+	}
+
+	message := &model.Message{
+		Message: db.Message{
+			ConversationID: scheduled.ConversationID,
+			SenderPid:      pgtype.Int8{Int64: scheduled.SenderPid, Valid: true},
+			Kind:           model.MessageText,
+			Body:           scheduled.Body,
+		},
+	}
+
+	message.Entities, err = model.NewEntities(mentions)
+	if err != nil {
+		return err
+	}
+
+	mentionedIDs, err := app.mentionedUserIDs(scheduled.SenderPid, mentions, membership)
+	if err != nil {
+		return err
+	}
+
+	// The message and the removal of its schedule commit together, so a
+	// crash in between can neither lose the message nor send it twice.
+	err = app.models.Transaction(func(tx data.Models) error {
+		if err := tx.Messages.Insert(message); err != nil {
+			return err
+		}
+
+		return tx.Scheduled.Complete(scheduled)
+	})
+	if err != nil {
+		return err
+	}
+
+	app.announceMessage(message, nil, membership, mentionedIDs)
+
+	app.publish([]int64{scheduled.SenderPid}, realtime.EventScheduledSent, map[string]any{
+		"conversationId": strconv.FormatInt(scheduled.ConversationID, 10),
+		"scheduledId":    strconv.FormatInt(scheduled.ScheduledID, 10),
+		"messageId":      strconv.FormatInt(message.MessageID, 10),
+	})
+
+	return nil
+}
+
+func (app *application) failScheduledMessage(scheduled *model.ScheduledMessage, reason string) error {
+	if err := app.models.Scheduled.Fail(scheduled, reason); err != nil {
+		return err
+	}
+
+	app.publish([]int64{scheduled.SenderPid}, realtime.EventScheduledFailed, scheduled)
+
+	return nil
+}
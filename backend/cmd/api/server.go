@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 )
 
-func (app *application) serve() error {
+// serve runs the server until SIGINT or SIGTERM, then lets in-flight
+// requests finish and calls drain before returning.
+func (app *application) serve(drain func()) error {
 	srv := &http.Server{
 		Addr:         ":" + strconv.Itoa(app.config.port),
 		Handler:      app.routes(),
@@ -18,6 +24,27 @@ func (app *application) serve() error {
 		WriteTimeout: 30 * time.Second,
 	}
 
+	shutdownError := make(chan error)
+
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		s := <-quit
+
+		app.logger.PrintInfo("shutting down server", map[string]any{
+			"signal": s.String(),
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
+		err := srv.Shutdown(ctx)
+
+		drain()
+
+		shutdownError <- err
+	}()
+
 	app.logger.PrintInfo("starting server", map[string]any{
 		"addr": srv.Addr,
 		"port": "4000",
@@ -28,5 +55,13 @@ func (app *application) serve() error {
 		return err
 	}
 
+	if err := <-shutdownError; err != nil {
+		return err
+	}
+
+	app.logger.PrintInfo("stopped server", map[string]any{
+		"addr": srv.Addr,
+	})
+
 	return nil
 }
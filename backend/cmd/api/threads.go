@@ -0,0 +1,155 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+// notifyThreadReply refreshes the thread summary on the root message for
+// everyone in the conversation and notifies the thread's followers, except
+// those who muted the conversation.
+func (app *application) notifyThreadReply(root, reply *model.Message, membership *model.Membership, mentionedIDs []int64) {
+	senderID := reply.SenderPid.Int64
+
+	app.background(func() {
+		updated, err := app.models.Messages.Get(root.ConversationID, root.MessageID)
+		if err != nil {
+			app.logger.PrintError(err, map[string]any{"message_id": root.MessageID})
+			return
+		}
+
+		app.publish(membership.UserIDs(), realtime.EventMessageUpdated, updated)
+
+		followers := []int64{senderID}
+		if root.SenderPid.Valid && membership.IsMember(root.SenderPid.Int64) {
+			followers = append(followers, root.SenderPid.Int64)
+		}
+
+		if err := app.models.Threads.AutoFollow(root.MessageID, followers...); err != nil {
+			app.logger.PrintError(err, map[string]any{"thread_id": root.MessageID})
+			return
+		}
+
+		followerIDs, err := app.models.Threads.GetFollowerIDs(root.MessageID)
+		if err != nil {
+			app.logger.PrintError(err, map[string]any{"thread_id": root.MessageID})
+			return
+		}
+
+		// Replies are unread for the thread's followers and for anyone they
+		// mention, not for the whole conversation.
+		app.countUnread(reply, slices.Concat(followerIDs, mentionedIDs), mentionedIDs)
+
+		payload := map[string]any{
+			"conversationId": strconv.FormatInt(root.ConversationID, 10),
+			"threadId":       strconv.FormatInt(root.MessageID, 10),
+			"messageId":      strconv.FormatInt(reply.MessageID, 10),
+		}
+
+		mutedIDs, err := app.models.Conversations.GetMutedIDs(root.ConversationID)
+		if err != nil {
+			app.logger.PrintError(err, map[string]any{"conversation_id": root.ConversationID})
+			return
+		}
+
+		// Mentioned followers already got a mention notification.
+		for _, followerID := range followerIDs {
+			if followerID == senderID || slices.Contains(mutedIDs, followerID) || slices.Contains(mentionedIDs, followerID) {
+				continue
+			}
+			app.notify(followerID, senderID, model.NotificationThreadReply, payload)
+		}
+	})
+}
+
+// readThreadRoot loads the message named in the path and makes sure it can
+// have a thread.
+func (app *application) readThreadRoot(w http.ResponseWriter, r *http.Request) (*model.Message, bool) {
+	root, _, ok := app.readMessage(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	if !root.IsThreadRoot() || root.Kind == model.MessageSystem {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	return root, true
+}
+
+func (app *application) listThreadHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	root, ok := app.readThreadRoot(w, r)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	query := app.readHistoryQuery(r.URL.Query(), v)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	query.ThreadID = root.MessageID
+
+	messages, cursors, err := app.models.Messages.GetHistory(root.ConversationID, user.UserPid, query)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	following, err := app.models.Threads.IsFollowing(root.MessageID, user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	response := envelope{
+		"root":      root,
+		"messages":  messages,
+		"cursors":   cursors,
+		"following": following,
+	}
+
+	if err = app.writeJson(w, http.StatusOK, response, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) setThreadFollowing(w http.ResponseWriter, r *http.Request, following bool) {
+	user := app.contextGetUser(r)
+
+	root, ok := app.readThreadRoot(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.models.Threads.Follow(root.MessageID, user.UserPid, following); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	response := envelope{
+		"threadId":  strconv.FormatInt(root.MessageID, 10),
+		"following": following,
+	}
+
+	if err := app.writeJson(w, http.StatusOK, response, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) followThreadHandler(w http.ResponseWriter, r *http.Request) {
+	app.setThreadFollowing(w, r, true)
+}
+
+func (app *application) unfollowThreadHandler(w http.ResponseWriter, r *http.Request) {
+	app.setThreadFollowing(w, r, false)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+)
+
+// typingEvent is the payload of typing frames in both directions.
+type typingEvent struct {
+	ConversationID string `json:"conversationId"`
+	UserID         string `json:"userId,omitempty"`
+	ExpiresIn      int    `json:"expiresIn,omitempty"`
+}
+
+// typingState is what a connection knows about its own typing indicators:
+// its id, which keeps them apart from the user's other connections, and the
+// conversations it is typing in, so they can be cleared when it closes.
+type typingState struct {
+	connectionID  int64
+	conversations map[int64]struct{}
+}
+
+// handleTyping records a typing.start or typing.stop frame and relays it to
+// the other participants.
+func (app *application) handleTyping(c *realtime.Client, event *realtime.Event, typing *typingState) {
+	var input typingEvent
+	if err := json.Unmarshal(event.Data, &input); err != nil {
+		c.SendError("malformed frame")
+		return
+	}
+
+	conversationID, err := strconv.ParseInt(input.ConversationID, 10, 64)
+	if err != nil || conversationID < 1 {
+		c.SendError("conversationId must be a valid conversation id")
+		return
+	}
+
+	membership, err := app.models.Conversations.Membership(conversationID)
+	if err != nil || !membership.IsMember(c.UserID) {
+		c.SendError("conversation not found")
+		return
+	}
+
+	if event.Type == realtime.EventTypingStop {
+		delete(typing.conversations, conversationID)
+		app.stopTyping(conversationID, c.UserID, typing.connectionID)
+		return
+	}
+
+	if err := app.models.Typing.Start(conversationID, c.UserID, typing.connectionID); err != nil {
+		app.logger.PrintError(err, map[string]any{"conversation_id": conversationID})
+		return
+	}
+	typing.conversations[conversationID] = struct{}{}
+
+	// Starts are relayed every time, with how long they last, so receivers
+	// can let an indicator lapse on their own when the refreshes stop.
+	app.relayTyping(conversationID, c.UserID, realtime.EventTypingStart, membership.UserIDs())
+}
+
+func (app *application) stopTyping(conversationID, userID, connectionID int64) {
+	stopped, err := app.models.Typing.Stop(conversationID, userID, connectionID)
+	if err != nil {
+		app.logger.PrintError(err, map[string]any{"conversation_id": conversationID})
+		return
+	}
+
+	// An indicator that already expired was dropped by the receivers too,
+	// and one still refreshed by another connection must stay up.
+	if !stopped {
+		return
+	}
+
+	membership, err := app.models.Conversations.Membership(conversationID)
+	if err != nil {
+		app.logger.PrintError(err, map[string]any{"conversation_id": conversationID})
+		return
+	}
+
+	app.relayTyping(conversationID, userID, realtime.EventTypingStop, membership.UserIDs())
+}
+
+// relayTyping sends a typing event to the participants other than the
+// typist, leaving out anyone on either side of a block with them.
+func (app *application) relayTyping(conversationID, userID int64, eventType string, participantIDs []int64) {
+	blockedIDs, err := app.models.Contacts.GetBlockedIDs(userID)
+	if err != nil {
+		app.logger.PrintError(err, map[string]any{"user_id": userID})
+		return
+	}
+
+	recipients := make([]int64, 0, len(participantIDs))
+	for _, participantID := range participantIDs {
+		if participantID != userID && !slices.Contains(blockedIDs, participantID) {
+			recipients = append(recipients, participantID)
+		}
+	}
+
+	if len(recipients) == 0 {
+		return
+	}
+
+	payload := typingEvent{
+		ConversationID: strconv.FormatInt(conversationID, 10),
+		UserID:         strconv.FormatInt(userID, 10),
+	}
+	if eventType == realtime.EventTypingStart {
+		payload.ExpiresIn = int(data.TypingTTL.Seconds())
+	}
+
+	app.publish(recipients, eventType, payload)
+}
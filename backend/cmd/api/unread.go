@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+)
+
+// countUnread bumps the unread counters of the recipients of a new message
+// and pushes the new counts to them. The sender is never a recipient.
+func (app *application) countUnread(message *model.Message, recipientIDs, mentionedIDs []int64) {
+	recipientIDs = slices.Clone(recipientIDs)
+	slices.Sort(recipientIDs)
+	recipientIDs = slices.DeleteFunc(slices.Compact(recipientIDs), func(userID int64) bool {
+		return userID == message.SenderPid.Int64
+	})
+
+	if len(recipientIDs) == 0 {
+		return
+	}
+
+	app.background(func() {
+		updates, err := app.models.Unread.MessageCreated(message.ConversationID, message.MessageID, recipientIDs, mentionedIDs)
+		if err != nil {
+			app.logger.PrintError(err, map[string]any{"conversation_id": message.ConversationID})
+			return
+		}
+
+		for _, update := range updates {
+			app.publish([]int64{update.UserID}, realtime.EventUnreadUpdated, update)
+		}
+	})
+}
+
+// recountUnread recounts a conversation for a user after something they
+// can't see anymore or have now read, and pushes the result to them.
+func (app *application) recountUnread(conversationID, userID int64) {
+	app.background(func() {
+		update, err := app.models.Unread.Recount(conversationID, userID)
+		if err != nil {
+			app.logger.PrintError(err, map[string]any{"conversation_id": conversationID})
+			return
+		}
+
+		app.publish([]int64{userID}, realtime.EventUnreadUpdated, update)
+	})
+}
+
+// attachUnread fills in the viewer's unread counters of conversations about
+// to be returned to them.
+func (app *application) attachUnread(viewerID int64, conversations ...*model.Conversation) error {
+	counts, _, err := app.models.Unread.Get(viewerID)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range conversations {
+		c.Unread = counts[c.ConversationID]
+		if c.Unread == nil {
+			c.Unread = &model.UnreadCount{ConversationID: c.ConversationID}
+		}
+	}
+
+	return nil
+}
+
+// reconcileUnread periodically rebuilds the unread counters from Postgres.
+// The lock makes sure only one instance does it each round.
+func (app *application) reconcileUnread() {
+	ticker := time.NewTicker(app.config.unread.reconcileInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		acquired, err := app.models.Unread.Lock(app.config.unread.reconcileInterval / 2)
+		if err != nil {
+			app.logger.PrintError(err, nil)
+			continue
+		}
+
+		if !acquired {
+			continue
+		}
+
+		rebuilt, err := app.models.Unread.Reconcile()
+		if err != nil {
+			app.logger.PrintError(err, nil)
+			continue
+		}
+
+		app.logger.PrintInfo("reconciled unread counters", map[string]any{"users": rebuilt})
+	}
+}
+
+func (app *application) getBadgeHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	_, badge, err := app.models.Unread.Get(user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"badge": badge}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/data"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+func (app *application) createUploadHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Filename string `json:"filename"`
+		Size     int64  `json:"size"`
+	}
+
+	if err := app.readJson(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	upload := &model.Upload{
+		Upload: db.Upload{
+			UserPid:  user.UserPid,
+			Filename: input.Filename,
+			Size:     input.Size,
+		},
+	}
+
+	v := validator.New()
+	if data.ValidateUpload(v, upload, app.config.uploads.maxSize); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Attachments.CreateUpload(upload, app.config.uploads.quota); err != nil {
+		switch {
+		case errors.Is(err, data.ErrQuotaExceeded):
+			app.errorResponse(w, r, http.StatusRequestEntityTooLarge, err.Error())
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/uploads/%d", upload.UploadID))
+	headers.Set("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+
+	if err := app.writeJson(w, http.StatusCreated, envelope{"upload": upload}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) readUpload(w http.ResponseWriter, r *http.Request) (*model.Upload, bool) {
+	uploadID, err := app.readIDPath(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	user := app.contextGetUser(r)
+
+	upload, err := app.models.Attachments.GetUpload(uploadID, user.UserPid)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	return upload, true
+}
+
+// getUploadHandler tells a client where to resume an interrupted upload.
+func (app *application) getUploadHandler(w http.ResponseWriter, r *http.Request) {
+	upload, ok := app.readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"upload": upload}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// writeUploadChunkHandler appends the request body to an upload. The
+// Upload-Offset header must match what the server has; on a mismatch the
+// client should ask for the current offset and resume from there.
+func (app *application) writeUploadChunkHandler(w http.ResponseWriter, r *http.Request) {
+	upload, ok := app.readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		app.badRequestResponse(w, r, errors.New("missing or invalid Upload-Offset header"))
+		return
+	}
+
+	if offset != upload.UploadOffset {
+		app.offsetMismatchResponse(w, r, upload)
+		return
+	}
+
+	limit := min(upload.Size-upload.UploadOffset, data.MaxChunkSize)
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+	chunk, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesError):
+			app.errorResponse(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("chunk must not be larger than %d bytes", limit))
+		default:
+			app.badRequestResponse(w, r, err)
+		}
+		return
+	}
+
+	if len(chunk) == 0 {
+		app.badRequestResponse(w, r, errors.New("chunk must not be empty"))
+		return
+	}
+
+	if err := app.models.Attachments.WriteChunk(r.Context(), upload, offset, chunk); err != nil {
+		switch {
+		case errors.Is(err, data.ErrOffsetMismatch):
+			app.offsetMismatchResponse(w, r, upload)
+		case errors.Is(err, data.ErrUploadBusy):
+			app.conflictResponse(w, r, "another chunk of this upload is being written")
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"upload": upload}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) offsetMismatchResponse(w http.ResponseWriter, r *http.Request, upload *model.Upload) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+	app.conflictResponse(w, r, "upload offset does not match, resume from the Upload-Offset header")
+}
+
+func (app *application) finalizeUploadHandler(w http.ResponseWriter, r *http.Request) {
+	upload, ok := app.readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	attachment, err := app.models.Attachments.Finalize(r.Context(), upload)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrUploadIncomplete):
+			app.conflictResponse(w, r, fmt.Sprintf("upload is incomplete, %d of %d bytes received", upload.UploadOffset, upload.Size))
+		case errors.Is(err, data.ErrUploadCorrupt):
+			app.errorResponse(w, r, http.StatusUnprocessableEntity, "upload is corrupt, cancel it and upload the file again")
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.enqueueMediaProcessing(attachment)
+
+	if err := app.writeJson(w, http.StatusCreated, envelope{"attachment": attachment}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) cancelUploadHandler(w http.ResponseWriter, r *http.Request) {
+	uploadID, err := app.readIDPath(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if err := app.models.Attachments.CancelUpload(r.Context(), uploadID, user.UserPid); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJson(w, http.StatusOK, envelope{"message": "upload cancelled"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// downloadAttachmentHandler serves attachment bytes. It is authorized by the
+// signed token in the URL rather than a session, and always downloads
+// instead of rendering inline so uploaded HTML can't run on our origin.
+func (app *application) downloadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	attachmentID, err := app.readIDPath(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if err := data.VerifyDownload(attachmentID, r.URL.Query().Get("token")); err != nil {
+		app.errorResponse(w, r, http.StatusForbidden, err.Error())
+		return
+	}
+
+	attachment, err := app.models.Attachments.Get(attachmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !attachment.CanDownload() {
+		switch attachment.Media.Status {
+		case model.MediaPending:
+			app.conflictResponse(w, r, "attachment is still being processed")
+		default:
+			app.errorResponse(w, r, http.StatusUnprocessableEntity, "attachment could not be processed")
+		}
+		return
+	}
+
+	body, err := app.models.Attachments.Open(r.Context(), attachment)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(data.DownloadURLTTL/time.Second)))
+
+	if _, err := io.Copy(w, body); err != nil {
+		app.logError(r, err)
+	}
+}
+
+// downloadThumbnailHandler serves a thumbnail with the same signed token as
+// the attachment. Thumbnails are always JPEG or PNG rendered by us, so they
+// are safe to display inline.
+func (app *application) downloadThumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	attachmentID, err := app.readIDPath(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	size, err := app.readIDPath(r, "size")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if err := data.VerifyDownload(attachmentID, r.URL.Query().Get("token")); err != nil {
+		app.errorResponse(w, r, http.StatusForbidden, err.Error())
+		return
+	}
+
+	attachment, err := app.models.Attachments.Get(attachmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	body, err := app.models.Attachments.OpenThumbnail(r.Context(), attachment, int32(size))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", attachment.Media.ThumbnailType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(data.DownloadURLTTL/time.Second)))
+
+	if _, err := io.Copy(w, body); err != nil {
+		app.logError(r, err)
+	}
+}
+
+// readAttachments resolves the attachment ids of a new message. They must
+// be attachments the sender uploaded and hasn't sent yet.
+func (app *application) readAttachments(userID int64, ids []string, v *validator.Validator) ([]*model.Attachment, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	if len(ids) > data.MaxAttachmentsPerMessage {
+		v.AddError("attachments", fmt.Sprintf("must not contain more than %d attachments", data.MaxAttachmentsPerMessage))
+		return nil, nil
+	}
+
+	attachmentIDs := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		attachmentID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil || attachmentID < 1 {
+			v.AddError("attachments", "must contain valid attachment ids")
+			return nil, nil
+		}
+		attachmentIDs = append(attachmentIDs, attachmentID)
+	}
+
+	v.Check(validator.Unique(ids), "attachments", "must not contain duplicate attachments")
+	if !v.Valid() {
+		return nil, nil
+	}
+
+	attachments, err := app.models.Attachments.GetUnsent(userID, attachmentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Check(len(attachments) == len(attachmentIDs), "attachments", "must be your own attachments that haven't been sent yet")
+
+	return attachments, nil
+}
+
+func (app *application) collectGarbage() {
+	ticker := time.NewTicker(app.config.uploads.gcInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		acquired, err := app.models.Attachments.Lock(app.config.uploads.gcInterval / 2)
+		if err != nil {
+			app.logger.PrintError(err, nil)
+			continue
+		}
+
+		if !acquired {
+			continue
+		}
+
+		stats, err := app.models.Attachments.CollectGarbage(context.Background())
+		if err != nil {
+			app.logger.PrintError(err, nil)
+			continue
+		}
+
+		app.logger.PrintInfo("collected attachment garbage", map[string]any{
+			"uploads":     stats.Uploads,
+			"attachments": stats.Attachments,
+			"blobs":       stats.Blobs,
+		})
+
+		sums, err := app.models.Attachments.StaleMedia(context.Background())
+		if err != nil {
+			app.logger.PrintError(err, nil)
+			continue
+		}
+
+		for _, sum := range sums {
+			if err := app.jobs.Enqueue(jobProcessMedia, processMediaPayload{Sha256: sum}); err != nil {
+				app.logger.PrintError(err, map[string]any{"sha256": sum})
+			}
+		}
+	}
+}
@@ -33,7 +33,22 @@ func (app *application) getUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewer := app.contextGetUser(r)
+
+	relationship, err := app.models.Contacts.Relationship(viewer.UserPid, user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	privacy, err := app.models.Privacy.Get(user.UserPid)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	user.SetMarshalType(model.Frontend)
+	user.SetViewer(relationship, privacy)
 
 	if err := app.writeJson(w, http.StatusOK, envelope{"user": user}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -162,3 +177,35 @@ func (app *application) checkUsernameHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 }
+
+func (app *application) searchUsersHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Query string
+		data.Filters
+	}
+
+	qs := r.URL.Query()
+
+	v := validator.New()
+	input.Query = app.readString(qs, "q", "")
+	input.Filters = app.readFilters(qs, "username", []string{"username"}, v)
+
+	v.Check(input.Query != "", "q", "must be provided")
+	v.Check(len(input.Query) <= 50, "q", "must not be more than 50 characters")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	users, metadata, err := app.models.Users.Search(user.UserPid, input.Query, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err = app.writeJson(w, http.StatusOK, envelope{"users": users, "metadata": metadata}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
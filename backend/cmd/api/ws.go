@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/realtime"
+	"github.com/gorilla/websocket"
+)
+
+// checkOrigin only lets the configured clients open sockets. Requests
+// without an Origin header, which browsers always send, are accepted in
+// development so that command line tools can connect.
+func (app *application) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return app.config.env == "development"
+	}
+
+	return slices.Contains(app.config.clients, origin)
+}
+
+func (app *application) wsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     app.checkOrigin,
+	}
+
+	if !app.checkOrigin(r) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	connectionID, err := app.generateID()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an error response.
+		app.logError(r, err)
+		return
+	}
+
+	// typing is only touched from the client's read loop, so it needs no
+	// locking.
+	typing := &typingState{
+		connectionID:  connectionID,
+		conversations: make(map[int64]struct{}),
+	}
+
+	untrackPresence := app.trackPresence(user.UserPid, connectionID)
+	defer untrackPresence()
+
+	client := realtime.NewClient(app.hub, conn, user.UserPid)
+	err = client.Run(func(c *realtime.Client, event *realtime.Event) {
+		app.handleClientEvent(c, event, typing)
+	})
+	if err != nil {
+		app.logError(r, err)
+	}
+
+	// A client that drops without sending typing.stop shouldn't look like
+	// it is still typing until the indicators expire.
+	for conversationID := range typing.conversations {
+		app.stopTyping(conversationID, user.UserPid, connectionID)
+	}
+}
+
+func (app *application) handleClientEvent(c *realtime.Client, event *realtime.Event, typing *typingState) {
+	switch event.Type {
+	case realtime.EventTypingStart, realtime.EventTypingStop:
+		app.handleTyping(c, event, typing)
+	default:
+		c.SendError("unsupported event type " + event.Type)
+	}
+}
+
+// publish pushes an event to every connection the given users hold.
+func (app *application) publish(userIDs []int64, eventType string, data any) {
+	event, err := realtime.NewEvent(eventType, data)
+	if err != nil {
+		app.logger.PrintError(err, map[string]any{"event": eventType})
+		return
+	}
+
+	if err := app.hub.Publish(userIDs, event); err != nil {
+		app.logger.PrintError(err, map[string]any{"event": eventType})
+	}
+}
+
+// publishToConversation pushes an event to the current participants of a
+// conversation plus any extra users, such as a member who was just removed.
+func (app *application) publishToConversation(conversationID int64, eventType string, data any, extraUserIDs ...int64) {
+	membership, err := app.models.Conversations.Membership(conversationID)
+	if err != nil {
+		app.logger.PrintError(err, map[string]any{"event": eventType})
+		return
+	}
+
+	app.publish(append(membership.UserIDs(), extraUserIDs...), eventType, data)
+}
+
+func (app *application) publishConversationUpdated(conversationID int64, extraUserIDs ...int64) {
+	data := map[string]any{"conversationId": strconv.FormatInt(conversationID, 10)}
+	app.publishToConversation(conversationID, realtime.EventConversationUpdated, data, extraUserIDs...)
+}
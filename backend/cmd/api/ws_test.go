@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    string
+		origin string
+		want   bool
+	}{
+		{"allowed client", "production", "http://localhost:5173", true},
+		{"unknown client", "production", "https://evil.example", false},
+		{"missing origin in production", "production", "", false},
+		{"missing origin in development", "development", "", true},
+		{"unknown client in development", "development", "https://evil.example", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &application{config: config{env: tt.env, clients: []string{"http://localhost:5173"}}}
+
+			r := httptest.NewRequest("GET", "/v1/ws", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+
+			if got := app.checkOrigin(r); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Package blob stores the bytes of uploaded files behind a small interface
+// so the API doesn't care whether they end up on local disk or in an
+// S3-compatible bucket.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps opaque objects under slash-separated keys.
+type Store interface {
+	// Put writes an object of the given size, replacing any existing one.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens an object. It returns ErrNotFound if there is none.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// List returns the keys under a prefix in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Delete removes an object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// DeletePrefix removes every object under a prefix.
+func DeletePrefix(ctx context.Context, store Store, prefix string) error {
+	keys, err := store.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Concat reads the objects with the given keys one after another, so parts
+// uploaded separately can be streamed as a single file.
+func Concat(ctx context.Context, store Store, keys []string) io.ReadCloser {
+	return &concatReader{ctx: ctx, store: store, keys: keys}
+}
+
+type concatReader struct {
+	ctx     context.Context
+	store   Store
+	keys    []string
+	current io.ReadCloser
+}
+
+func (c *concatReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if len(c.keys) == 0 {
+				return 0, io.EOF
+			}
+
+			r, err := c.store.Get(c.ctx, c.keys[0])
+			if err != nil {
+				return 0, err
+			}
+			c.current = r
+			c.keys = c.keys[1:]
+		}
+
+		n, err := c.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			c.current.Close()
+			c.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+
+		return n, err
+	}
+}
+
+func (c *concatReader) Close() error {
+	if c.current != nil {
+		return c.current.Close()
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestConcat(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	put(t, store, "parts/0", "hello, ")
+	put(t, store, "parts/1", "")
+	put(t, store, "parts/2", "world")
+
+	tests := []struct {
+		name    string
+		keys    []string
+		want    string
+		wantErr error
+	}{
+		{"in order", []string{"parts/0", "parts/1", "parts/2"}, "hello, world", nil},
+		{"single", []string{"parts/2"}, "world", nil},
+		{"none", nil, "", nil},
+		{"missing part", []string{"parts/0", "parts/9"}, "", ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Concat(ctx, store, tt.keys)
+			defer r.Close()
+
+			got, err := io.ReadAll(r)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
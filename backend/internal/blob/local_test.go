@@ -0,0 +1,141 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *LocalStore {
+	t.Helper()
+
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return store
+}
+
+func put(t *testing.T, store Store, key, content string) {
+	t.Helper()
+
+	if err := store.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put(%q): unexpected error: %v", key, err)
+	}
+}
+
+func TestLocalStorePath(t *testing.T) {
+	store := newTestStore(t)
+
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{"blobs/ab/abcdef", true},
+		{"uploads/1/00000000000000000000", true},
+		{"../outside", false},
+		{"blobs/../../outside", false},
+		{"/etc/passwd", false},
+		{"blobs//double", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		path, err := store.path(tt.key)
+		if (err == nil) != tt.valid {
+			t.Errorf("path(%q) error = %v, want valid %v", tt.key, err, tt.valid)
+			continue
+		}
+		if tt.valid && !strings.HasPrefix(path, store.root+string(filepath.Separator)) {
+			t.Errorf("path(%q) = %q, outside root %q", tt.key, path, store.root)
+		}
+	}
+}
+
+func TestLocalStorePutGet(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	put(t, store, "blobs/ab/abc", "hello")
+
+	r, err := store.Get(ctx, "blobs/ab/abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("Get = %q, want %q", got, "hello")
+	}
+
+	if _, err := store.Get(ctx, "blobs/ab/missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalStorePutSizeMismatch(t *testing.T) {
+	store := newTestStore(t)
+
+	err := store.Put(context.Background(), "blobs/short", strings.NewReader("abc"), 10, "text/plain")
+	if err == nil {
+		t.Fatal("expected an error for a short write")
+	}
+
+	if _, err := store.Get(context.Background(), "blobs/short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("a failed Put left an object behind: %v", err)
+	}
+}
+
+func TestLocalStoreListAndDelete(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	put(t, store, "uploads/1/00000000000000000005", "b")
+	put(t, store, "uploads/1/00000000000000000000", "a")
+	put(t, store, "uploads/12/00000000000000000000", "other")
+	put(t, store, "blobs/ab/abc", "blob")
+
+	keys, err := store.List(ctx, "uploads/1/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"uploads/1/00000000000000000000", "uploads/1/00000000000000000005"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("List = %v, want %v", keys, want)
+	}
+
+	keys, err = store.List(ctx, "missing/")
+	if err != nil || len(keys) != 0 {
+		t.Errorf("List(missing) = %v, %v, want no keys", keys, err)
+	}
+
+	if err := DeletePrefix(ctx, store, "uploads/1/"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(store.root, "uploads", "1")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("empty directory was left behind: %v", err)
+	}
+
+	keys, err = store.List(ctx, "uploads/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(keys, []string{"uploads/12/00000000000000000000"}) {
+		t.Errorf("List after DeletePrefix = %v", keys)
+	}
+
+	if err := store.Delete(ctx, "blobs/ab/missing"); err != nil {
+		t.Errorf("Delete(missing) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	membershipTTL = time.Hour
+
+	// membershipGenerationTTL outlives any read of a membership by far, so
+	// a generation only expires once nobody can still be holding it.
+	membershipGenerationTTL = 24 * time.Hour
+)
+
+// A membership is only cached if its generation hasn't moved since the
+// reader fetched it, i.e. no write was committed while the rows were being
+// read. Otherwise a read racing a role change or removal could put the old
+// member list back right after it was invalidated.
+var setMembershipScript = redis.NewScript(`
+local generation = redis.call('GET', KEYS[2]) or ''
+if generation ~= ARGV[2] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[3])
+return 1
+`)
+
+// MembershipGeneration returns the current generation of a conversation's
+// membership, to be passed to SetCachedMembership. Fetch it before reading
+// the membership from the database.
+func MembershipGeneration(rdb *redis.Client, conversationID int64) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	generation, err := rdb.Get(ctx, cacheKeyForMembershipGeneration(conversationID)).Result()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+
+	return generation, nil
+}
+
+// SetCachedMembership caches a membership unless it was invalidated after
+// generation was fetched.
+func SetCachedMembership(rdb *redis.Client, conversationID int64, membership *model.Membership, generation string) error {
+	data, err := json.Marshal(membership)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	keys := []string{cacheKeyForMembership(conversationID), cacheKeyForMembershipGeneration(conversationID)}
+
+	return setMembershipScript.Run(ctx, rdb, keys, data, generation, membershipTTL.Milliseconds()).Err()
+}
+
+func GetCachedMembership(rdb *redis.Client, conversationID int64) (*model.Membership, bool, error) {
+	key := cacheKeyForMembership(conversationID)
+
+	return Get[model.Membership](rdb, key)
+}
+
+// DelCachedMembership drops the cached membership and moves its generation
+// on, so reads that started before the write can't cache what they found.
+func DelCachedMembership(rdb *redis.Client, conversationID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	generationKey := cacheKeyForMembershipGeneration(conversationID)
+
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, generationKey)
+		pipe.PExpire(ctx, generationKey, membershipGenerationTTL)
+		pipe.Del(ctx, cacheKeyForMembership(conversationID))
+		return nil
+	})
+
+	return err
+}
+
+func cacheKeyForMembership(conversationID int64) string {
+	return "membership:" + strconv.FormatInt(conversationID, 10)
+}
+
+func cacheKeyForMembershipGeneration(conversationID int64) string {
+	return "membership-gen:" + strconv.FormatInt(conversationID, 10)
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// LinkPreviewTTL is how long a fetched preview is reused for.
+	LinkPreviewTTL = 24 * time.Hour
+
+	// NoLinkPreviewTTL is how long a URL without a preview is remembered,
+	// so a broken link posted many times isn't fetched each time.
+	NoLinkPreviewTTL = time.Hour
+)
+
+// SetCachedLinkPreview caches the preview of url. A nil preview records that
+// the URL has none.
+func SetCachedLinkPreview(rdb RedisExecutor, url string, preview *model.LinkPreview) error {
+	key := cacheKeyForLinkPreview(url)
+
+	if preview == nil {
+		return Set(rdb, key, model.LinkPreview{}, NoLinkPreviewTTL)
+	}
+	return Set(rdb, key, preview, LinkPreviewTTL)
+}
+
+func GetCachedLinkPreview(rdb *redis.Client, url string) (*model.LinkPreview, bool, error) {
+	key := cacheKeyForLinkPreview(url)
+
+	return Get[model.LinkPreview](rdb, key)
+}
+
+// cacheKeyForLinkPreview hashes the URL to keep keys short and free of
+// whatever characters users put in links.
+func cacheKeyForLinkPreview(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return "link_preview:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AcquireLock takes a named lock for ttl and reports whether it was free.
+// Periodic jobs hold it for the whole round so only one instance runs each
+// one; short critical sections release it with ReleaseLock.
+func AcquireLock(rdb *redis.Client, name string, ttl time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return rdb.SetNX(ctx, "lock:"+name, time.Now().Unix(), ttl).Result()
+}
+
+func ReleaseLock(rdb *redis.Client, name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return rdb.Del(ctx, "lock:"+name).Err()
+}
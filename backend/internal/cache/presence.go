@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PresenceTTL is how long a connection counts as online without a
+// heartbeat. Connections on an instance that dies simply age out.
+const PresenceTTL = 90 * time.Second
+
+// Every connection of a user is a member of one sorted set, scored by when
+// it expires, so a user is online while any member is still live. The
+// scripts prune expired members first so the counts they return are exact.
+var (
+	touchPresenceScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[2])
+local live = redis.call('ZCARD', KEYS[1])
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return live
+`)
+
+	removePresenceScript = redis.NewScript(`
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[2])
+return redis.call('ZCARD', KEYS[1])
+`)
+)
+
+// TouchPresence adds or refreshes a connection and reports whether it is the
+// only live one, meaning the user just came online.
+func TouchPresence(rdb *redis.Client, userID, connectionID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	key := []string{cacheKeyForPresence(userID)}
+
+	live, err := touchPresenceScript.Run(ctx, rdb, key,
+		connectionID,
+		now.UnixMilli(),
+		now.Add(PresenceTTL).UnixMilli(),
+		PresenceTTL.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return false, err
+	}
+
+	return live == 0, nil
+}
+
+// RemovePresence drops a connection and reports whether it was the last
+// live one, meaning the user just went offline.
+func RemovePresence(rdb *redis.Client, userID, connectionID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	key := []string{cacheKeyForPresence(userID)}
+
+	live, err := removePresenceScript.Run(ctx, rdb, key, connectionID, time.Now().UnixMilli()).Int64()
+	if err != nil {
+		return false, err
+	}
+
+	return live == 0, nil
+}
+
+// GetOnline reports which of the given users have a live connection.
+func GetOnline(rdb *redis.Client, userIDs []int64) (map[int64]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	cmds := make([]*redis.IntCmd, len(userIDs))
+	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, userID := range userIDs {
+			cmds[i] = pipe.ZCount(ctx, cacheKeyForPresence(userID), "("+now, "+inf")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	online := make(map[int64]bool, len(userIDs))
+	for i, userID := range userIDs {
+		online[userID] = cmds[i].Val() > 0
+	}
+
+	return online, nil
+}
+
+func cacheKeyForPresence(userID int64) string {
+	return "presence:" + strconv.FormatInt(userID, 10)
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+func SetCachedPrivacySettings(rdb RedisExecutor, settings *model.PrivacySettings) error {
+	key := cacheKeyForPrivacySettings(settings.UserPid)
+
+	return Set(rdb, key, settings, time.Hour)
+}
+
+func GetCachedPrivacySettings(rdb *redis.Client, userID int64) (*model.PrivacySettings, bool, error) {
+	key := cacheKeyForPrivacySettings(userID)
+
+	return Get[model.PrivacySettings](rdb, key)
+}
+
+func DelCachedPrivacySettings(rdb RedisExecutor, userID int64) error {
+	key := cacheKeyForPrivacySettings(userID)
+
+	return Del(rdb, key)
+}
+
+func cacheKeyForPrivacySettings(userID int64) string {
+	return "privacy:" + strconv.FormatInt(userID, 10)
+}
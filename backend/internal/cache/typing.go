@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// A user types in a conversation from any number of connections. Each one is
+// a member of a sorted set scored by when its indicator lapses, so that one
+// device stopping or disconnecting leaves the others' indicators alone.
+var delTypingScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[2])
+local removed = redis.call('ZREM', KEYS[1], ARGV[1])
+if removed == 0 then
+	return 0
+end
+if redis.call('ZCARD', KEYS[1]) > 0 then
+	return 0
+end
+return 1
+`)
+
+// SetTyping marks a connection as typing in a conversation for ttl. Members
+// lapse on their own, so a client that never sends typing.stop can't leave
+// one behind.
+func SetTyping(rdb *redis.Client, conversationID, userID, connectionID int64, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	key := cacheKeyForTyping(conversationID, userID)
+
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, redis.Z{
+			Score:  float64(time.Now().Add(ttl).UnixMilli()),
+			Member: connectionID,
+		})
+		pipe.PExpire(ctx, key, ttl)
+		return nil
+	})
+
+	return err
+}
+
+// DelTyping clears a connection's typing indicator. It reports whether that
+// ended the user's typing in the conversation: the indicator was still live
+// and no other connection of the user is typing there.
+func DelTyping(rdb *redis.Client, conversationID, userID, connectionID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	key := []string{cacheKeyForTyping(conversationID, userID)}
+
+	stopped, err := delTypingScript.Run(ctx, rdb, key, connectionID, time.Now().UnixMilli()).Int64()
+	if err != nil {
+		return false, err
+	}
+
+	return stopped == 1, nil
+}
+
+func cacheKeyForTyping(conversationID, userID int64) string {
+	return "typing:" + strconv.FormatInt(conversationID, 10) + ":" + strconv.FormatInt(userID, 10)
+}
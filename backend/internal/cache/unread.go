@@ -0,0 +1,329 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// UnreadTTL drops the counters of users who stop showing up. They are
+// rebuilt from Postgres the next time they are needed.
+const UnreadTTL = 7 * 24 * time.Hour
+
+// The counters of a user live in one hash with a "<conversation>:unread" and
+// a "<conversation>:mentions" field per conversation. The hash also carries
+// a "built" field so it exists even when nothing is unread; counters are
+// only changed while it exists, since a partial hash would be taken for the
+// whole picture.
+//
+// Counters are rebuilt from Postgres while messages keep arriving. Postgres
+// is read up to a message id, and "<conversation>:last" holds the newest
+// message a counter reflects, so an increment for a message the rebuild
+// already counted is skipped. Increments are also logged for a short while so
+// that the ones for messages newer than what Postgres returned survive the
+// rebuild. Message ids don't fit in a Lua number, so the scripts compare them
+// as zero padded strings.
+const (
+	unreadLogLength = 1000
+	unreadLogTTL    = time.Minute
+)
+
+// unreadTotalsLua sums every counter of the hash and returns them along with
+// the counters of one conversation.
+const unreadTotalsLua = `
+local function totals(key, conversation)
+	local totalUnread, totalMentions = 0, 0
+	local fields = redis.call('HGETALL', key)
+	for i = 1, #fields, 2 do
+		if string.sub(fields[i], -7) == ':unread' then
+			totalUnread = totalUnread + tonumber(fields[i + 1])
+		elseif string.sub(fields[i], -9) == ':mentions' then
+			totalMentions = totalMentions + tonumber(fields[i + 1])
+		end
+	end
+
+	return {
+		tonumber(redis.call('HGET', key, conversation .. ':unread') or 0),
+		tonumber(redis.call('HGET', key, conversation .. ':mentions') or 0),
+		totalUnread,
+		totalMentions,
+	}
+end
+`
+
+// unreadReplayLua adds the logged increments for messages newer than
+// countedTo on top of counters just read from Postgres, optionally only
+// those of one conversation.
+const unreadReplayLua = `
+local function replay(key, logKey, countedTo, only)
+	for _, entry in ipairs(redis.call('LRANGE', logKey, 0, -1)) do
+		local id, conversation, mention = string.match(entry, '^(%d+):(%d+):(%d)$')
+		if id > countedTo and (only == nil or conversation == only) then
+			redis.call('HINCRBY', key, conversation .. ':unread', 1)
+			redis.call('HINCRBY', key, conversation .. ':mentions', tonumber(mention))
+			local last = redis.call('HGET', key, conversation .. ':last')
+			if not last or id > last then
+				redis.call('HSET', key, conversation .. ':last', id)
+			end
+		end
+	end
+end
+`
+
+var (
+	// Increments are logged even while the counters aren't built, since a
+	// first build may be reading Postgres at the same time.
+	incrUnreadScript = redis.NewScript(unreadTotalsLua + `
+local conversation, id, mention = ARGV[1], ARGV[2], ARGV[3]
+
+local last = redis.call('HGET', KEYS[1], conversation .. ':last')
+if last and id <= last then
+	return totals(KEYS[1], conversation)
+end
+
+redis.call('LPUSH', KEYS[2], id .. ':' .. conversation .. ':' .. mention)
+redis.call('LTRIM', KEYS[2], 0, tonumber(ARGV[4]) - 1)
+redis.call('PEXPIRE', KEYS[2], ARGV[5])
+
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+
+redis.call('HINCRBY', KEYS[1], conversation .. ':unread', 1)
+redis.call('HINCRBY', KEYS[1], conversation .. ':mentions', tonumber(mention))
+redis.call('HSET', KEYS[1], conversation .. ':last', id)
+
+return totals(KEYS[1], conversation)
+`)
+
+	setUnreadScript = redis.NewScript(unreadTotalsLua + unreadReplayLua + `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+
+local conversation, countedTo = ARGV[1], ARGV[2]
+
+redis.call('HSET', KEYS[1],
+	conversation .. ':unread', ARGV[3],
+	conversation .. ':mentions', ARGV[4],
+	conversation .. ':last', countedTo)
+replay(KEYS[1], KEYS[2], countedTo, conversation)
+
+return totals(KEYS[1], conversation)
+`)
+
+	replaceUnreadScript = redis.NewScript(unreadReplayLua + `
+local countedTo = ARGV[1]
+
+redis.call('DEL', KEYS[1])
+redis.call('HSET', KEYS[1], 'built', ARGV[2])
+for i = 4, #ARGV, 3 do
+	redis.call('HSET', KEYS[1],
+		ARGV[i] .. ':unread', ARGV[i + 1],
+		ARGV[i] .. ':mentions', ARGV[i + 2],
+		ARGV[i] .. ':last', countedTo)
+end
+replay(KEYS[1], KEYS[2], countedTo, nil)
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+
+return true
+`)
+)
+
+// IncrUnread counts a new message for each user whose counters are built and
+// returns their new values. Users without counters are skipped.
+func IncrUnread(rdb *redis.Client, userIDs []int64, conversationID, messageID int64, mentionedIDs []int64) ([]*model.UnreadUpdate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	conversation := strconv.FormatInt(conversationID, 10)
+
+	cmds := make([]*redis.Cmd, len(userIDs))
+	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, userID := range userIDs {
+			var mention int64
+			if slices.Contains(mentionedIDs, userID) {
+				mention = 1
+			}
+
+			keys := []string{cacheKeyForUnread(userID), cacheKeyForUnreadLog(userID)}
+			cmds[i] = incrUnreadScript.Eval(ctx, pipe, keys,
+				conversation,
+				paddedID(messageID),
+				mention,
+				unreadLogLength,
+				unreadLogTTL.Milliseconds(),
+			)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	var updates []*model.UnreadUpdate
+	for i, userID := range userIDs {
+		update, err := unreadUpdate(cmds[i], userID, conversationID)
+		if err != nil {
+			return nil, err
+		}
+		if update != nil {
+			updates = append(updates, update)
+		}
+	}
+
+	return updates, nil
+}
+
+// SetUnread overwrites the counters of a conversation for a user with ones
+// read from Postgres up to the message countedTo. It returns nil if the
+// user's counters aren't built.
+func SetUnread(rdb *redis.Client, userID int64, count *model.UnreadCount, countedTo int64) (*model.UnreadUpdate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	keys := []string{cacheKeyForUnread(userID), cacheKeyForUnreadLog(userID)}
+	cmd := setUnreadScript.Run(ctx, rdb, keys,
+		strconv.FormatInt(count.ConversationID, 10),
+		paddedID(countedTo),
+		count.Unread,
+		count.Mentions,
+	)
+
+	return unreadUpdate(cmd, userID, count.ConversationID)
+}
+
+func unreadUpdate(cmd *redis.Cmd, userID, conversationID int64) (*model.UnreadUpdate, error) {
+	values, err := cmd.Int64Slice()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &model.UnreadUpdate{
+		UserID: userID,
+		Count: &model.UnreadCount{
+			ConversationID: conversationID,
+			Unread:         values[0],
+			Mentions:       values[1],
+		},
+		Badge: &model.Badge{
+			Unread:   values[2],
+			Mentions: values[3],
+		},
+	}, nil
+}
+
+// GetUnread returns the counters of every conversation of a user that has
+// any, and whether the counters are built at all.
+func GetUnread(rdb *redis.Client, userID int64) (map[int64]*model.UnreadCount, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	key := cacheKeyForUnread(userID)
+
+	fields, err := rdb.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(fields) == 0 {
+		return nil, false, nil
+	}
+
+	counts := make(map[int64]*model.UnreadCount)
+	for field, value := range fields {
+		conversation, counter, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+
+		conversationID, err := strconv.ParseInt(conversation, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		count, ok := counts[conversationID]
+		if !ok {
+			count = &model.UnreadCount{ConversationID: conversationID}
+			counts[conversationID] = count
+		}
+
+		switch counter {
+		case "unread":
+			count.Unread = n
+		case "mentions":
+			count.Mentions = n
+		}
+	}
+
+	rdb.Expire(ctx, key, UnreadTTL)
+
+	return counts, true, nil
+}
+
+// SetAllUnread replaces every counter of a user with ones read from
+// Postgres up to the message countedTo, keeping the increments for newer
+// messages that arrived in the meantime.
+func SetAllUnread(rdb *redis.Client, userID int64, counts map[int64]*model.UnreadCount, countedTo int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := []any{paddedID(countedTo), time.Now().Unix(), int64(UnreadTTL.Seconds())}
+	for conversationID, count := range counts {
+		args = append(args, strconv.FormatInt(conversationID, 10), count.Unread, count.Mentions)
+	}
+
+	keys := []string{cacheKeyForUnread(userID), cacheKeyForUnreadLog(userID)}
+
+	return replaceUnreadScript.Run(ctx, rdb, keys, args...).Err()
+}
+
+// ScanUnreadUsers returns a batch of users whose counters are built along
+// with the cursor for the next batch, which is 0 after the last one.
+func ScanUnreadUsers(rdb *redis.Client, cursor uint64) ([]int64, uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	keys, next, err := rdb.Scan(ctx, cursor, "unread:*", 100).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		userID, err := strconv.ParseInt(strings.TrimPrefix(key, "unread:"), 10, 64)
+		if err == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	return userIDs, next, nil
+}
+
+func cacheKeyForUnread(userID int64) string {
+	return "unread:" + strconv.FormatInt(userID, 10)
+}
+
+func cacheKeyForUnreadLog(userID int64) string {
+	return "unread-log:" + strconv.FormatInt(userID, 10)
+}
+
+// paddedID formats a message id so that ids compare correctly as strings.
+func paddedID(id int64) string {
+	return fmt.Sprintf("%020d", id)
+}
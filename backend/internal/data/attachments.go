@@ -0,0 +1,939 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/RickinShah/BuzzChat/internal/blob"
+	"github.com/RickinShah/BuzzChat/internal/cache"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/media"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/security"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	MaxAttachmentsPerMessage = 10
+
+	// MaxChunkSize caps a single PATCH of an upload.
+	MaxChunkSize = 8 << 20
+
+	// UploadTTL is how long an unfinished upload can be resumed.
+	UploadTTL = 24 * time.Hour
+
+	// DownloadURLTTL is how long signed download links keep working.
+	DownloadURLTTL = time.Hour
+
+	// orphanGracePeriod keeps unsent attachments and unreferenced blobs
+	// around for a while, so a message being composed or a dedupe hit in
+	// flight doesn't lose its bytes.
+	orphanGracePeriod = 24 * time.Hour
+
+	// staleMediaAge is how long a blob can wait for its processing job
+	// before the job is assumed lost and queued again.
+	staleMediaAge = time.Hour
+
+	gcBatchSize = 500
+)
+
+var (
+	ErrQuotaExceeded    = errors.New("storage quota exceeded")
+	ErrOffsetMismatch   = errors.New("upload offset mismatch")
+	ErrUploadBusy       = errors.New("upload busy")
+	ErrUploadIncomplete = errors.New("upload incomplete")
+	ErrUploadCorrupt    = errors.New("upload does not match its checksum")
+	ErrInvalidDownload  = errors.New("invalid or expired download link")
+)
+
+func ValidateUpload(v *validator.Validator, upload *model.Upload, maxSize int64) {
+	v.Check(upload.Filename != "", "filename", "must be provided")
+	v.Check(utf8.RuneCountInString(upload.Filename) <= 255, "filename", "must not be more than 255 characters")
+	v.Check(!strings.ContainsAny(upload.Filename, `/\`), "filename", "must not contain path separators")
+	v.Check(upload.Size > 0, "size", "must be greater than zero")
+	v.Check(upload.Size <= maxSize, "size", fmt.Sprintf("must not be more than %d bytes", maxSize))
+}
+
+// AttachmentModel handles uploads and the attachments they turn into. Blob
+// bytes go through Store; only metadata is kept in Postgres.
+type AttachmentModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+	Store blob.Store
+	Conn  Conn
+}
+
+func partPrefix(uploadID int64) string {
+	return "uploads/" + strconv.FormatInt(uploadID, 10) + "/"
+}
+
+// partKey names a new part starting at offset. Every write gets its own
+// key, so a chunk that loses the race for an offset can't overwrite the
+// bytes of the one that won.
+func partKey(uploadID, offset int64) string {
+	suffix := make([]byte, 8)
+	rand.Read(suffix)
+
+	return fmt.Sprintf("%s%020d-%s", partPrefix(uploadID), offset, hex.EncodeToString(suffix))
+}
+
+func blobKey(sum string) string {
+	return "blobs/" + sum[:2] + "/" + sum
+}
+
+func thumbnailKey(sum string, size int32) string {
+	return "thumbnails/" + sum[:2] + "/" + sum + "/" + strconv.Itoa(int(size))
+}
+
+// CreateUpload starts an upload if it fits in what is left of the user's
+// quota. Bytes of unfinished uploads count towards the quota too.
+func (m AttachmentModel) CreateUpload(upload *model.Upload, quota int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	upload.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(UploadTTL), Valid: true}
+
+	args := db.InsertUploadParams{
+		UserPid:   upload.UserPid,
+		Filename:  upload.Filename,
+		Size:      upload.Size,
+		ExpiresAt: upload.ExpiresAt,
+	}
+
+	var row db.InsertUploadRow
+
+	err := withTx(ctx, m.Conn, func(queries *db.Queries) error {
+		if err := checkQuota(ctx, queries, upload.UserPid, upload.Size, quota); err != nil {
+			return err
+		}
+
+		var err error
+		row, err = queries.InsertUpload(ctx, args)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	upload.UploadID = row.UploadID
+	upload.UploadOffset = row.UploadOffset
+	upload.ContentType = row.ContentType
+	upload.CreatedAt = row.CreatedAt
+
+	return nil
+}
+
+// checkQuota makes sure size more bytes fit in the user's quota. The user is
+// locked until the transaction ends, so concurrent uploads can't each see
+// the same free space.
+func checkQuota(ctx context.Context, queries *db.Queries, userID, size, quota int64) error {
+	if err := queries.LockStorageUsage(ctx, userID); err != nil {
+		return err
+	}
+
+	used, err := queries.GetStorageUsage(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if used+size > quota {
+		return ErrQuotaExceeded
+	}
+
+	return nil
+}
+
+func (m AttachmentModel) GetUpload(uploadID, userID int64) (*model.Upload, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.GetUploadParams{
+		UploadID: uploadID,
+		UserPid:  userID,
+	}
+
+	row, err := m.DB.GetUpload(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &model.Upload{Upload: row}, nil
+}
+
+// WriteChunk stores the next chunk of an upload, which must start exactly
+// where the upload left off. The running SHA-256 is saved with the offset,
+// so finishing the upload doesn't have to read everything back.
+func (m AttachmentModel) WriteChunk(ctx context.Context, upload *model.Upload, offset int64, chunk []byte) error {
+	if offset != upload.UploadOffset {
+		return ErrOffsetMismatch
+	}
+
+	lock := "upload:" + strconv.FormatInt(upload.UploadID, 10)
+
+	acquired, err := cache.AcquireLock(m.Redis, lock, time.Minute)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return ErrUploadBusy
+	}
+	defer cache.ReleaseLock(m.Redis, lock)
+
+	// The upload is read again under the lock, as another chunk may have
+	// been written since the caller loaded it.
+	row, err := m.DB.GetUpload(ctx, db.GetUploadParams{
+		UploadID: upload.UploadID,
+		UserPid:  upload.UserPid,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRecordNotFound
+		}
+		return err
+	}
+
+	upload.Upload = row
+
+	if offset != upload.UploadOffset {
+		return ErrOffsetMismatch
+	}
+
+	digest := sha256.New()
+	if upload.HashState != nil {
+		if err := digest.(encoding.BinaryUnmarshaler).UnmarshalBinary(upload.HashState); err != nil {
+			return err
+		}
+	}
+	digest.Write(chunk)
+
+	state, err := digest.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	// The type is sniffed from the first bytes rather than trusted from the
+	// client.
+	contentType := upload.ContentType
+	if offset == 0 {
+		contentType = http.DetectContentType(chunk)
+	}
+
+	// The part only becomes part of the upload once AdvanceUpload records
+	// its key. Should the lock have expired and another chunk taken the
+	// offset first, this one is left unreferenced and removed.
+	key := partKey(upload.UploadID, offset)
+	if err := m.Store.Put(ctx, key, bytes.NewReader(chunk), int64(len(chunk)), "application/octet-stream"); err != nil {
+		return err
+	}
+
+	args := db.AdvanceUploadParams{
+		Written:      int64(len(chunk)),
+		ContentType:  contentType,
+		HashState:    state,
+		PartKey:      key,
+		UploadID:     upload.UploadID,
+		UploadOffset: offset,
+	}
+
+	rows, err := m.DB.AdvanceUpload(ctx, args)
+	if err == nil && rows == 0 {
+		err = ErrOffsetMismatch
+	}
+	if err != nil {
+		if err := m.Store.Delete(ctx, key); err != nil {
+			logger.PrintError(err, map[string]any{"upload_id": upload.UploadID})
+		}
+		return err
+	}
+
+	upload.UploadOffset += int64(len(chunk))
+	upload.ContentType = contentType
+	upload.HashState = state
+	upload.PartKeys = append(upload.PartKeys, key)
+
+	return nil
+}
+
+// verifyParts reports whether the parts of an upload hash to sum.
+func (m AttachmentModel) verifyParts(ctx context.Context, upload *model.Upload, sum string) (bool, error) {
+	parts := blob.Concat(ctx, m.Store, upload.PartKeys)
+	defer parts.Close()
+
+	digest := sha256.New()
+	n, err := io.Copy(digest, parts)
+	if err != nil {
+		return false, err
+	}
+
+	return n == upload.Size && hex.EncodeToString(digest.Sum(nil)) == sum, nil
+}
+
+// Finalize turns a complete upload into an attachment. If a blob with the
+// same SHA-256 already exists the parts are dropped and the blob is shared.
+func (m AttachmentModel) Finalize(ctx context.Context, upload *model.Upload) (*model.Attachment, error) {
+	if !upload.IsComplete() {
+		return nil, ErrUploadIncomplete
+	}
+
+	digest := sha256.New()
+	if err := digest.(encoding.BinaryUnmarshaler).UnmarshalBinary(upload.HashState); err != nil {
+		return nil, err
+	}
+	sum := hex.EncodeToString(digest.(hash.Hash).Sum(nil))
+
+	size := upload.Size
+
+	// Processing strips metadata from images, so a blob can end up smaller
+	// than the upload that created it.
+	var newBlob *db.UpsertBlobParams
+
+	existing, err := m.DB.TouchBlob(ctx, sum)
+	switch {
+	case err == nil:
+		size = existing.Size
+	case errors.Is(err, pgx.ErrNoRows):
+		// Blobs are shared by everyone who uploads the same content, so the
+		// parts are checked against the sum before they are stored under
+		// it.
+		valid, err := m.verifyParts(ctx, upload, sum)
+		if err != nil {
+			return nil, err
+		}
+		if !valid {
+			return nil, ErrUploadCorrupt
+		}
+
+		parts := blob.Concat(ctx, m.Store, upload.PartKeys)
+		err = m.Store.Put(ctx, blobKey(sum), parts, upload.Size, upload.ContentType)
+		parts.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		status := model.MediaReady
+		if media.CanProcess(upload.ContentType) {
+			status = model.MediaPending
+		}
+
+		newBlob = &db.UpsertBlobParams{
+			Sha256:      sum,
+			Size:        upload.Size,
+			ContentType: upload.ContentType,
+			StorageKey:  blobKey(sum),
+			Status:      status,
+		}
+	default:
+		return nil, err
+	}
+
+	attachment := &model.Attachment{
+		Attachment: db.Attachment{
+			UserPid:     upload.UserPid,
+			Sha256:      sum,
+			Filename:    upload.Filename,
+			Size:        size,
+			ContentType: upload.ContentType,
+		},
+		StorageKey: blobKey(sum),
+	}
+
+	args := db.InsertAttachmentParams{
+		UserPid:     attachment.UserPid,
+		Sha256:      attachment.Sha256,
+		Filename:    attachment.Filename,
+		Size:        attachment.Size,
+		ContentType: attachment.ContentType,
+	}
+
+	// Deleting the upload claims it, so finishing the same upload twice
+	// can't create two attachments.
+	err = withTx(ctx, m.Conn, func(queries *db.Queries) error {
+		rows, err := queries.DeleteUpload(ctx, db.DeleteUploadParams{
+			UploadID: upload.UploadID,
+			UserPid:  upload.UserPid,
+		})
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return ErrRecordNotFound
+		}
+
+		if newBlob != nil {
+			if err := queries.UpsertBlob(ctx, *newBlob); err != nil {
+				return err
+			}
+		}
+
+		row, err := queries.InsertAttachment(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		attachment.AttachmentID = row.AttachmentID
+		attachment.CreatedAt = row.CreatedAt
+
+		return attachMedia(ctx, queries, []*model.Attachment{attachment})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := blob.DeletePrefix(ctx, m.Store, partPrefix(upload.UploadID)); err != nil {
+		logger.PrintError(err, map[string]any{"upload_id": upload.UploadID})
+	}
+
+	return attachment, nil
+}
+
+// CancelUpload drops an upload and the parts received so far.
+func (m AttachmentModel) CancelUpload(ctx context.Context, uploadID, userID int64) error {
+	args := db.DeleteUploadParams{
+		UploadID: uploadID,
+		UserPid:  userID,
+	}
+
+	rows, err := m.DB.DeleteUpload(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
+	return blob.DeletePrefix(ctx, m.Store, partPrefix(uploadID))
+}
+
+func (m AttachmentModel) Get(attachmentID int64) (*model.Attachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	row, err := m.DB.GetAttachment(ctx, attachmentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &model.Attachment{
+		Attachment: db.Attachment{
+			AttachmentID: row.AttachmentID,
+			UserPid:      row.UserPid,
+			MessageID:    row.MessageID,
+			Sha256:       row.Sha256,
+			Filename:     row.Filename,
+			Size:         row.Size,
+			ContentType:  row.ContentType,
+			CreatedAt:    row.CreatedAt,
+		},
+		StorageKey: row.StorageKey,
+		Media: &model.Media{
+			Status:        row.Status,
+			Thumbnails:    thumbnails(row.ThumbnailSizes),
+			ThumbnailType: row.ThumbnailType.String,
+		},
+	}, nil
+}
+
+// GetUnsent returns the attachments among attachmentIDs that userID
+// uploaded and hasn't sent yet.
+func (m AttachmentModel) GetUnsent(userID int64, attachmentIDs []int64) ([]*model.Attachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.ListUnlinkedAttachmentsParams{
+		AttachmentIds: attachmentIDs,
+		UserPid:       userID,
+	}
+
+	rows, err := m.DB.ListUnlinkedAttachments(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]*model.Attachment, 0, len(rows))
+	for _, row := range rows {
+		attachments = append(attachments, &model.Attachment{Attachment: row})
+	}
+
+	if err := attachMedia(ctx, m.DB, attachments); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// GetForBlob returns every attachment sharing a blob, with the conversation
+// of the message it was sent in, if any.
+func (m AttachmentModel) GetForBlob(sum string) ([]*model.Attachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.ListBlobAttachments(ctx, sum)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]*model.Attachment, 0, len(rows))
+	for _, row := range rows {
+		attachments = append(attachments, &model.Attachment{
+			Attachment: db.Attachment{
+				AttachmentID: row.AttachmentID,
+				UserPid:      row.UserPid,
+				MessageID:    row.MessageID,
+				Sha256:       row.Sha256,
+				Filename:     row.Filename,
+				Size:         row.Size,
+				ContentType:  row.ContentType,
+				CreatedAt:    row.CreatedAt,
+			},
+			ConversationID: row.ConversationID.Int64,
+		})
+	}
+
+	if err := attachMedia(ctx, m.DB, attachments); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// attachMedia fills in the media details of attachments and signs their
+// download and thumbnail links.
+func attachMedia(ctx context.Context, queries *db.Queries, attachments []*model.Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	sums := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		sums = append(sums, attachment.Sha256)
+	}
+
+	rows, err := queries.ListBlobMedia(ctx, sums)
+	if err != nil {
+		return err
+	}
+
+	media := make(map[string]db.ListBlobMediaRow, len(rows))
+	for _, row := range rows {
+		media[row.Sha256] = row
+	}
+
+	for _, attachment := range attachments {
+		row := media[attachment.Sha256]
+		attachment.Media = &model.Media{
+			Status:     row.Status,
+			Width:      row.Width.Int32,
+			Height:     row.Height.Int32,
+			DurationMs: row.DurationMs.Int64,
+			Blurhash:   row.Blurhash.String,
+			Thumbnails: thumbnails(row.ThumbnailSizes),
+		}
+		signDownload(attachment)
+	}
+
+	return nil
+}
+
+func thumbnails(sizes []int32) []*model.Thumbnail {
+	thumbnails := make([]*model.Thumbnail, 0, len(sizes))
+	for _, size := range sizes {
+		thumbnails = append(thumbnails, &model.Thumbnail{Size: size})
+	}
+	return thumbnails
+}
+
+// Link attaches unsent attachments to a message. It fails with
+// ErrEditConflict if any of them was sent in the meantime.
+func (m AttachmentModel) Link(message *model.Message, attachments []*model.Attachment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	attachmentIDs := make([]int64, 0, len(attachments))
+	for _, attachment := range attachments {
+		attachmentIDs = append(attachmentIDs, attachment.AttachmentID)
+	}
+
+	args := db.LinkAttachmentsParams{
+		MessageID:     pgtype.Int8{Int64: message.MessageID, Valid: true},
+		AttachmentIds: attachmentIDs,
+		UserPid:       message.SenderPid.Int64,
+	}
+
+	rows, err := m.DB.LinkAttachments(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if int(rows) != len(attachments) {
+		return ErrEditConflict
+	}
+
+	for _, attachment := range attachments {
+		attachment.MessageID = args.MessageID
+	}
+	message.Attachments = attachments
+
+	return nil
+}
+
+// Copy gives a forwarded message the attachments of the message it was
+// forwarded from. The copies point at the same blobs, so no bytes are
+// duplicated, but they count towards the forwarder's quota all the same.
+func (m AttachmentModel) Copy(source, message *model.Message, quota int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.CopyAttachmentsParams{
+		UserPid:   message.SenderPid.Int64,
+		MessageID: message.MessageID,
+		SourceID:  source.MessageID,
+	}
+
+	var rows int64
+
+	err := withTx(ctx, m.Conn, func(queries *db.Queries) error {
+		size, err := queries.GetMessageAttachmentSize(ctx, source.MessageID)
+		if err != nil {
+			return err
+		}
+
+		if size == 0 {
+			return nil
+		}
+
+		if err := checkQuota(ctx, queries, args.UserPid, size, quota); err != nil {
+			return err
+		}
+
+		rows, err = queries.CopyAttachments(ctx, args)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return nil
+	}
+
+	return MessageModel{m.DB, m.Redis, m.Conn}.attachAttachments(ctx, []*model.Message{message})
+}
+
+// Open streams the bytes of an attachment.
+func (m AttachmentModel) Open(ctx context.Context, attachment *model.Attachment) (io.ReadCloser, error) {
+	r, err := m.Store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// OpenThumbnail streams one of the thumbnails of an attachment loaded
+// with Get. It returns ErrRecordNotFound if there is no thumbnail of that
+// size.
+func (m AttachmentModel) OpenThumbnail(ctx context.Context, attachment *model.Attachment, size int32) (io.ReadCloser, error) {
+	if !slices.ContainsFunc(attachment.Media.Thumbnails, func(t *model.Thumbnail) bool { return t.Size == size }) {
+		return nil, ErrRecordNotFound
+	}
+
+	r, err := m.Store.Get(ctx, thumbnailKey(attachment.Sha256, size))
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// ProcessMedia runs the media processing for a pending blob. For images
+// the stripped image replaces the original bytes, and its size,
+// dimensions, blurhash and thumbnails are recorded; for videos only the
+// dimensions and duration are. It reports whether anything changed; blobs
+// that were already processed are skipped, so running it twice is harmless.
+//
+// Files that can't be decoded are marked failed. Failed videos are served
+// as uploaded, always as a download; failed images aren't served at all, as
+// their metadata is still in them.
+func (m AttachmentModel) ProcessMedia(ctx context.Context, sum string) (bool, error) {
+	b, err := m.DB.GetBlob(ctx, sum)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if b.Status != model.MediaPending {
+		return false, nil
+	}
+
+	r, err := m.Store.Get(ctx, b.StorageKey)
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+
+	args := db.UpdateBlobMediaParams{
+		Sha256:         sum,
+		Size:           b.Size,
+		Status:         model.MediaFailed,
+		ThumbnailSizes: []int32{},
+	}
+
+	if media.IsVideo(b.ContentType) {
+		video, err := media.ProbeVideo(r)
+		if err != nil {
+			if !errors.Is(err, media.ErrInvalidVideo) {
+				return false, err
+			}
+			logger.PrintInfo("failed to process media: "+err.Error(), map[string]any{"sha256": sum})
+			return true, m.DB.UpdateBlobMedia(ctx, args)
+		}
+
+		args.Status = model.MediaReady
+		if video.Width > 0 {
+			args.Width = pgtype.Int4{Int32: int32(video.Width), Valid: true}
+			args.Height = pgtype.Int4{Int32: int32(video.Height), Valid: true}
+		}
+		args.DurationMs = pgtype.Int8{Int64: video.Duration.Milliseconds(), Valid: true}
+
+		return true, m.DB.UpdateBlobMedia(ctx, args)
+	}
+
+	original, err := io.ReadAll(io.LimitReader(r, b.Size))
+	if err != nil {
+		return false, err
+	}
+
+	result, err := media.Process(original, b.ContentType)
+	if err != nil {
+		logger.PrintInfo("failed to process media: "+err.Error(), map[string]any{"sha256": sum})
+		return true, m.DB.UpdateBlobMedia(ctx, args)
+	}
+
+	for _, thumbnail := range result.Thumbnails {
+		key := thumbnailKey(sum, int32(thumbnail.Size))
+		if err := m.Store.Put(ctx, key, bytes.NewReader(thumbnail.Data), int64(len(thumbnail.Data)), result.ThumbnailType); err != nil {
+			return false, err
+		}
+		args.ThumbnailSizes = append(args.ThumbnailSizes, int32(thumbnail.Size))
+	}
+
+	if err := m.Store.Put(ctx, b.StorageKey, bytes.NewReader(result.Data), int64(len(result.Data)), b.ContentType); err != nil {
+		return false, err
+	}
+
+	args.Size = int64(len(result.Data))
+	args.Status = model.MediaReady
+	args.Width = pgtype.Int4{Int32: int32(result.Width), Valid: true}
+	args.Height = pgtype.Int4{Int32: int32(result.Height), Valid: true}
+	args.Blurhash = pgtype.Text{String: result.Blurhash, Valid: true}
+	args.ThumbnailType = pgtype.Text{String: result.ThumbnailType, Valid: true}
+
+	return true, m.DB.UpdateBlobMedia(ctx, args)
+}
+
+// FailMedia marks a blob whose processing kept failing as failed. It
+// reports whether the blob was still pending.
+func (m AttachmentModel) FailMedia(ctx context.Context, sum string) (bool, error) {
+	rows, err := m.DB.FailBlobMedia(ctx, sum)
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// StaleMedia returns blobs that have been pending for longer than any
+// processing job takes. Their job was lost, so they need to be queued
+// again; until they're processed downloads of them are refused.
+func (m AttachmentModel) StaleMedia(ctx context.Context) ([]string, error) {
+	args := db.ListStalePendingBlobsParams{
+		CreatedBefore: pgtype.Timestamptz{Time: time.Now().Add(-staleMediaAge), Valid: true},
+		BatchSize:     gcBatchSize,
+	}
+
+	return m.DB.ListStalePendingBlobs(ctx, args)
+}
+
+type GCStats struct {
+	Uploads     int `json:"uploads"`
+	Attachments int `json:"attachments"`
+	Blobs       int `json:"blobs"`
+}
+
+// CollectGarbage removes expired uploads, attachments that were never sent
+// and blobs no attachment points to anymore.
+func (m AttachmentModel) CollectGarbage(ctx context.Context) (GCStats, error) {
+	var stats GCStats
+
+	uploadIDs, err := m.DB.DeleteExpiredUploads(ctx, gcBatchSize)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, uploadID := range uploadIDs {
+		if err := blob.DeletePrefix(ctx, m.Store, partPrefix(uploadID)); err != nil {
+			return stats, err
+		}
+		stats.Uploads++
+	}
+
+	cutoff := pgtype.Timestamptz{Time: time.Now().Add(-orphanGracePeriod), Valid: true}
+
+	attachmentArgs := db.DeleteUnlinkedAttachmentsParams{
+		CreatedBefore: cutoff,
+		BatchSize:     gcBatchSize,
+	}
+
+	deleted, err := m.DB.DeleteUnlinkedAttachments(ctx, attachmentArgs)
+	if err != nil {
+		return stats, err
+	}
+	stats.Attachments = int(deleted)
+
+	blobArgs := db.DeleteOrphanBlobsParams{
+		UsedBefore: cutoff,
+		BatchSize:  gcBatchSize,
+	}
+
+	blobs, err := m.DB.DeleteOrphanBlobs(ctx, blobArgs)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, row := range blobs {
+		if err := m.Store.Delete(ctx, row.StorageKey); err != nil {
+			return stats, err
+		}
+
+		for _, size := range row.ThumbnailSizes {
+			if err := m.Store.Delete(ctx, thumbnailKey(row.Sha256, size)); err != nil {
+				return stats, err
+			}
+		}
+		stats.Blobs++
+	}
+
+	return stats, nil
+}
+
+// Lock claims the next garbage collection round for ttl across instances.
+func (m AttachmentModel) Lock(ttl time.Duration) (bool, error) {
+	return cache.AcquireLock(m.Redis, "attachment-gc", ttl)
+}
+
+func (m MessageModel) attachAttachments(ctx context.Context, messages []*model.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Message, len(messages))
+	messageIDs := make([]int64, 0, len(messages))
+	for _, message := range messages {
+		byID[message.MessageID] = message
+		messageIDs = append(messageIDs, message.MessageID)
+	}
+
+	rows, err := m.DB.ListMessageAttachments(ctx, messageIDs)
+	if err != nil {
+		return err
+	}
+
+	attachments := make([]*model.Attachment, 0, len(rows))
+	for _, row := range rows {
+		attachment := &model.Attachment{Attachment: row}
+		attachments = append(attachments, attachment)
+
+		message := byID[row.MessageID.Int64]
+		message.Attachments = append(message.Attachments, attachment)
+	}
+
+	return attachMedia(ctx, m.DB, attachments)
+}
+
+type downloadToken struct {
+	AttachmentID int64 `json:"a"`
+	Expires      int64 `json:"e"`
+}
+
+// signDownload sets a download URL that needs no session, so it can be
+// handed to image tags and download managers, but only works for a while.
+func signDownload(attachment *model.Attachment) {
+	expires := time.Now().Add(DownloadURLTTL).Truncate(time.Second)
+
+	payload, err := json.Marshal(downloadToken{AttachmentID: attachment.AttachmentID, Expires: expires.Unix()})
+	if err != nil {
+		return
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(security.SignWithPayload(payload))
+
+	prefix := "/v1/attachments/" + strconv.FormatInt(attachment.AttachmentID, 10)
+
+	attachment.URL = prefix + "/download?token=" + token
+	attachment.URLExpiresAt = expires
+
+	if attachment.Media != nil {
+		for _, thumbnail := range attachment.Media.Thumbnails {
+			thumbnail.URL = prefix + "/thumbnails/" + strconv.Itoa(int(thumbnail.Size)) + "?token=" + token
+		}
+	}
+}
+
+// VerifyDownload checks a download token for the given attachment.
+func VerifyDownload(attachmentID int64, token string) error {
+	signed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ErrInvalidDownload
+	}
+
+	payload, err := security.VerifyWithPayload(signed)
+	if err != nil {
+		return ErrInvalidDownload
+	}
+
+	var t downloadToken
+	if err := json.Unmarshal(payload, &t); err != nil {
+		return ErrInvalidDownload
+	}
+
+	if t.AttachmentID != attachmentID || time.Now().Unix() > t.Expires {
+		return ErrInvalidDownload
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package data
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+func TestValidateUpload(t *testing.T) {
+	const maxSize = 1 << 20
+
+	tests := []struct {
+		name     string
+		filename string
+		size     int64
+		valid    bool
+	}{
+		{"valid", "photo.jpg", 1024, true},
+		{"at the size limit", "photo.jpg", maxSize, true},
+		{"long multibyte name", strings.Repeat("é", 255), 1, true},
+		{"no name", "", 1024, false},
+		{"name too long", strings.Repeat("a", 256), 1024, false},
+		{"slash", "../photo.jpg", 1024, false},
+		{"backslash", `..\photo.jpg`, 1024, false},
+		{"empty", "photo.jpg", 0, false},
+		{"negative", "photo.jpg", -1, false},
+		{"too large", "photo.jpg", maxSize + 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upload := &model.Upload{Upload: db.Upload{Filename: tt.filename, Size: tt.size}}
+
+			v := validator.New()
+			ValidateUpload(v, upload, maxSize)
+
+			if v.Valid() != tt.valid {
+				t.Fatalf("got valid %v, want %v: %v", v.Valid(), tt.valid, v.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrContactExists  = errors.New("contact already exists")
+	ErrRequestPending = errors.New("contact request already pending")
+	ErrContactBlocked = errors.New("contact blocked")
+)
+
+type ContactModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+}
+
+func newContactEntry(userID int64, username string, name, profilePic pgtype.Text, status string, createdAt, updatedAt pgtype.Timestamptz) *model.ContactEntry {
+	user := model.NewUser(&db.User{
+		UserPid:    userID,
+		Username:   username,
+		Name:       name,
+		ProfilePic: profilePic,
+	})
+	user.SetMarshalType(model.Minimal)
+
+	return &model.ContactEntry{
+		User:      user,
+		Status:    status,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}
+}
+
+func (m ContactModel) Get(userID, otherID int64) (*model.Contact, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.GetContactParams{
+		RequesterPid: userID,
+		AddresseePid: otherID,
+	}
+
+	contact, err := m.DB.GetContact(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &model.Contact{Contact: contact}, nil
+}
+
+// Request sends a contact request from requesterID to addresseeID. If the
+// addressee already asked for the same thing, the pending request is accepted
+// instead of creating a second one.
+func (m ContactModel) Request(requesterID, addresseeID int64) (*model.Contact, error) {
+	blocks, err := m.getBlocks(requesterID, addresseeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if blocks.Blocking || blocks.BlockedBy {
+		return nil, ErrContactBlocked
+	}
+
+	contact, err := m.Get(requesterID, addresseeID)
+	if err != nil && !errors.Is(err, ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if contact != nil {
+		switch contact.Status {
+		case model.ContactPending:
+			if contact.IsRequester(requesterID) {
+				return nil, ErrRequestPending
+			}
+			return m.Accept(requesterID, addresseeID)
+		case model.ContactAccepted:
+			return nil, ErrContactExists
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.InsertContactParams{
+		RequesterPid: requesterID,
+		AddresseePid: addresseeID,
+	}
+
+	row, err := m.DB.InsertContact(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRequestPending
+		}
+		return nil, err
+	}
+
+	return &model.Contact{Contact: row}, nil
+}
+
+func (m ContactModel) updateStatus(requesterID, addresseeID int64, oldStatus, newStatus string) (*model.Contact, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.UpdateContactStatusParams{
+		NewStatus:    newStatus,
+		RequesterPid: requesterID,
+		AddresseePid: addresseeID,
+		OldStatus:    oldStatus,
+	}
+
+	row, err := m.DB.UpdateContactStatus(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &model.Contact{Contact: row}, nil
+}
+
+func (m ContactModel) Accept(userID, requesterID int64) (*model.Contact, error) {
+	return m.updateStatus(requesterID, userID, model.ContactPending, model.ContactAccepted)
+}
+
+func (m ContactModel) Decline(userID, requesterID int64) error {
+	_, err := m.updateStatus(requesterID, userID, model.ContactPending, model.ContactDeclined)
+	return err
+}
+
+func (m ContactModel) deleteRequest(requesterID, addresseeID int64, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.DeleteContactRequestParams{
+		RequesterPid: requesterID,
+		AddresseePid: addresseeID,
+		Status:       status,
+	}
+
+	rows, err := m.DB.DeleteContactRequest(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m ContactModel) Cancel(userID, addresseeID int64) error {
+	return m.deleteRequest(userID, addresseeID, model.ContactPending)
+}
+
+func (m ContactModel) Remove(userID, otherID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.DeleteContactParams{
+		RequesterPid: userID,
+		AddresseePid: otherID,
+	}
+
+	rows, err := m.DB.DeleteContact(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Block stops otherID from reaching userID and ends any contact or pending
+// request between them. Blocks are kept per direction, so either user can
+// block the other regardless of what the other one did.
+func (m ContactModel) Block(userID, otherID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.BlockUserParams{
+		BlockerPid: userID,
+		BlockedPid: otherID,
+	}
+
+	return m.DB.BlockUser(ctx, args)
+}
+
+func (m ContactModel) Unblock(userID, otherID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.UnblockUserParams{
+		BlockerPid: userID,
+		BlockedPid: otherID,
+	}
+
+	rows, err := m.DB.UnblockUser(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m ContactModel) getBlocks(userID, otherID int64) (db.GetBlocksRow, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.GetBlocksParams{
+		UserPid:  userID,
+		OtherPid: otherID,
+	}
+
+	return m.DB.GetBlocks(ctx, args)
+}
+
+func (m ContactModel) GetAll(userID int64, filters Filters) ([]*model.ContactEntry, Metadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.ListContactsParams{
+		UserPid:    userID,
+		Descending: filters.keysetDescending(),
+		PageLimit:  filters.keysetLimit(),
+	}
+
+	if c := filters.keyset(); c != nil {
+		args.HasCursor = true
+		args.CursorUsername = c.Value
+		args.CursorID = c.ID
+	}
+
+	rows, err := m.DB.ListContacts(ctx, args)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	total, err := m.DB.CountContacts(ctx, userID)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	contacts := make([]*model.ContactEntry, 0, len(rows))
+	for _, row := range rows {
+		contacts = append(contacts, newContactEntry(row.UserPid, row.Username, row.Name, row.ProfilePic, row.Status, row.CreatedAt, row.UpdatedAt))
+	}
+
+	contacts, metadata := calculateKeysetMetadata(contacts, filters, func(c *model.ContactEntry) (string, int64) {
+		return c.User.Username, c.User.UserPid
+	})
+	metadata.TotalRecords = int(total)
+
+	return contacts, metadata, nil
+}
+
+func (m ContactModel) GetRequests(userID int64, incoming bool) ([]*model.ContactEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var requests []*model.ContactEntry
+
+	if incoming {
+		rows, err := m.DB.ListIncomingContactRequests(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			requests = append(requests, newContactEntry(row.UserPid, row.Username, row.Name, row.ProfilePic, row.Status, row.CreatedAt, row.UpdatedAt))
+		}
+		return requests, nil
+	}
+
+	rows, err := m.DB.ListOutgoingContactRequests(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		requests = append(requests, newContactEntry(row.UserPid, row.Username, row.Name, row.ProfilePic, row.Status, row.CreatedAt, row.UpdatedAt))
+	}
+
+	return requests, nil
+}
+
+func (m ContactModel) GetBlocked(userID int64) ([]*model.ContactEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.ListBlockedUsers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := make([]*model.ContactEntry, 0, len(rows))
+	for _, row := range rows {
+		blocked = append(blocked, newContactEntry(row.UserPid, row.Username, row.Name, row.ProfilePic, model.ContactBlocked, row.CreatedAt, row.CreatedAt))
+	}
+
+	return blocked, nil
+}
+
+func (m ContactModel) GetIDs(userID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.ListContactIDs(ctx, userID)
+}
+
+func (m ContactModel) Relationship(viewerID, targetID int64) (model.Relationship, error) {
+	if viewerID == targetID {
+		return model.RelationSelf, nil
+	}
+
+	blocks, err := m.getBlocks(viewerID, targetID)
+	if err != nil {
+		return model.RelationNone, err
+	}
+
+	switch {
+	case blocks.Blocking:
+		return model.RelationBlocking, nil
+	case blocks.BlockedBy:
+		return model.RelationBlockedBy, nil
+	}
+
+	contact, err := m.Get(viewerID, targetID)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return model.RelationNone, nil
+		}
+		return model.RelationNone, err
+	}
+
+	if contact.Status == model.ContactAccepted {
+		return model.RelationContact, nil
+	}
+
+	return model.RelationNone, nil
+}
+
+// GetBlockedIDs returns the users on either side of a block with userID.
+func (m ContactModel) GetBlockedIDs(userID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.ListBlockedIDs(ctx, userID)
+}
@@ -0,0 +1,283 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+)
+
+type ConversationModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+	Conn  Conn
+}
+
+// GetOrCreateDirect returns the direct conversation between two users,
+// creating it on first use. The boolean reports whether it was created.
+func (m ConversationModel) GetOrCreateDirect(userID, otherID, viewerID int64) (*model.Conversation, bool, error) {
+	directKey := pgtype.Text{String: model.DirectKey(userID, otherID), Valid: true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	created := true
+
+	args := db.InsertDirectConversationParams{
+		DirectKey: directKey,
+		UserPids:  []int64{userID, otherID},
+	}
+
+	conversationID, err := m.DB.InsertDirectConversation(ctx, args)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, err
+		}
+
+		conversation, err := m.DB.GetConversationByDirectKey(ctx, directKey)
+		if err != nil {
+			return nil, false, err
+		}
+		conversationID = conversation.ConversationID
+		created = false
+	}
+
+	conversation, err := m.Get(conversationID, viewerID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return conversation, created, nil
+}
+
+// Get returns the conversation only if userID takes part in it, so callers
+// get ErrRecordNotFound for conversations they can't see.
+func (m ConversationModel) Get(conversationID, userID int64) (*model.Conversation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.GetConversationForParticipantParams{
+		ConversationID: conversationID,
+		UserPid:        userID,
+	}
+
+	row, err := m.DB.GetConversationForParticipant(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	conversations := []*model.Conversation{{Conversation: row}}
+	if err := m.attach(ctx, conversations, userID); err != nil {
+		return nil, err
+	}
+
+	return conversations[0], nil
+}
+
+func (m ConversationModel) IsParticipant(conversationID, userID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.IsParticipantParams{
+		ConversationID: conversationID,
+		UserPid:        userID,
+	}
+
+	return m.DB.IsParticipant(ctx, args)
+}
+
+func (m ConversationModel) GetParticipantIDs(conversationID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.ListParticipantIDs(ctx, conversationID)
+}
+
+func (m ConversationModel) GetAllForUser(userID int64, filters Filters) ([]*model.Conversation, Metadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.ListConversationsParams{
+		UserPid:    userID,
+		Descending: filters.keysetDescending(),
+		PageLimit:  filters.keysetLimit(),
+	}
+
+	if c := filters.keyset(); c != nil {
+		args.HasCursor = true
+		args.CursorID = c.ID
+	}
+
+	rows, err := m.DB.ListConversations(ctx, args)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	total, err := m.DB.CountConversations(ctx, userID)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	conversations := make([]*model.Conversation, 0, len(rows))
+	for _, row := range rows {
+		conversations = append(conversations, &model.Conversation{Conversation: row})
+	}
+
+	conversations, metadata := calculateKeysetMetadata(conversations, filters, func(c *model.Conversation) (string, int64) {
+		return strconv.FormatInt(c.ActivityID(), 10), c.ActivityID()
+	})
+	metadata.TotalRecords = int(total)
+
+	if err := m.attach(ctx, conversations, userID); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return conversations, metadata, nil
+}
+
+// UpdateSettings saves the settings of a conversation.
+func (m ConversationModel) UpdateSettings(conversation *model.Conversation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.UpdateConversationSettingsParams{
+		LinkPreviews:    conversation.LinkPreviews,
+		MentionAll:      conversation.MentionAll,
+		MessageTtl:      conversation.MessageTtl,
+		AllowForwarding: conversation.AllowForwarding,
+		ConversationID:  conversation.ConversationID,
+		Version:         conversation.Version,
+	}
+
+	row, err := m.DB.UpdateConversationSettings(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	conversation.UpdatedAt = row.UpdatedAt
+	conversation.Version = row.Version
+
+	m.invalidateMembership(conversation.ConversationID)
+
+	return nil
+}
+
+// Mute silences a conversation for userID until the given time. An
+// invalid until unmutes it.
+func (m ConversationModel) Mute(conversationID, userID int64, until pgtype.Timestamptz) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.MuteConversationParams{
+		MutedUntil:     until,
+		ConversationID: conversationID,
+		UserPid:        userID,
+	}
+
+	rows, err := m.DB.MuteConversation(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetMutedIDs returns the participants who have muted a conversation.
+func (m ConversationModel) GetMutedIDs(conversationID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.ListMutedParticipantIDs(ctx, conversationID)
+}
+
+// attach loads participants and last messages for a page of conversations
+// with one query each instead of one per conversation.
+func (m ConversationModel) attach(ctx context.Context, conversations []*model.Conversation, viewerID int64) error {
+	if len(conversations) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Conversation, len(conversations))
+	conversationIDs := make([]int64, 0, len(conversations))
+	messageIDs := make([]int64, 0, len(conversations))
+
+	for _, c := range conversations {
+		byID[c.ConversationID] = c
+		conversationIDs = append(conversationIDs, c.ConversationID)
+		if c.LastMessageID.Valid {
+			messageIDs = append(messageIDs, c.LastMessageID.Int64)
+		}
+	}
+
+	args := db.ListParticipantsParams{
+		ViewerPid:       viewerID,
+		ConversationIds: conversationIDs,
+	}
+
+	participants, err := m.DB.ListParticipants(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range participants {
+		user := model.NewUser(&db.User{
+			UserPid:    p.UserPid,
+			Username:   p.Username,
+			Name:       p.Name,
+			ProfilePic: p.ProfilePic,
+		})
+		user.SetMarshalType(model.Minimal)
+
+		c := byID[p.ConversationID]
+		if p.UserPid == viewerID {
+			c.MutedUntil = p.MutedUntil
+		}
+
+		c.Participants = append(c.Participants, &model.Participant{
+			User:     user,
+			Role:     p.Role,
+			JoinedAt: p.JoinedAt,
+			Receipt: &model.Receipt{
+				ConversationID:  p.ConversationID,
+				UserID:          p.UserPid,
+				LastDeliveredID: p.LastDeliveredID,
+				LastReadID:      p.LastReadID,
+			},
+		})
+	}
+
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	messages, err := m.DB.ListMessagesByID(ctx, messageIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		if c, ok := byID[message.ConversationID]; ok {
+			c.LastMessage = &model.Message{Message: message}
+		}
+	}
+
+	return nil
+}
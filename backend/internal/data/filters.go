@@ -0,0 +1,196 @@
+package data
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/RickinShah/BuzzChat/internal/security"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type Filters struct {
+	Page         int
+	PageSize     int
+	Sort         string
+	SortSafelist []string
+	Cursor       string
+}
+
+// Cursor points at the last row a client has seen. Value holds the sort
+// column of that row and ID breaks ties between rows with equal values.
+type Cursor struct {
+	Sort     string `json:"s"`
+	Value    string `json:"v,omitempty"`
+	ID       int64  `json:"i"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+type Metadata struct {
+	CurrentPage  int    `json:"currentPage,omitempty"`
+	PageSize     int    `json:"pageSize,omitempty"`
+	FirstPage    int    `json:"firstPage,omitempty"`
+	LastPage     int    `json:"lastPage,omitempty"`
+	TotalRecords int    `json:"totalRecords,omitempty"`
+	NextCursor   string `json:"nextCursor,omitempty"`
+	PrevCursor   string `json:"prevCursor,omitempty"`
+}
+
+func EncodeCursor(c Cursor) string {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(security.SignWithPayload(payload))
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	signed, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := security.VerifyWithPayload(signed)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+func ValidateFilters(v *validator.Validator, f Filters) {
+	v.Check(f.Page > 0, "page", "must be greater than zero")
+	v.Check(f.Page <= 10_000_000, "page", "must be a maximum of 10 million")
+	v.Check(f.PageSize > 0, "page_size", "must be greater than zero")
+	v.Check(f.PageSize <= 100, "page_size", "must be a maximum of 100")
+	v.Check(validator.In(f.Sort, f.SortSafelist...), "sort", "invalid sort value")
+
+	if f.Cursor != "" {
+		v.Check(f.Page == 1, "cursor", "cannot be combined with page")
+
+		c, err := DecodeCursor(f.Cursor)
+		if err != nil {
+			v.AddError("cursor", "is invalid")
+			return
+		}
+		v.Check(c.Sort == f.Sort, "cursor", "does not match the sort order")
+	}
+}
+
+func (f Filters) sortColumn() string {
+	if slices.Contains(f.SortSafelist, f.Sort) {
+		return strings.TrimPrefix(f.Sort, "-")
+	}
+
+	panic("unsafe sort parameter: " + f.Sort)
+}
+
+func (f Filters) sortDirection() string {
+	if strings.HasPrefix(f.Sort, "-") {
+		return "DESC"
+	}
+
+	return "ASC"
+}
+
+func (f Filters) limit() int32 {
+	return int32(f.PageSize)
+}
+
+func (f Filters) offset() int32 {
+	return int32((f.Page - 1) * f.PageSize)
+}
+
+// keyset returns the decoded cursor, or nil when the first page is requested.
+// Filters are expected to have gone through ValidateFilters already.
+func (f Filters) keyset() *Cursor {
+	if f.Cursor == "" {
+		return nil
+	}
+
+	c, err := DecodeCursor(f.Cursor)
+	if err != nil {
+		return nil
+	}
+
+	return c
+}
+
+// keysetLimit fetches one extra row so we know whether another page exists.
+func (f Filters) keysetLimit() int32 {
+	return int32(f.PageSize + 1)
+}
+
+// keysetDescending reports the order rows have to be read from the database
+// in. Walking backwards flips the requested sort direction.
+func (f Filters) keysetDescending() bool {
+	descending := f.sortDirection() == "DESC"
+
+	if c := f.keyset(); c != nil && c.Backward {
+		return !descending
+	}
+
+	return descending
+}
+
+func calculateMetadata(totalRecords, page, pageSize int) Metadata {
+	if totalRecords == 0 {
+		return Metadata{}
+	}
+
+	return Metadata{
+		CurrentPage:  page,
+		PageSize:     pageSize,
+		FirstPage:    1,
+		LastPage:     int(math.Ceil(float64(totalRecords) / float64(pageSize))),
+		TotalRecords: totalRecords,
+	}
+}
+
+// calculateKeysetMetadata trims the extra row fetched by keysetLimit, restores
+// the requested order for backward pages and builds the cursors around the
+// page. key returns the sort value and the id of a row.
+func calculateKeysetMetadata[T any](items []T, f Filters, key func(T) (string, int64)) ([]T, Metadata) {
+	c := f.keyset()
+	backward := c != nil && c.Backward
+
+	hasMore := len(items) > f.PageSize
+	if hasMore {
+		items = items[:f.PageSize]
+	}
+
+	if backward {
+		slices.Reverse(items)
+	}
+
+	metadata := Metadata{PageSize: f.PageSize}
+	if len(items) == 0 {
+		return items, metadata
+	}
+
+	cursorFor := func(item T, backward bool) string {
+		value, id := key(item)
+		return EncodeCursor(Cursor{Sort: f.Sort, Value: value, ID: id, Backward: backward})
+	}
+
+	if (!backward && hasMore) || backward {
+		metadata.NextCursor = cursorFor(items[len(items)-1], false)
+	}
+
+	if (backward && hasMore) || (!backward && c != nil) {
+		metadata.PrevCursor = cursorFor(items[0], true)
+	}
+
+	return items, metadata
+}
@@ -0,0 +1,133 @@
+package data
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+func TestMain(m *testing.M) {
+	flag.String("encryption-key", "abcdefghijklmnopqrstuvwxyzabcdef", "")
+	os.Exit(m.Run())
+}
+
+func TestDecodeCursor(t *testing.T) {
+	want := Cursor{Sort: "-id", Value: "7", ID: 7, Backward: true}
+	encoded := EncodeCursor(want)
+
+	got, err := DecodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *got != want {
+		t.Fatalf("got %+v, want %+v", *got, want)
+	}
+
+	for _, s := range []string{"", "not base64!", encoded[:len(encoded)-2], "x" + encoded[1:]} {
+		if _, err := DecodeCursor(s); err != ErrInvalidCursor {
+			t.Errorf("DecodeCursor(%q): got error %v, want %v", s, err, ErrInvalidCursor)
+		}
+	}
+}
+
+func TestValidateFilters(t *testing.T) {
+	safelist := []string{"id", "-id"}
+
+	tests := []struct {
+		name    string
+		filters Filters
+		errKey  string
+	}{
+		{"valid", Filters{Page: 1, PageSize: 20, Sort: "id"}, ""},
+		{"zero page", Filters{Page: 0, PageSize: 20, Sort: "id"}, "page"},
+		{"page too large", Filters{Page: 10_000_001, PageSize: 20, Sort: "id"}, "page"},
+		{"zero page size", Filters{Page: 1, PageSize: 0, Sort: "id"}, "page_size"},
+		{"page size too large", Filters{Page: 1, PageSize: 101, Sort: "id"}, "page_size"},
+		{"unknown sort", Filters{Page: 1, PageSize: 20, Sort: "name"}, "sort"},
+		{"valid cursor", Filters{Page: 1, PageSize: 20, Sort: "-id", Cursor: EncodeCursor(Cursor{Sort: "-id", ID: 1})}, ""},
+		{"cursor with page", Filters{Page: 2, PageSize: 20, Sort: "-id", Cursor: EncodeCursor(Cursor{Sort: "-id", ID: 1})}, "cursor"},
+		{"cursor for another sort", Filters{Page: 1, PageSize: 20, Sort: "id", Cursor: EncodeCursor(Cursor{Sort: "-id", ID: 1})}, "cursor"},
+		{"forged cursor", Filters{Page: 1, PageSize: 20, Sort: "id", Cursor: "eyJzIjoiaWQiLCJpIjoxfQ"}, "cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filters.SortSafelist = safelist
+
+			v := validator.New()
+			ValidateFilters(v, tt.filters)
+
+			if tt.errKey == "" {
+				if !v.Valid() {
+					t.Fatalf("unexpected errors: %v", v.Errors)
+				}
+				return
+			}
+
+			if _, ok := v.Errors[tt.errKey]; !ok {
+				t.Fatalf("expected an error for %q, got %v", tt.errKey, v.Errors)
+			}
+		})
+	}
+}
+
+func TestCalculateKeysetMetadata(t *testing.T) {
+	key := func(id int64) (string, int64) {
+		return strconv.FormatInt(id, 10), id
+	}
+
+	ids := func(from, to int64) []int64 {
+		var s []int64
+		for id := from; id <= to; id++ {
+			s = append(s, id)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		cursor   *Cursor
+		rows     []int64
+		want     []int64
+		wantNext bool
+		wantPrev bool
+	}{
+		{"first page with more", nil, ids(1, 4), ids(1, 3), true, false},
+		{"only page", nil, ids(1, 2), ids(1, 2), false, false},
+		{"middle page", &Cursor{Sort: "id", ID: 3}, ids(4, 7), ids(4, 6), true, true},
+		{"last page", &Cursor{Sort: "id", ID: 3}, ids(4, 5), ids(4, 5), false, true},
+		{"backward page with more", &Cursor{Sort: "id", ID: 7, Backward: true}, []int64{6, 5, 4, 3}, ids(4, 6), true, true},
+		{"backward to the start", &Cursor{Sort: "id", ID: 3, Backward: true}, []int64{2, 1}, ids(1, 2), true, false},
+		{"empty", nil, nil, nil, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Filters{Page: 1, PageSize: 3, Sort: "id", SortSafelist: []string{"id"}}
+			if tt.cursor != nil {
+				f.Cursor = EncodeCursor(*tt.cursor)
+			}
+
+			got, metadata := calculateKeysetMetadata(tt.rows, f, key)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+
+			if (metadata.NextCursor != "") != tt.wantNext {
+				t.Errorf("got next cursor %q, want one: %v", metadata.NextCursor, tt.wantNext)
+			}
+			if (metadata.PrevCursor != "") != tt.wantPrev {
+				t.Errorf("got prev cursor %q, want one: %v", metadata.PrevCursor, tt.wantPrev)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/RickinShah/BuzzChat/internal/cache"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5"
+)
+
+const MaxGroupMembers = 256
+
+func ValidateGroup(v *validator.Validator, conversation *model.Conversation) {
+	v.Check(conversation.Title.Valid && conversation.Title.String != "", "title", "must be provided")
+	v.Check(utf8.RuneCountInString(conversation.Title.String) <= 100, "title", "must not be more than 100 characters")
+
+	if conversation.Description.Valid {
+		v.Check(utf8.RuneCountInString(conversation.Description.String) <= 500, "description", "must not be more than 500 characters")
+	}
+	validator.NullifyEmptyText(&conversation.Description)
+
+	if conversation.Avatar.Valid {
+		v.Check(len(conversation.Avatar.String) <= 2048, "avatar", "must not be more than 2048 bytes long")
+	}
+	validator.NullifyEmptyText(&conversation.Avatar)
+}
+
+// Membership returns the kind and settings of a conversation and the role
+// of every participant. It is cached because it is checked on every message
+// send.
+func (m ConversationModel) Membership(conversationID int64) (*model.Membership, error) {
+	cachedMembership, isCached, _ := cache.GetCachedMembership(m.Redis, conversationID)
+	if isCached {
+		return cachedMembership, nil
+	}
+
+	// The generation is taken before the rows are read, so a write that
+	// commits in between stops the stale result from being cached.
+	generation, err := cache.MembershipGeneration(m.Redis, conversationID)
+	cacheable := err == nil
+	if err != nil {
+		logger.PrintInfo("failed to read membership generation:"+err.Error(), nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.GetMembership(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	membership := &model.Membership{
+		Kind:                 rows[0].Kind,
+		Roles:                make(map[int64]string, len(rows)),
+		LinkPreviews:         rows[0].LinkPreviews,
+		MentionAll:           rows[0].MentionAll,
+		ForwardingRestricted: !rows[0].AllowForwarding,
+	}
+	for _, row := range rows {
+		membership.Roles[row.UserPid] = row.Role
+	}
+
+	if cacheable {
+		if err := cache.SetCachedMembership(m.Redis, conversationID, membership, generation); err != nil {
+			logger.PrintInfo("failed to cache membership:"+err.Error(), nil)
+		}
+	}
+
+	return membership, nil
+}
+
+// invalidateMembership runs after a write to the participants or settings
+// of a conversation has been committed.
+func (m ConversationModel) invalidateMembership(conversationID int64) {
+	if err := cache.DelCachedMembership(m.Redis, conversationID); err != nil {
+		logger.PrintError(err, map[string]any{"conversation_id": conversationID})
+	}
+}
+
+func (m ConversationModel) CreateGroup(conversation *model.Conversation, ownerID int64, memberIDs []int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.InsertGroupConversationParams{
+		Title:       conversation.Title,
+		Avatar:      conversation.Avatar,
+		Description: conversation.Description,
+		OwnerPid:    ownerID,
+		MemberPids:  memberIDs,
+	}
+
+	conversationID, err := m.DB.InsertGroupConversation(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	conversation.ConversationID = conversationID
+	conversation.Kind = model.ConversationGroup
+
+	return nil
+}
+
+func (m ConversationModel) UpdateGroup(conversation *model.Conversation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.UpdateGroupConversationParams{
+		Title:          conversation.Title,
+		Avatar:         conversation.Avatar,
+		Description:    conversation.Description,
+		ConversationID: conversation.ConversationID,
+		Version:        conversation.Version,
+	}
+
+	row, err := m.DB.UpdateGroupConversation(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	conversation.UpdatedAt = row.UpdatedAt
+	conversation.Version = row.Version
+
+	return nil
+}
+
+func (m ConversationModel) Delete(conversationID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	defer m.invalidateMembership(conversationID)
+
+	return m.DB.DeleteConversation(ctx, conversationID)
+}
+
+// AddMembers adds users to a group and returns the ids of those who weren't
+// already members.
+func (m ConversationModel) AddMembers(conversationID int64, userIDs []int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	defer m.invalidateMembership(conversationID)
+
+	args := db.AddParticipantsParams{
+		ConversationID: conversationID,
+		UserPids:       userIDs,
+	}
+
+	return m.DB.AddParticipants(ctx, args)
+}
+
+func (m ConversationModel) RemoveMember(conversationID, userID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	defer m.invalidateMembership(conversationID)
+
+	args := db.RemoveParticipantParams{
+		ConversationID: conversationID,
+		UserPid:        userID,
+	}
+
+	rows, err := m.DB.RemoveParticipant(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SetRole changes a member's role only if it is still oldRole, so two admins
+// acting on the same member at once can't both succeed.
+func (m ConversationModel) SetRole(conversationID, userID int64, oldRole, newRole string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	defer m.invalidateMembership(conversationID)
+
+	args := db.UpdateParticipantRoleParams{
+		NewRole:        newRole,
+		ConversationID: conversationID,
+		UserPid:        userID,
+		OldRole:        oldRole,
+	}
+
+	rows, err := m.DB.UpdateParticipantRole(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrEditConflict
+	}
+
+	return nil
+}
+
+// TransferOwnership makes newOwnerID the owner and demotes the current owner
+// to admin. Unless both rows change, nothing does, so a conversation never
+// ends up with two owners or none.
+func (m ConversationModel) TransferOwnership(conversationID, ownerID, newOwnerID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.TransferOwnershipParams{
+		OwnerPid:       ownerID,
+		ConversationID: conversationID,
+		NewOwnerPid:    newOwnerID,
+	}
+
+	err := withTx(ctx, m.Conn, func(queries *db.Queries) error {
+		rows, err := queries.TransferOwnership(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		if rows != 2 {
+			return ErrEditConflict
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	m.invalidateMembership(conversationID)
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestValidateGroup(t *testing.T) {
+	text := func(s string) pgtype.Text {
+		return pgtype.Text{String: s, Valid: true}
+	}
+
+	tests := []struct {
+		name         string
+		conversation db.Conversation
+		errKey       string
+	}{
+		{"title only", db.Conversation{Title: text("Team")}, ""},
+		{"everything", db.Conversation{Title: text("Team"), Description: text("About"), Avatar: text("https://example.com/a.png")}, ""},
+		{"missing title", db.Conversation{}, "title"},
+		{"empty title", db.Conversation{Title: text("")}, "title"},
+		{"long title", db.Conversation{Title: text(strings.Repeat("t", 101))}, "title"},
+		{"long description", db.Conversation{Title: text("Team"), Description: text(strings.Repeat("d", 501))}, "description"},
+		{"long avatar", db.Conversation{Title: text("Team"), Avatar: text(strings.Repeat("a", 2049))}, "avatar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversation := &model.Conversation{Conversation: tt.conversation}
+
+			v := validator.New()
+			ValidateGroup(v, conversation)
+
+			if tt.errKey == "" {
+				if !v.Valid() {
+					t.Fatalf("unexpected errors: %v", v.Errors)
+				}
+				return
+			}
+
+			if _, ok := v.Errors[tt.errKey]; !ok {
+				t.Fatalf("expected an error for %q, got %v", tt.errKey, v.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateGroupNullifiesEmptyFields(t *testing.T) {
+	conversation := &model.Conversation{Conversation: db.Conversation{
+		Title:       pgtype.Text{String: "Team", Valid: true},
+		Description: pgtype.Text{Valid: true},
+		Avatar:      pgtype.Text{Valid: true},
+	}}
+
+	ValidateGroup(validator.New(), conversation)
+
+	if conversation.Description.Valid || conversation.Avatar.Valid {
+		t.Fatalf("got description %v and avatar %v, want both null", conversation.Description, conversation.Avatar)
+	}
+}
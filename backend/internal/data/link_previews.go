@@ -0,0 +1,97 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/cache"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+)
+
+type LinkPreviewModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+}
+
+// GetCached returns the cached preview of url. found is false if url hasn't
+// been fetched recently; a nil preview with found set means it has no
+// preview.
+func (m LinkPreviewModel) GetCached(url string) (preview *model.LinkPreview, found bool) {
+	cached, isCached, err := cache.GetCachedLinkPreview(m.Redis, url)
+	if err != nil || !isCached {
+		return nil, false
+	}
+
+	if cached.URL == "" {
+		return nil, true
+	}
+	return cached, true
+}
+
+func (m LinkPreviewModel) Cache(url string, preview *model.LinkPreview) {
+	if err := cache.SetCachedLinkPreview(m.Redis, url, preview); err != nil {
+		logger.PrintInfo("failed to cache link preview:"+err.Error(), nil)
+	}
+}
+
+func (m LinkPreviewModel) Set(messageID int64, preview *model.LinkPreview) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.UpsertLinkPreviewParams{
+		MessageID:   messageID,
+		Url:         preview.URL,
+		Title:       optionalText(preview.Title),
+		Description: optionalText(preview.Description),
+		ImageUrl:    optionalText(preview.ImageURL),
+		SiteName:    optionalText(preview.SiteName),
+	}
+
+	return m.DB.UpsertLinkPreview(ctx, args)
+}
+
+// Delete removes the preview of a message and reports whether it had one.
+func (m LinkPreviewModel) Delete(messageID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.DeleteLinkPreview(ctx, messageID)
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (m MessageModel) attachLinkPreviews(ctx context.Context, messages []*model.Message) error {
+	byID := make(map[int64]*model.Message, len(messages))
+	messageIDs := make([]int64, 0, len(messages))
+	for _, message := range messages {
+		byID[message.MessageID] = message
+		messageIDs = append(messageIDs, message.MessageID)
+	}
+
+	rows, err := m.DB.ListLinkPreviews(ctx, messageIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		byID[row.MessageID].LinkPreview = &model.LinkPreview{
+			URL:         row.Url,
+			Title:       row.Title.String,
+			Description: row.Description.String,
+			ImageURL:    row.ImageUrl.String,
+			SiteName:    row.SiteName.String,
+		}
+	}
+
+	return nil
+}
+
+func optionalText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
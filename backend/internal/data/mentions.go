@@ -0,0 +1,70 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+)
+
+// ResolveMentions looks up the users mentioned by username among the
+// members of a conversation. Mentions of anyone else are dropped; @here and
+// @all are kept as they are.
+func (m MessageModel) ResolveMentions(conversationID int64, mentions []*model.Mention) ([]*model.Mention, error) {
+	var usernames []string
+	for _, mention := range mentions {
+		if mention.Kind == model.MentionUser {
+			usernames = append(usernames, mention.Username)
+		}
+	}
+
+	if len(usernames) == 0 {
+		return mentions, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.ResolveMentionsParams{
+		ConversationID: conversationID,
+		Usernames:      usernames,
+	}
+
+	rows, err := m.DB.ResolveMentions(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		userIDs[row.Username] = row.UserPid
+	}
+
+	resolved := make([]*model.Mention, 0, len(mentions))
+	for _, mention := range mentions {
+		if mention.Kind == model.MentionUser {
+			userID, ok := userIDs[mention.Username]
+			if !ok {
+				continue
+			}
+			mention.UserID = userID
+		}
+		resolved = append(resolved, mention)
+	}
+
+	return resolved, nil
+}
+
+// AddMentions records who a message mentioned, for their mention counters.
+func (m MessageModel) AddMentions(messageID int64, userIDs []int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.InsertMentionsParams{
+		MessageID: messageID,
+		UserPids:  userIDs,
+	}
+
+	return m.DB.InsertMentions(ctx, args)
+}
@@ -0,0 +1,430 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+)
+
+const MaxMessageLength = 4000
+
+// ourEpoch matches the epoch next_id() counts milliseconds from. IDs keep
+// the milliseconds in the bits above the lowest 23, so they sort by time.
+const ourEpoch = 1733036400000
+
+func IDFromTime(t time.Time) int64 {
+	ms := t.UnixMilli() - ourEpoch
+	if ms < 0 {
+		return 0
+	}
+	return ms << 23
+}
+
+func TimeFromID(id int64) time.Time {
+	return time.UnixMilli((id >> 23) + ourEpoch)
+}
+
+// HistoryQuery selects a page of messages relative to a message id. At most
+// one of Before, After and Around is set; with none the latest page is
+// returned. With ThreadID set the page comes from that thread instead of the
+// main timeline.
+type HistoryQuery struct {
+	ThreadID int64
+	Before   int64
+	After    int64
+	Around   int64
+	Limit    int
+}
+
+// HistoryCursors hold the ids to pass as before or after to fetch the
+// neighbouring pages. They are empty when there is nothing more that way.
+type HistoryCursors struct {
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+func ValidateHistoryQuery(v *validator.Validator, q HistoryQuery) {
+	set := 0
+	for _, id := range []int64{q.Before, q.After, q.Around} {
+		if id != 0 {
+			set++
+		}
+	}
+
+	v.Check(set <= 1, "before", "only one of before, after, around and at can be provided")
+	v.Check(q.Limit > 0, "limit", "must be greater than zero")
+	v.Check(q.Limit <= 100, "limit", "must be a maximum of 100")
+}
+
+type MessageModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+	Conn  Conn
+}
+
+func ValidateMessageBody(v *validator.Validator, body string) {
+	v.Check(body != "", "body", "must be provided")
+	v.Check(utf8.RuneCountInString(body) <= MaxMessageLength, "body", "must not be more than 4000 characters")
+}
+
+func (m MessageModel) Insert(message *model.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.InsertMessageParams{
+		ConversationID: message.ConversationID,
+		SenderPid:      message.SenderPid,
+		Kind:           message.Kind,
+		Body:           message.Body,
+		ParentID:       message.ParentID,
+		ThreadID:       message.ThreadID,
+		Quote:          message.Quote,
+		Entities:       message.Entities,
+		Forward:        message.Forward,
+	}
+
+	row, err := m.DB.InsertMessage(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	message.MessageID = row.MessageID
+	message.CreatedAt = row.CreatedAt
+	message.ExpiresAt = row.ExpiresAt
+	message.Version = 1
+
+	return nil
+}
+
+func (m MessageModel) Get(conversationID, messageID int64) (*model.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.GetMessageParams{
+		MessageID:      messageID,
+		ConversationID: conversationID,
+	}
+
+	message, err := m.DB.GetMessage(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &model.Message{Message: message}, nil
+}
+
+// Edit replaces the body of a message, keeping the previous body in
+// message_edits. It fails with ErrEditConflict if the message changed since
+// it was read.
+func (m MessageModel) Edit(message *model.Message, editorID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.EditMessageParams{
+		MessageID:      message.MessageID,
+		ConversationID: message.ConversationID,
+		Version:        message.Version,
+		EditorPid:      editorID,
+		Body:           message.Body,
+		Entities:       message.Entities,
+	}
+
+	row, err := m.DB.EditMessage(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	message.EditedAt = row.EditedAt
+	message.Version = row.Version
+
+	return nil
+}
+
+// DeleteForEveryone turns a message into a tombstone that records who
+// deleted it. Earlier versions are purged along with the body. It reports
+// whether the message was pinned, since deleting it unpins it too.
+func (m MessageModel) DeleteForEveryone(message *model.Message, deleterID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.DeleteMessageForEveryoneParams{
+		MessageID:      message.MessageID,
+		DeleterPid:     pgtype.Int8{Int64: deleterID, Valid: true},
+		ConversationID: message.ConversationID,
+		Version:        message.Version,
+	}
+
+	row, err := m.DB.DeleteMessageForEveryone(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return false, ErrEditConflict
+		default:
+			return false, err
+		}
+	}
+
+	message.Body = ""
+	message.DeletedAt = row.DeletedAt
+	message.DeleterPid = args.DeleterPid
+	message.Version = row.Version
+
+	return row.Unpinned, nil
+}
+
+// Hide removes a message from the history of a single user.
+func (m MessageModel) Hide(userID, messageID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.HideMessageParams{
+		UserPid:   userID,
+		MessageID: messageID,
+	}
+
+	return m.DB.HideMessage(ctx, args)
+}
+
+func (m MessageModel) GetEdits(messageID int64) ([]*model.MessageEdit, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.ListMessageEdits(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	edits := make([]*model.MessageEdit, 0, len(rows))
+	for _, row := range rows {
+		edits = append(edits, &model.MessageEdit{MessageEdit: row})
+	}
+
+	return edits, nil
+}
+
+func (m MessageModel) attachReactions(ctx context.Context, messages []*model.Message, viewerID int64) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Message, len(messages))
+	messageIDs := make([]int64, 0, len(messages))
+	for _, message := range messages {
+		message.Reactions = []*model.ReactionSummary{}
+		byID[message.MessageID] = message
+		messageIDs = append(messageIDs, message.MessageID)
+	}
+
+	args := db.ListReactionSummariesParams{
+		ViewerPid:  viewerID,
+		MessageIds: messageIDs,
+	}
+
+	rows, err := m.DB.ListReactionSummaries(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		message := byID[row.MessageID]
+		message.Reactions = append(message.Reactions, &model.ReactionSummary{
+			Emoji:   row.Emoji,
+			Count:   row.Count,
+			Reacted: row.Reacted,
+		})
+	}
+
+	return nil
+}
+
+func (m MessageModel) page(ctx context.Context, conversationID, viewerID, threadID, boundaryID int64, descending bool, limit int) ([]*model.Message, bool, error) {
+	var (
+		rows []db.Message
+		err  error
+	)
+
+	// Each direction has its own query so that both can walk the index
+	// instead of sorting the whole conversation.
+	if descending {
+		rows, err = m.DB.ListMessagesBefore(ctx, db.ListMessagesBeforeParams{
+			ConversationID: conversationID,
+			ViewerPid:      viewerID,
+			ThreadID:       threadID,
+			BoundaryID:     boundaryID,
+			PageLimit:      int32(limit + 1),
+		})
+	} else {
+		rows, err = m.DB.ListMessagesAfter(ctx, db.ListMessagesAfterParams{
+			ConversationID: conversationID,
+			ViewerPid:      viewerID,
+			ThreadID:       threadID,
+			BoundaryID:     boundaryID,
+			PageLimit:      int32(limit + 1),
+		})
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+
+	messages := make([]*model.Message, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, &model.Message{Message: row})
+	}
+
+	if descending {
+		slices.Reverse(messages)
+	}
+
+	return messages, more, nil
+}
+
+// hasMessages reports whether the viewer can see any message on the given
+// side of boundaryID, boundaryID itself included.
+func (m MessageModel) hasMessages(ctx context.Context, conversationID, viewerID, threadID, boundaryID int64, descending bool) (bool, error) {
+	if descending {
+		boundaryID++
+	} else {
+		boundaryID--
+	}
+
+	_, more, err := m.page(ctx, conversationID, viewerID, threadID, boundaryID, descending, 0)
+	return more, err
+}
+
+// GetHistory returns a page of messages in chronological order along with
+// the cursors for the pages before and after it. Messages the viewer hid are
+// left out.
+func (m MessageModel) GetHistory(conversationID, viewerID int64, q HistoryQuery) ([]*model.Message, HistoryCursors, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var (
+		messages   []*model.Message
+		moreBefore bool
+		moreAfter  bool
+		err        error
+	)
+
+	switch {
+	case q.After != 0:
+		messages, moreAfter, err = m.page(ctx, conversationID, viewerID, q.ThreadID, q.After, false, q.Limit)
+		if err != nil {
+			return nil, HistoryCursors{}, err
+		}
+		moreBefore, err = m.hasMessages(ctx, conversationID, viewerID, q.ThreadID, q.After, true)
+	case q.Around != 0:
+		var newer []*model.Message
+		messages, moreBefore, err = m.page(ctx, conversationID, viewerID, q.ThreadID, q.Around+1, true, (q.Limit+1)/2)
+		if err != nil {
+			return nil, HistoryCursors{}, err
+		}
+		newer, moreAfter, err = m.page(ctx, conversationID, viewerID, q.ThreadID, q.Around, false, q.Limit/2)
+		messages = append(messages, newer...)
+	case q.Before != 0:
+		messages, moreBefore, err = m.page(ctx, conversationID, viewerID, q.ThreadID, q.Before, true, q.Limit)
+		if err != nil {
+			return nil, HistoryCursors{}, err
+		}
+		moreAfter, err = m.hasMessages(ctx, conversationID, viewerID, q.ThreadID, q.Before, false)
+	default:
+		messages, moreBefore, err = m.page(ctx, conversationID, viewerID, q.ThreadID, math.MaxInt64, true, q.Limit)
+	}
+	if err != nil {
+		return nil, HistoryCursors{}, err
+	}
+
+	var cursors HistoryCursors
+
+	if len(messages) == 0 {
+		return messages, cursors, nil
+	}
+
+	if err := m.attachReactions(ctx, messages, viewerID); err != nil {
+		return nil, HistoryCursors{}, err
+	}
+
+	if err := m.attachAttachments(ctx, messages); err != nil {
+		return nil, HistoryCursors{}, err
+	}
+
+	if err := m.attachLinkPreviews(ctx, messages); err != nil {
+		return nil, HistoryCursors{}, err
+	}
+
+	if err := m.attachPolls(ctx, messages, viewerID); err != nil {
+		return nil, HistoryCursors{}, err
+	}
+
+	if moreBefore {
+		cursors.Before = strconv.FormatInt(messages[0].MessageID, 10)
+	}
+	if moreAfter {
+		cursors.After = strconv.FormatInt(messages[len(messages)-1].MessageID, 10)
+	}
+
+	return messages, cursors, nil
+}
+
+// ExpiredMessages are the messages of one conversation removed by
+// DeleteExpired, and which of them were pinned.
+type ExpiredMessages struct {
+	MessageIDs  []int64
+	UnpinnedIDs []int64
+}
+
+// DeleteExpired removes up to limit messages whose disappearing timer ran
+// out, along with their edits, reactions and attachments. A thread root
+// takes its replies with it, a surviving root has its reply count, last
+// reply and participants recounted, and messages quoting a removed one lose
+// the quote. Blobs no longer used by any attachment are left to the attachment
+// garbage collector. It returns the removed messages by conversation and
+// how many there were.
+func (m MessageModel) DeleteExpired(limit int) (map[int64]*ExpiredMessages, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.DeleteExpiredMessages(ctx, int32(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	expired := make(map[int64]*ExpiredMessages)
+	for _, row := range rows {
+		messages, ok := expired[row.ConversationID]
+		if !ok {
+			messages = &ExpiredMessages{}
+			expired[row.ConversationID] = messages
+		}
+
+		messages.MessageIDs = append(messages.MessageIDs, row.MessageID)
+		if row.Unpinned {
+			messages.UnpinnedIDs = append(messages.UnpinnedIDs, row.MessageID)
+		}
+	}
+
+	return expired, len(rows), nil
+}
@@ -0,0 +1,89 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+func TestValidateMessageBody(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		valid bool
+	}{
+		{"plain", "hello", true},
+		{"empty", "", false},
+		{"at the limit", strings.Repeat("a", MaxMessageLength), true},
+		{"over the limit", strings.Repeat("a", MaxMessageLength+1), false},
+		{"multibyte at the limit", strings.Repeat("é", MaxMessageLength), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateMessageBody(v, tt.body)
+
+			if v.Valid() != tt.valid {
+				t.Fatalf("got valid %v, want %v: %v", v.Valid(), tt.valid, v.Errors)
+			}
+		})
+	}
+}
+
+func TestIDFromTime(t *testing.T) {
+	at := time.Date(2026, 3, 14, 15, 9, 26, 535_000_000, time.UTC)
+
+	id := IDFromTime(at)
+	if got := TimeFromID(id); !got.Equal(at) {
+		t.Fatalf("TimeFromID(IDFromTime(%v)) = %v", at, got)
+	}
+
+	// Ids made within the same millisecond sort between the two boundaries.
+	sameMillisecond := id + 1<<22
+	if !TimeFromID(sameMillisecond).Equal(at) {
+		t.Fatal("sequence bits changed the time")
+	}
+	if next := IDFromTime(at.Add(time.Millisecond)); next <= sameMillisecond {
+		t.Fatalf("next millisecond starts at %d, before %d", next, sameMillisecond)
+	}
+
+	if got := IDFromTime(time.UnixMilli(0)); got != 0 {
+		t.Fatalf("got %d for a time before the epoch, want 0", got)
+	}
+}
+
+func TestValidateHistoryQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  HistoryQuery
+		errKey string
+	}{
+		{"latest", HistoryQuery{Limit: 50}, ""},
+		{"before", HistoryQuery{Before: 10, Limit: 50}, ""},
+		{"around in a thread", HistoryQuery{ThreadID: 3, Around: 10, Limit: 100}, ""},
+		{"before and after", HistoryQuery{Before: 10, After: 5, Limit: 50}, "before"},
+		{"zero limit", HistoryQuery{Limit: 0}, "limit"},
+		{"limit too large", HistoryQuery{Limit: 101}, "limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateHistoryQuery(v, tt.query)
+
+			if tt.errKey == "" {
+				if !v.Valid() {
+					t.Fatalf("unexpected errors: %v", v.Errors)
+				}
+				return
+			}
+
+			if _, ok := v.Errors[tt.errKey]; !ok {
+				t.Fatalf("expected an error for %q, got %v", tt.errKey, v.Errors)
+			}
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package data
 
 import (
+	"context"
 	"errors"
 	"os"
+	"time"
 
+	"github.com/RickinShah/BuzzChat/internal/blob"
 	"github.com/RickinShah/BuzzChat/internal/db"
 	"github.com/RickinShah/BuzzChat/internal/jsonlog"
+	"github.com/jackc/pgx/v5"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -16,16 +20,100 @@ var (
 	ErrEditConflict   = errors.New("edit conflict")
 )
 
+// Conn is a database connection that can start transactions. Connection
+// pools and transactions both implement it; beginning a transaction inside
+// another one creates a savepoint.
+type Conn interface {
+	db.DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// withTx runs fn with queries bound to a new transaction, which is committed
+// if fn returns nil and rolled back otherwise.
+func withTx(ctx context.Context, conn Conn, fn func(queries *db.Queries) error) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(db.New(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
 type Models struct {
-	Users  *UserModel
-	Tokens *TokenModel
-	OTPs   *OTPModel
+	conn  Conn
+	redis *redis.Client
+	store blob.Store
+
+	Users         *UserModel
+	Tokens        *TokenModel
+	OTPs          *OTPModel
+	Contacts      *ContactModel
+	Notifications *NotificationModel
+	Privacy       *PrivacyModel
+	Conversations *ConversationModel
+	Messages      *MessageModel
+	Reactions     *ReactionModel
+	Threads       *ThreadModel
+	Receipts      *ReceiptModel
+	Typing        *TypingModel
+	Presence      *PresenceModel
+	Unread        *UnreadModel
+	Attachments   *AttachmentModel
+	LinkPreviews  *LinkPreviewModel
+	Scheduled     *ScheduledMessageModel
+	Polls         *PollModel
 }
 
-func NewModels(db *db.Queries, redis *redis.Client) Models {
+func NewModels(conn Conn, redis *redis.Client, store blob.Store) Models {
+	db := db.New(conn)
+
 	return Models{
-		Users:  &UserModel{db, redis},
-		Tokens: &TokenModel{db, redis},
-		OTPs:   &OTPModel{db, redis},
+		conn:  conn,
+		redis: redis,
+		store: store,
+
+		Users:         &UserModel{db, redis},
+		Tokens:        &TokenModel{db, redis},
+		OTPs:          &OTPModel{db, redis},
+		Contacts:      &ContactModel{db, redis},
+		Notifications: &NotificationModel{db, redis},
+		Privacy:       &PrivacyModel{db, redis},
+		Conversations: &ConversationModel{db, redis, conn},
+		Messages:      &MessageModel{db, redis, conn},
+		Reactions:     &ReactionModel{db, redis, conn},
+		Threads:       &ThreadModel{db, redis},
+		Receipts:      &ReceiptModel{db, redis},
+		Typing:        &TypingModel{db, redis},
+		Presence:      &PresenceModel{db, redis},
+		Unread:        &UnreadModel{db, redis},
+		Attachments:   &AttachmentModel{db, redis, store, conn},
+		LinkPreviews:  &LinkPreviewModel{db, redis},
+		Scheduled:     &ScheduledMessageModel{db, redis},
+		Polls:         &PollModel{db, redis, conn},
 	}
 }
+
+// Transaction runs fn with models that share one transaction. It is committed
+// if fn returns nil and rolled back otherwise, so anything fn publishes or
+// caches should wait until Transaction returns.
+func (m Models) Transaction(fn func(tx Models) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := m.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(NewModels(tx, m.redis, m.store)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
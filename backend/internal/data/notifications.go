@@ -0,0 +1,107 @@
+package data
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+type NotificationModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+}
+
+func (m NotificationModel) Insert(notification *model.Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.InsertNotificationParams{
+		UserPid:  notification.UserPid,
+		ActorPid: notification.ActorPid,
+		Kind:     notification.Kind,
+		Payload:  notification.Payload,
+	}
+
+	row, err := m.DB.InsertNotification(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	notification.NotificationID = row.NotificationID
+	notification.ReadAt = row.ReadAt
+	notification.CreatedAt = row.CreatedAt
+
+	return nil
+}
+
+func (m NotificationModel) GetAll(userID int64, unreadOnly bool, filters Filters) ([]*model.Notification, Metadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.ListNotificationsParams{
+		UserPid:    userID,
+		UnreadOnly: unreadOnly,
+		Descending: filters.keysetDescending(),
+		PageLimit:  filters.keysetLimit(),
+	}
+
+	if c := filters.keyset(); c != nil {
+		args.HasCursor = true
+		args.CursorID = c.ID
+	}
+
+	rows, err := m.DB.ListNotifications(ctx, args)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	notifications := make([]*model.Notification, 0, len(rows))
+	for _, row := range rows {
+		notifications = append(notifications, &model.Notification{Notification: row})
+	}
+
+	notifications, metadata := calculateKeysetMetadata(notifications, filters, func(n *model.Notification) (string, int64) {
+		return strconv.FormatInt(n.NotificationID, 10), n.NotificationID
+	})
+
+	return notifications, metadata, nil
+}
+
+func (m NotificationModel) CountUnread(userID int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.CountUnreadNotifications(ctx, userID)
+}
+
+func (m NotificationModel) MarkRead(userID, notificationID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.MarkNotificationReadParams{
+		NotificationID: notificationID,
+		UserPid:        userID,
+	}
+
+	rows, err := m.DB.MarkNotificationRead(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m NotificationModel) MarkAllRead(userID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.MarkAllNotificationsRead(ctx, userID)
+}
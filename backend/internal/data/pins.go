@@ -0,0 +1,156 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+)
+
+// MaxPinnedMessages caps how many messages can be pinned in a conversation.
+const MaxPinnedMessages = 50
+
+var ErrTooManyPins = errors.New("too many pinned messages")
+
+// Pin pins a message to its conversation. It reports false if the message
+// was already pinned. The conversation is locked while the pins are
+// counted, so two pins at once can't both take the last slot.
+func (m MessageModel) Pin(message *model.Message, pinnerID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.InsertPinParams{
+		MessageID:      message.MessageID,
+		ConversationID: message.ConversationID,
+		PinnerPid:      pinnerID,
+		MaxPins:        MaxPinnedMessages,
+	}
+
+	var pinned bool
+
+	err := withTx(ctx, m.Conn, func(queries *db.Queries) error {
+		if err := queries.LockConversation(ctx, message.ConversationID); err != nil {
+			return err
+		}
+
+		rows, err := queries.InsertPin(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		if rows > 0 {
+			pinned = true
+			return nil
+		}
+
+		exists, err := queries.PinExists(ctx, message.MessageID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return ErrTooManyPins
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return pinned, nil
+}
+
+func (m MessageModel) Unpin(message *model.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.DeletePinParams{
+		MessageID:      message.MessageID,
+		ConversationID: message.ConversationID,
+	}
+
+	rows, err := m.DB.DeletePin(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetPins returns the pinned messages of a conversation, most recently
+// pinned first. Messages the viewer hid are left out.
+func (m MessageModel) GetPins(conversationID, viewerID int64) ([]*model.Pin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.ListPinsParams{
+		ConversationID: conversationID,
+		ViewerPid:      viewerID,
+	}
+
+	rows, err := m.DB.ListPins(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	pins := make([]*model.Pin, 0, len(rows))
+	if len(rows) == 0 {
+		return pins, nil
+	}
+
+	messageIDs := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		messageIDs = append(messageIDs, row.MessageID)
+	}
+
+	messageRows, err := m.DB.ListMessagesByID(ctx, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*model.Message, len(messageRows))
+	messages := make([]*model.Message, 0, len(messageRows))
+	for _, row := range messageRows {
+		message := &model.Message{Message: row}
+		byID[row.MessageID] = message
+		messages = append(messages, message)
+	}
+
+	if err := m.attachReactions(ctx, messages, viewerID); err != nil {
+		return nil, err
+	}
+
+	if err := m.attachAttachments(ctx, messages); err != nil {
+		return nil, err
+	}
+
+	if err := m.attachLinkPreviews(ctx, messages); err != nil {
+		return nil, err
+	}
+
+	if err := m.attachPolls(ctx, messages, viewerID); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		message, ok := byID[row.MessageID]
+		if !ok {
+			continue
+		}
+
+		pins = append(pins, &model.Pin{
+			Message:  message,
+			PinnerID: row.PinnerPid,
+			PinnedAt: row.PinnedAt,
+		})
+	}
+
+	return pins, nil
+}
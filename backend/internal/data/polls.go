@@ -0,0 +1,346 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	MinPollOptions = 2
+	MaxPollOptions = 10
+)
+
+var ErrPollClosed = errors.New("poll is closed")
+
+type PollModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+	Conn  Conn
+}
+
+func ValidatePoll(v *validator.Validator, poll *model.Poll) {
+	v.Check(strings.TrimSpace(poll.Question) != "", "question", "must be provided")
+	v.Check(utf8.RuneCountInString(poll.Question) <= 300, "question", "must not be more than 300 characters")
+
+	v.Check(len(poll.Options) >= MinPollOptions, "options", "must contain at least 2 options")
+	v.Check(len(poll.Options) <= MaxPollOptions, "options", "must not contain more than 10 options")
+	v.Check(validator.Unique(poll.Options), "options", "must not contain duplicate values")
+	for _, option := range poll.Options {
+		v.Check(strings.TrimSpace(option) != "", "options", "must not contain empty options")
+		v.Check(utf8.RuneCountInString(option) <= 100, "options", "must not contain options of more than 100 characters")
+	}
+
+	if poll.ClosesAt.Valid {
+		v.Check(poll.ClosesAt.Time.After(time.Now()), "closesAt", "must be in the future")
+	}
+}
+
+// ValidateVote checks the options picked in a poll. An empty vote retracts
+// the voter's choices.
+func ValidateVote(v *validator.Validator, poll *model.Poll, options []int32) {
+	if !poll.MultipleChoice {
+		v.Check(len(options) <= 1, "options", "must contain a single option")
+	}
+
+	seen := make(map[int32]bool, len(options))
+	for _, option := range options {
+		v.Check(option >= 0 && int(option) < len(poll.Options), "options", "must contain valid option ids")
+		v.Check(!seen[option], "options", "must not contain duplicate values")
+		seen[option] = true
+	}
+}
+
+func (m PollModel) Insert(poll *model.Poll) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.InsertPollParams{
+		MessageID:      poll.MessageID,
+		ConversationID: poll.ConversationID,
+		Question:       poll.Question,
+		Options:        poll.Options,
+		MultipleChoice: poll.MultipleChoice,
+		Anonymous:      poll.Anonymous,
+		ClosesAt:       poll.ClosesAt,
+	}
+
+	if err := m.DB.InsertPoll(ctx, args); err != nil {
+		return err
+	}
+
+	poll.Votes = make([]int64, len(poll.Options))
+
+	return nil
+}
+
+// Get returns a poll with its tallies. A non-zero viewerID also loads the
+// viewer's own votes.
+func (m PollModel) Get(messageID, viewerID int64) (*model.Poll, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	row, err := m.DB.GetPoll(ctx, messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	poll := &model.Poll{Poll: row}
+
+	if err := tallyPolls(ctx, m.DB, []*model.Poll{poll}, viewerID); err != nil {
+		return nil, err
+	}
+
+	return poll, nil
+}
+
+// GetVoters loads who voted for each option. Anonymous polls have no voter
+// lists.
+func (m PollModel) GetVoters(poll *model.Poll) error {
+	if poll.Anonymous {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.ListPollVoters(ctx, poll.MessageID)
+	if err != nil {
+		return err
+	}
+
+	poll.VoterIDs = make([][]int64, len(poll.Options))
+	for i := range poll.VoterIDs {
+		poll.VoterIDs[i] = []int64{}
+	}
+
+	for _, row := range rows {
+		if int(row.OptionID) < len(poll.VoterIDs) {
+			poll.VoterIDs[row.OptionID] = append(poll.VoterIDs[row.OptionID], row.UserPid)
+		}
+	}
+
+	return nil
+}
+
+// Vote replaces userID's choices in a poll with options. Voting the same
+// way twice changes nothing, and an empty vote retracts. It reports whether
+// anything changed, and fails with ErrPollClosed once the poll is closed.
+//
+// The poll is locked before the old choices are read, so two votes by the
+// same user at once can't both land in a single choice poll.
+func (m PollModel) Vote(poll *model.Poll, userID int64, options []int32) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var changed int64
+
+	err := withTx(ctx, m.Conn, func(queries *db.Queries) error {
+		if _, err := queries.LockOpenPoll(ctx, poll.MessageID); err != nil {
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+				return ErrPollClosed
+			default:
+				return err
+			}
+		}
+
+		retractArgs := db.RetractPollVotesParams{
+			MessageID: poll.MessageID,
+			UserPid:   userID,
+			OptionIds: options,
+		}
+
+		retracted, err := queries.RetractPollVotes(ctx, retractArgs)
+		if err != nil {
+			return err
+		}
+
+		castArgs := db.CastPollVotesParams{
+			MessageID: poll.MessageID,
+			UserPid:   userID,
+			OptionIds: options,
+		}
+
+		cast, err := queries.CastPollVotes(ctx, castArgs)
+		if err != nil {
+			return err
+		}
+
+		changed = retracted + cast
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if changed == 0 {
+		return false, nil
+	}
+
+	return true, tallyPolls(ctx, m.DB, []*model.Poll{poll}, 0)
+}
+
+// Close ends voting in a poll. It fails with ErrPollClosed if the poll was
+// already closed.
+func (m PollModel) Close(poll *model.Poll) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	closedAt, err := m.DB.ClosePoll(ctx, poll.MessageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrPollClosed
+		default:
+			return err
+		}
+	}
+
+	poll.ClosedAt = closedAt
+
+	return nil
+}
+
+// CloseDue closes up to limit polls whose close time has passed and returns
+// them with their final tallies. Polls another instance is closing are
+// skipped.
+func (m PollModel) CloseDue(limit int) ([]*model.Poll, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.CloseDuePolls(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	messageIDs := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		messageIDs = append(messageIDs, row.MessageID)
+	}
+
+	pollRows, err := m.DB.ListPolls(ctx, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	polls := make([]*model.Poll, 0, len(pollRows))
+	for _, row := range pollRows {
+		polls = append(polls, &model.Poll{Poll: row})
+	}
+
+	if err := tallyPolls(ctx, m.DB, polls, 0); err != nil {
+		return nil, err
+	}
+
+	return polls, nil
+}
+
+// tallyPolls counts the votes of a set of polls. A non-zero viewerID also
+// loads the viewer's own votes.
+func tallyPolls(ctx context.Context, queries *db.Queries, polls []*model.Poll, viewerID int64) error {
+	if len(polls) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Poll, len(polls))
+	messageIDs := make([]int64, 0, len(polls))
+	for _, poll := range polls {
+		poll.Votes = make([]int64, len(poll.Options))
+		poll.Voters = 0
+		byID[poll.MessageID] = poll
+		messageIDs = append(messageIDs, poll.MessageID)
+	}
+
+	tallies, err := queries.ListPollTallies(ctx, messageIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range tallies {
+		poll := byID[row.MessageID]
+		if int(row.OptionID) < len(poll.Votes) {
+			poll.Votes[row.OptionID] = row.Votes
+		}
+	}
+
+	counts, err := queries.ListPollVoterCounts(ctx, messageIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range counts {
+		byID[row.MessageID].Voters = row.Voters
+	}
+
+	if viewerID == 0 {
+		return nil
+	}
+
+	for _, poll := range polls {
+		poll.Voted = []int32{}
+	}
+
+	args := db.ListPollVotesByUserParams{
+		MessageIds: messageIDs,
+		UserPid:    viewerID,
+	}
+
+	votes, err := queries.ListPollVotesByUser(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range votes {
+		poll := byID[row.MessageID]
+		poll.Voted = append(poll.Voted, row.OptionID)
+	}
+
+	return nil
+}
+
+func (m MessageModel) attachPolls(ctx context.Context, messages []*model.Message, viewerID int64) error {
+	byID := make(map[int64]*model.Message)
+	var messageIDs []int64
+	for _, message := range messages {
+		if message.Kind == model.MessagePoll && !message.IsDeleted() {
+			byID[message.MessageID] = message
+			messageIDs = append(messageIDs, message.MessageID)
+		}
+	}
+
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	rows, err := m.DB.ListPolls(ctx, messageIDs)
+	if err != nil {
+		return err
+	}
+
+	polls := make([]*model.Poll, 0, len(rows))
+	for _, row := range rows {
+		poll := &model.Poll{Poll: row}
+		polls = append(polls, poll)
+		byID[row.MessageID].Poll = poll
+	}
+
+	return tallyPolls(ctx, m.DB, polls, viewerID)
+}
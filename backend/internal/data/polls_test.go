@@ -0,0 +1,94 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestValidatePoll(t *testing.T) {
+	options := func(n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = "option " + string(rune('a'+i))
+		}
+		return out
+	}
+
+	at := func(d time.Duration) pgtype.Timestamptz {
+		return pgtype.Timestamptz{Time: time.Now().Add(d), Valid: true}
+	}
+
+	tests := []struct {
+		name     string
+		question string
+		options  []string
+		closesAt pgtype.Timestamptz
+		valid    bool
+	}{
+		{"valid", "Lunch?", []string{"Pizza", "Sushi"}, pgtype.Timestamptz{}, true},
+		{"most options", "Lunch?", options(MaxPollOptions), pgtype.Timestamptz{}, true},
+		{"closes later", "Lunch?", options(2), at(time.Hour), true},
+		{"longest question", strings.Repeat("é", 300), options(2), pgtype.Timestamptz{}, true},
+		{"longest option", "Lunch?", []string{strings.Repeat("é", 100), "b"}, pgtype.Timestamptz{}, true},
+		{"no question", "", options(2), pgtype.Timestamptz{}, false},
+		{"blank question", "   ", options(2), pgtype.Timestamptz{}, false},
+		{"question too long", strings.Repeat("a", 301), options(2), pgtype.Timestamptz{}, false},
+		{"one option", "Lunch?", options(1), pgtype.Timestamptz{}, false},
+		{"too many options", "Lunch?", options(MaxPollOptions + 1), pgtype.Timestamptz{}, false},
+		{"duplicate options", "Lunch?", []string{"Pizza", "Pizza"}, pgtype.Timestamptz{}, false},
+		{"blank option", "Lunch?", []string{"Pizza", " "}, pgtype.Timestamptz{}, false},
+		{"option too long", "Lunch?", []string{strings.Repeat("a", 101), "b"}, pgtype.Timestamptz{}, false},
+		{"closes in the past", "Lunch?", options(2), at(-time.Minute), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poll := &model.Poll{Poll: db.Poll{Question: tt.question, Options: tt.options, ClosesAt: tt.closesAt}}
+
+			v := validator.New()
+			ValidatePoll(v, poll)
+
+			if v.Valid() != tt.valid {
+				t.Fatalf("got valid %v, want %v: %v", v.Valid(), tt.valid, v.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateVote(t *testing.T) {
+	single := &model.Poll{Poll: db.Poll{Options: []string{"a", "b", "c"}}}
+	multiple := &model.Poll{Poll: db.Poll{Options: []string{"a", "b", "c"}, MultipleChoice: true}}
+
+	tests := []struct {
+		name    string
+		poll    *model.Poll
+		options []int32
+		valid   bool
+	}{
+		{"single choice", single, []int32{2}, true},
+		{"retract", single, []int32{}, true},
+		{"several in a multiple choice poll", multiple, []int32{0, 2}, true},
+		{"every option", multiple, []int32{0, 1, 2}, true},
+		{"several in a single choice poll", single, []int32{0, 1}, false},
+		{"negative option", single, []int32{-1}, false},
+		{"option out of range", multiple, []int32{3}, false},
+		{"duplicate option", multiple, []int32{1, 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateVote(v, tt.poll, tt.options)
+
+			if v.Valid() != tt.valid {
+				t.Fatalf("got valid %v, want %v: %v", v.Valid(), tt.valid, v.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package data
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/cache"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// PresenceTTL is how long a connection stays online without a heartbeat.
+	PresenceTTL = cache.PresenceTTL
+
+	MaxPresenceUsers = 100
+)
+
+func ValidatePresenceQuery(v *validator.Validator, userIDs []int64) {
+	v.Check(len(userIDs) > 0, "ids", "must contain at least one user id")
+	v.Check(len(userIDs) <= MaxPresenceUsers, "ids", "must not contain more than 100 user ids")
+}
+
+// PresenceModel tracks live connections in Redis and only writes to
+// Postgres when a user's last connection goes away.
+type PresenceModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+}
+
+// Connect records a new connection and reports whether the user just came
+// online.
+func (m PresenceModel) Connect(userID, connectionID int64) (bool, error) {
+	return cache.TouchPresence(m.Redis, userID, connectionID)
+}
+
+func (m PresenceModel) Heartbeat(userID, connectionID int64) error {
+	_, err := cache.TouchPresence(m.Redis, userID, connectionID)
+	return err
+}
+
+// Disconnect drops a connection. When it was the user's last one the user is
+// offline and their last-seen time is saved and returned.
+func (m PresenceModel) Disconnect(userID, connectionID int64) (*model.Presence, error) {
+	offline, err := cache.RemovePresence(m.Redis, userID, connectionID)
+	if err != nil || !offline {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	presence := &model.Presence{
+		UserID:   userID,
+		LastSeen: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+
+	args := db.UpdateLastSeenParams{
+		LastSeen: presence.LastSeen,
+		UserPid:  userID,
+	}
+
+	if err := m.DB.UpdateLastSeen(ctx, args); err != nil {
+		return nil, err
+	}
+
+	return presence, nil
+}
+
+// GetOnlineIDs returns which of userIDs are connected right now. It ignores
+// privacy settings, so the result must not be shown to other users.
+func (m PresenceModel) GetOnlineIDs(userIDs []int64) ([]int64, error) {
+	online, err := cache.GetOnline(m.Redis, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	onlineIDs := make([]int64, 0, len(online))
+	for _, userID := range userIDs {
+		if online[userID] {
+			onlineIDs = append(onlineIDs, userID)
+		}
+	}
+
+	return onlineIDs, nil
+}
+
+// GetAll returns the presence of the given users as viewerID may see it.
+// Unknown users are left out.
+func (m PresenceModel) GetAll(viewerID int64, userIDs []int64) ([]*model.Presence, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.ListLastSeen(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	contactIDs, err := m.DB.ListContactIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	blockedIDs, err := m.DB.ListBlockedIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	online, err := cache.GetOnline(m.Redis, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	presences := make([]*model.Presence, 0, len(rows))
+	for _, row := range rows {
+		rel := model.RelationNone
+		switch {
+		case row.UserPid == viewerID:
+			rel = model.RelationSelf
+		case slices.Contains(contactIDs, row.UserPid):
+			rel = model.RelationContact
+		}
+
+		privacy := &model.PrivacySettings{PrivacySetting: db.PrivacySetting{LastSeenVisibility: row.LastSeenVisibility}}
+
+		// A block in either direction hides presence both ways.
+		blocked := rel != model.RelationSelf && slices.Contains(blockedIDs, row.UserPid)
+
+		presences = append(presences, &model.Presence{
+			UserID:   row.UserPid,
+			Online:   online[row.UserPid],
+			LastSeen: row.LastSeen,
+			Hidden:   blocked || !privacy.Allows(privacy.LastSeenVisibility, rel),
+		})
+	}
+
+	return presences, nil
+}
@@ -0,0 +1,39 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+func TestValidatePresenceQuery(t *testing.T) {
+	ids := func(n int) []int64 {
+		userIDs := make([]int64, n)
+		for i := range userIDs {
+			userIDs[i] = int64(i + 1)
+		}
+		return userIDs
+	}
+
+	tests := []struct {
+		name    string
+		userIDs []int64
+		valid   bool
+	}{
+		{"one", ids(1), true},
+		{"at the limit", ids(MaxPresenceUsers), true},
+		{"none", nil, false},
+		{"over the limit", ids(MaxPresenceUsers + 1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidatePresenceQuery(v, tt.userIDs)
+
+			if v.Valid() != tt.valid {
+				t.Fatalf("got valid %v, want %v: %v", v.Valid(), tt.valid, v.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/cache"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+func ValidateVisibility(v *validator.Validator, key, visibility string) {
+	v.Check(validator.In(visibility, model.VisibilityEveryone, model.VisibilityContacts, model.VisibilityNobody), key, "must be everyone, contacts or nobody")
+}
+
+func ValidatePrivacySettings(v *validator.Validator, settings *model.PrivacySettings) {
+	ValidateVisibility(v, "bioVisibility", settings.BioVisibility)
+	ValidateVisibility(v, "lastSeenVisibility", settings.LastSeenVisibility)
+	ValidateVisibility(v, "profilePicVisibility", settings.ProfilePicVisibility)
+	ValidateVisibility(v, "emailVisibility", settings.EmailVisibility)
+	ValidateVisibility(v, "whoCanMessage", settings.WhoCanMessage)
+}
+
+type PrivacyModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+}
+
+// Get returns the stored settings for a user, falling back to the defaults
+// for users who never changed them.
+func (m PrivacyModel) Get(userID int64) (*model.PrivacySettings, error) {
+	cachedSettings, isCached, _ := cache.GetCachedPrivacySettings(m.Redis, userID)
+	if isCached {
+		return cachedSettings, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var settings *model.PrivacySettings
+
+	row, err := m.DB.GetPrivacySettings(ctx, userID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		settings = model.DefaultPrivacySettings(userID)
+	case err != nil:
+		return nil, err
+	default:
+		settings = &model.PrivacySettings{PrivacySetting: row}
+	}
+
+	if err := cache.SetCachedPrivacySettings(m.Redis, settings); err != nil {
+		logger.PrintInfo("failed to cache privacy settings:"+err.Error(), nil)
+	}
+
+	return settings, nil
+}
+
+func (m PrivacyModel) Update(settings *model.PrivacySettings) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.UpsertPrivacySettingsParams{
+		UserPid:              settings.UserPid,
+		BioVisibility:        settings.BioVisibility,
+		LastSeenVisibility:   settings.LastSeenVisibility,
+		ProfilePicVisibility: settings.ProfilePicVisibility,
+		EmailVisibility:      settings.EmailVisibility,
+		WhoCanMessage:        settings.WhoCanMessage,
+		Searchable:           settings.Searchable,
+		ReadReceipts:         settings.ReadReceipts,
+		ForwardAttribution:   settings.ForwardAttribution,
+		Version:              settings.Version,
+	}
+
+	row, err := m.DB.UpsertPrivacySettings(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+	settings.UpdatedAt = row.UpdatedAt
+	settings.Version = row.Version
+
+	if err := cache.SetCachedPrivacySettings(m.Redis, settings); err != nil {
+		logger.PrintInfo("failed to cache privacy settings:"+err.Error(), nil)
+	}
+
+	return nil
+}
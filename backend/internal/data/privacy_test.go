@@ -0,0 +1,45 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+func TestValidatePrivacySettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*model.PrivacySettings)
+		errKey string
+	}{
+		{"defaults", func(*model.PrivacySettings) {}, ""},
+		{"nobody", func(s *model.PrivacySettings) { s.BioVisibility = model.VisibilityNobody }, ""},
+		{"bio", func(s *model.PrivacySettings) { s.BioVisibility = "friends" }, "bioVisibility"},
+		{"last seen", func(s *model.PrivacySettings) { s.LastSeenVisibility = "" }, "lastSeenVisibility"},
+		{"profile pic", func(s *model.PrivacySettings) { s.ProfilePicVisibility = "all" }, "profilePicVisibility"},
+		{"email", func(s *model.PrivacySettings) { s.EmailVisibility = "Everyone" }, "emailVisibility"},
+		{"who can message", func(s *model.PrivacySettings) { s.WhoCanMessage = "none" }, "whoCanMessage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := model.DefaultPrivacySettings(1)
+			tt.modify(settings)
+
+			v := validator.New()
+			ValidatePrivacySettings(v, settings)
+
+			if tt.errKey == "" {
+				if !v.Valid() {
+					t.Fatalf("unexpected errors: %v", v.Errors)
+				}
+				return
+			}
+
+			if _, ok := v.Errors[tt.errKey]; !ok || len(v.Errors) != 1 {
+				t.Fatalf("expected a single error for %q, got %v", tt.errKey, v.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/redis/go-redis/v9"
+)
+
+// MaxReactionEmoji caps how many distinct emoji a single message can collect.
+const MaxReactionEmoji = 20
+
+var ErrTooManyReactions = errors.New("too many distinct reactions")
+
+type ReactionModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+	Conn  Conn
+}
+
+// emojiTable holds the code points that render as emoji on their own.
+var emojiTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00a9, Hi: 0x00a9, Stride: 1},
+		{Lo: 0x00ae, Hi: 0x00ae, Stride: 1},
+		{Lo: 0x203c, Hi: 0x203c, Stride: 1},
+		{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+		{Lo: 0x2122, Hi: 0x2122, Stride: 1},
+		{Lo: 0x2139, Hi: 0x2139, Stride: 1},
+		{Lo: 0x2194, Hi: 0x2199, Stride: 1},
+		{Lo: 0x21a9, Hi: 0x21aa, Stride: 1},
+		{Lo: 0x231a, Hi: 0x231b, Stride: 1},
+		{Lo: 0x2328, Hi: 0x2328, Stride: 1},
+		{Lo: 0x23cf, Hi: 0x23cf, Stride: 1},
+		{Lo: 0x23e9, Hi: 0x23f3, Stride: 1},
+		{Lo: 0x23f8, Hi: 0x23fa, Stride: 1},
+		{Lo: 0x24c2, Hi: 0x24c2, Stride: 1},
+		{Lo: 0x25aa, Hi: 0x25ab, Stride: 1},
+		{Lo: 0x25b6, Hi: 0x25b6, Stride: 1},
+		{Lo: 0x25c0, Hi: 0x25c0, Stride: 1},
+		{Lo: 0x25fb, Hi: 0x25fe, Stride: 1},
+		{Lo: 0x2600, Hi: 0x27bf, Stride: 1},
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2b05, Hi: 0x2b07, Stride: 1},
+		{Lo: 0x2b1b, Hi: 0x2b1c, Stride: 1},
+		{Lo: 0x2b50, Hi: 0x2b50, Stride: 1},
+		{Lo: 0x2b55, Hi: 0x2b55, Stride: 1},
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+		{Lo: 0x303d, Hi: 0x303d, Stride: 1},
+		{Lo: 0x3297, Hi: 0x3297, Stride: 1},
+		{Lo: 0x3299, Hi: 0x3299, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1faff, Stride: 1},
+	},
+}
+
+// emojiComponentTable holds the code points that only modify or join emoji:
+// the zero width joiner, variation selectors, the keycap mark and tags.
+var emojiComponentTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x200d, Hi: 0x200d, Stride: 1},
+		{Lo: 0x20e3, Hi: 0x20e3, Stride: 1},
+		{Lo: 0xfe0e, Hi: 0xfe0f, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0xe0020, Hi: 0xe007f, Stride: 1},
+	},
+}
+
+// isEmoji reports whether s is made up of emoji, including sequences built
+// with joiners, modifiers, tags and keycaps.
+func isEmoji(s string) bool {
+	runes := []rune(s)
+	if len(runes) == 0 || unicode.Is(emojiComponentTable, runes[0]) {
+		return false
+	}
+
+	for i, r := range runes {
+		switch {
+		case unicode.Is(emojiTable, r), unicode.Is(emojiComponentTable, r):
+		case (r >= '0' && r <= '9') || r == '#' || r == '*':
+			if !isKeycap(runes[i+1:]) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isKeycap reports whether rest completes a keycap sequence after its base
+// character.
+func isKeycap(rest []rune) bool {
+	if len(rest) > 0 && rest[0] == 0xfe0f {
+		rest = rest[1:]
+	}
+	return len(rest) > 0 && rest[0] == 0x20e3
+}
+
+func ValidateEmoji(v *validator.Validator, emoji string) {
+	v.Check(emoji != "", "emoji", "must be provided")
+	v.Check(utf8.RuneCountInString(emoji) <= 16, "emoji", "must not be more than 16 characters")
+	v.Check(emoji == "" || isEmoji(emoji), "emoji", "must be an emoji")
+}
+
+// Add records a reaction. It reports false if the user had already reacted
+// with the same emoji. The message row is locked while the distinct emoji are
+// counted so that concurrent reactions cannot push a message over the cap.
+func (m ReactionModel) Add(messageID, userID int64, emoji string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.InsertReactionParams{
+		MessageID: messageID,
+		UserPid:   userID,
+		Emoji:     emoji,
+		MaxEmoji:  MaxReactionEmoji,
+	}
+
+	var added bool
+
+	err := withTx(ctx, m.Conn, func(queries *db.Queries) error {
+		if err := queries.LockMessage(ctx, messageID); err != nil {
+			return err
+		}
+
+		rows, err := queries.InsertReaction(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		if rows > 0 {
+			added = true
+			return nil
+		}
+
+		exists, err := queries.ReactionExists(ctx, db.ReactionExistsParams{
+			MessageID: messageID,
+			UserPid:   userID,
+			Emoji:     emoji,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return ErrTooManyReactions
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return added, nil
+}
+
+func (m ReactionModel) Remove(messageID, userID int64, emoji string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.DeleteReactionParams{
+		MessageID: messageID,
+		UserPid:   userID,
+		Emoji:     emoji,
+	}
+
+	rows, err := m.DB.DeleteReaction(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m ReactionModel) GetUsers(messageID int64, emoji string, viewerID int64, filters Filters) ([]*model.User, Metadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.ListReactionUsersParams{
+		ViewerPid:  viewerID,
+		MessageID:  messageID,
+		Emoji:      emoji,
+		Descending: filters.keysetDescending(),
+		PageLimit:  filters.keysetLimit(),
+	}
+
+	if c := filters.keyset(); c != nil {
+		args.HasCursor = true
+		args.CursorUsername = c.Value
+		args.CursorID = c.ID
+	}
+
+	rows, err := m.DB.ListReactionUsers(ctx, args)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	total, err := m.DB.CountReactionUsers(ctx, db.CountReactionUsersParams{
+		MessageID: messageID,
+		Emoji:     emoji,
+	})
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	users := make([]*model.User, 0, len(rows))
+	for _, row := range rows {
+		user := model.NewUser(&db.User{
+			UserPid:    row.UserPid,
+			Username:   row.Username,
+			Name:       row.Name,
+			ProfilePic: row.ProfilePic,
+		})
+		user.SetMarshalType(model.Minimal)
+		users = append(users, user)
+	}
+
+	users, metadata := calculateKeysetMetadata(users, filters, func(u *model.User) (string, int64) {
+		return u.Username, u.UserPid
+	})
+	metadata.TotalRecords = int(total)
+
+	return users, metadata, nil
+}
@@ -0,0 +1,46 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+func TestValidateEmoji(t *testing.T) {
+	tests := []struct {
+		name  string
+		emoji string
+		valid bool
+	}{
+		{"single", "👍", true},
+		{"with variation selector", "❤\ufe0f", true},
+		{"skin tone", "👍🏽", true},
+		{"zwj sequence", "👩\u200d💻", true},
+		{"flag", "🇮🇳", true},
+		{"keycap", "1\ufe0f\u20e3", true},
+		{"keycap without selector", "#\u20e3", true},
+		{"subdivision flag", "🏴\U000e0067\U000e0062\U000e0073\U000e0063\U000e0074\U000e007f", true},
+		{"copyright", "©", true},
+		{"empty", "", false},
+		{"ascii", "ok", false},
+		{"bare digit", "1", false},
+		{"letters", "é", false},
+		{"cjk", "日本", false},
+		{"emoji and text", "👍ok", false},
+		{"whitespace", "👍 👍", false},
+		{"lone joiner", "\u200d", false},
+		{"lone selector", "\ufe0f", false},
+		{"too long", "👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateEmoji(v, tt.emoji)
+
+			if v.Valid() != tt.valid {
+				t.Fatalf("got valid %v, want %v: %v", v.Valid(), tt.valid, v.Errors)
+			}
+		})
+	}
+}
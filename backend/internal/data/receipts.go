@@ -0,0 +1,137 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+)
+
+type ReceiptModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+}
+
+// MarkDelivered moves the delivery watermark of a participant up to
+// messageID. Watermarks never move back, so the boolean is false when the
+// participant had already received messageID. The current receipt is
+// returned either way.
+func (m ReceiptModel) MarkDelivered(conversationID, userID, messageID int64) (*model.Receipt, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.AdvanceDeliveredReceiptParams{
+		MessageID:      messageID,
+		ConversationID: conversationID,
+		UserPid:        userID,
+	}
+
+	row, err := m.DB.AdvanceDeliveredReceipt(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			receipt, err := m.get(ctx, conversationID, userID)
+			return receipt, false, err
+		}
+		return nil, false, err
+	}
+
+	return newReceipt(conversationID, userID, row.LastDeliveredID, row.LastReadID), true, nil
+}
+
+// MarkRead moves the read watermark of a participant up to messageID. A read
+// message is also delivered, so the delivery watermark follows along.
+func (m ReceiptModel) MarkRead(conversationID, userID, messageID int64) (*model.Receipt, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.AdvanceReadReceiptParams{
+		MessageID:      messageID,
+		ConversationID: conversationID,
+		UserPid:        userID,
+	}
+
+	row, err := m.DB.AdvanceReadReceipt(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			receipt, err := m.get(ctx, conversationID, userID)
+			return receipt, false, err
+		}
+		return nil, false, err
+	}
+
+	return newReceipt(conversationID, userID, row.LastDeliveredID, row.LastReadID), true, nil
+}
+
+func (m ReceiptModel) get(ctx context.Context, conversationID, userID int64) (*model.Receipt, error) {
+	args := db.GetReceiptParams{
+		ConversationID: conversationID,
+		UserPid:        userID,
+	}
+
+	row, err := m.DB.GetReceipt(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return newReceipt(conversationID, userID, row.LastDeliveredID, row.LastReadID), nil
+}
+
+func newReceipt(conversationID, userID, lastDeliveredID, lastReadID int64) *model.Receipt {
+	return &model.Receipt{
+		ConversationID:  conversationID,
+		UserID:          userID,
+		LastDeliveredID: lastDeliveredID,
+		LastReadID:      pgtype.Int8{Int64: lastReadID, Valid: true},
+	}
+}
+
+// GetForMessage splits the participants a message reached into those who
+// have seen it and those it was only delivered to. Participants who turned
+// read receipts off only ever show up as delivered.
+func (m ReceiptModel) GetForMessage(message *model.Message, viewerID int64) (*model.MessageReceipts, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.ListMessageReceiptsParams{
+		ViewerPid:      viewerID,
+		MessageID:      message.MessageID,
+		ConversationID: message.ConversationID,
+		SenderPid:      message.SenderPid.Int64,
+	}
+
+	rows, err := m.DB.ListMessageReceipts(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := &model.MessageReceipts{
+		SeenBy:      []*model.User{},
+		DeliveredTo: []*model.User{},
+	}
+
+	for _, row := range rows {
+		user := model.NewUser(&db.User{
+			UserPid:    row.UserPid,
+			Username:   row.Username,
+			Name:       row.Name,
+			ProfilePic: row.ProfilePic,
+		})
+		user.SetMarshalType(model.Minimal)
+
+		if row.Seen {
+			receipts.SeenBy = append(receipts.SeenBy, user)
+		} else {
+			receipts.DeliveredTo = append(receipts.DeliveredTo, user)
+		}
+	}
+
+	return receipts, nil
+}
@@ -0,0 +1,223 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/RickinShah/BuzzChat/internal/validator"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+)
+
+// MaxScheduleAhead is how far in the future a message can be scheduled.
+const MaxScheduleAhead = 365 * 24 * time.Hour
+
+type ScheduledMessageModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+}
+
+func ValidateSendAt(v *validator.Validator, sendAt time.Time) {
+	v.Check(!sendAt.IsZero(), "sendAt", "must be provided")
+	v.Check(sendAt.After(time.Now()), "sendAt", "must be in the future")
+	v.Check(sendAt.Before(time.Now().Add(MaxScheduleAhead)), "sendAt", "must be within a year")
+}
+
+func (m ScheduledMessageModel) Insert(message *model.ScheduledMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.InsertScheduledMessageParams{
+		ConversationID: message.ConversationID,
+		SenderPid:      message.SenderPid,
+		Body:           message.Body,
+		SendAt:         message.SendAt,
+	}
+
+	row, err := m.DB.InsertScheduledMessage(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	message.ScheduledID = row.ScheduledID
+	message.Status = row.Status
+	message.CreatedAt = row.CreatedAt
+	message.Version = row.Version
+
+	return nil
+}
+
+// Get returns a scheduled message of senderID's. Other users' scheduled
+// messages are reported as not found.
+func (m ScheduledMessageModel) Get(conversationID, scheduledID, senderID int64) (*model.ScheduledMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.GetScheduledMessageParams{
+		ScheduledID:    scheduledID,
+		ConversationID: conversationID,
+		SenderPid:      senderID,
+	}
+
+	row, err := m.DB.GetScheduledMessage(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &model.ScheduledMessage{ScheduledMessage: row}, nil
+}
+
+// GetAll returns senderID's scheduled messages in a conversation, the next
+// to be sent first.
+func (m ScheduledMessageModel) GetAll(conversationID, senderID int64) ([]*model.ScheduledMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.ListScheduledMessagesParams{
+		ConversationID: conversationID,
+		SenderPid:      senderID,
+	}
+
+	rows, err := m.DB.ListScheduledMessages(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*model.ScheduledMessage, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, &model.ScheduledMessage{ScheduledMessage: row})
+	}
+
+	return messages, nil
+}
+
+// Update changes the body and time of a scheduled message. A failed message
+// is queued again. Messages that are being sent can't be changed.
+func (m ScheduledMessageModel) Update(message *model.ScheduledMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.UpdateScheduledMessageParams{
+		Body:        message.Body,
+		SendAt:      message.SendAt,
+		ScheduledID: message.ScheduledID,
+		Version:     message.Version,
+	}
+
+	row, err := m.DB.UpdateScheduledMessage(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	message.Status = row.Status
+	message.Error = pgtype.Text{}
+	message.Version = row.Version
+
+	return nil
+}
+
+func (m ScheduledMessageModel) Delete(message *model.ScheduledMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.DeleteScheduledMessageParams{
+		ScheduledID: message.ScheduledID,
+		Version:     message.Version,
+	}
+
+	rows, err := m.DB.DeleteScheduledMessage(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrEditConflict
+	}
+
+	return nil
+}
+
+// CancelAll drops senderID's scheduled messages in a conversation, for
+// when they are no longer a member. It returns the ids of the dropped
+// messages.
+func (m ScheduledMessageModel) CancelAll(conversationID, senderID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.CancelScheduledMessagesParams{
+		ConversationID: conversationID,
+		SenderPid:      senderID,
+	}
+
+	return m.DB.CancelScheduledMessages(ctx, args)
+}
+
+// Claim marks up to limit due messages as being sent and returns them.
+// Rows locked by another instance are skipped, so each message is claimed
+// once. Claims older than staleAfter are assumed to belong to an instance
+// that died and are taken over.
+func (m ScheduledMessageModel) Claim(limit int, staleAfter time.Duration) ([]*model.ScheduledMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.ClaimScheduledMessagesParams{
+		StaleBefore: pgtype.Timestamptz{Time: time.Now().Add(-staleAfter), Valid: true},
+		BatchSize:   int32(limit),
+	}
+
+	rows, err := m.DB.ClaimScheduledMessages(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*model.ScheduledMessage, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, &model.ScheduledMessage{ScheduledMessage: row})
+	}
+
+	return messages, nil
+}
+
+// Complete removes a scheduled message once it has been sent.
+func (m ScheduledMessageModel) Complete(message *model.ScheduledMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.CompleteScheduledMessage(ctx, message.ScheduledID)
+}
+
+// Fail keeps a message that couldn't be sent so its sender can edit and
+// reschedule it or cancel it.
+func (m ScheduledMessageModel) Fail(message *model.ScheduledMessage, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.FailScheduledMessageParams{
+		ScheduledID: message.ScheduledID,
+		Error:       pgtype.Text{String: reason, Valid: true},
+	}
+
+	if err := m.DB.FailScheduledMessage(ctx, args); err != nil {
+		return err
+	}
+
+	message.Status = model.ScheduledFailed
+	message.Error = args.Error
+	message.Version++
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/validator"
+)
+
+func TestValidateSendAt(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		sendAt time.Time
+		valid  bool
+	}{
+		{"in a minute", now.Add(time.Minute), true},
+		{"in a month", now.AddDate(0, 1, 0), true},
+		{"just under a year", now.Add(MaxScheduleAhead - time.Hour), true},
+		{"missing", time.Time{}, false},
+		{"in the past", now.Add(-time.Minute), false},
+		{"more than a year ahead", now.Add(MaxScheduleAhead + time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateSendAt(v, tt.sendAt)
+
+			if v.Valid() != tt.valid {
+				t.Fatalf("got valid %v, want %v: %v", v.Valid(), tt.valid, v.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/redis/go-redis/v9"
+)
+
+type ThreadModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+}
+
+// Follow records an explicit choice to follow or unfollow a thread, which
+// later automatic follows don't override.
+func (m ThreadModel) Follow(threadID, userID int64, following bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.FollowThreadParams{
+		ThreadID:  threadID,
+		UserPid:   userID,
+		Following: following,
+	}
+
+	return m.DB.FollowThread(ctx, args)
+}
+
+// AutoFollow follows a thread on behalf of its author and repliers unless
+// they unfollowed it before.
+func (m ThreadModel) AutoFollow(threadID int64, userIDs ...int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	for _, userID := range userIDs {
+		args := db.AutoFollowThreadParams{
+			ThreadID: threadID,
+			UserPid:  userID,
+		}
+
+		if err := m.DB.AutoFollowThread(ctx, args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m ThreadModel) IsFollowing(threadID, userID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.IsFollowingThreadParams{
+		ThreadID: threadID,
+		UserPid:  userID,
+	}
+
+	return m.DB.IsFollowingThread(ctx, args)
+}
+
+// GetFollowerIDs returns the followers of a thread who are still in its
+// conversation.
+func (m ThreadModel) GetFollowerIDs(threadID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.ListThreadFollowerIDs(ctx, threadID)
+}
@@ -0,0 +1,30 @@
+package data
+
+import (
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/cache"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/redis/go-redis/v9"
+)
+
+// TypingTTL is how long a typing indicator lasts unless it is refreshed.
+const TypingTTL = 6 * time.Second
+
+// TypingModel keeps typing indicators in Redis only. They are short lived
+// and never worth a database write. Indicators are kept per connection so
+// that a user typing on two devices stays typing until both stop.
+type TypingModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+}
+
+func (m TypingModel) Start(conversationID, userID, connectionID int64) error {
+	return cache.SetTyping(m.Redis, conversationID, userID, connectionID, TypingTTL)
+}
+
+// Stop clears a connection's typing indicator and reports whether the user
+// is no longer typing in the conversation on any connection.
+func (m TypingModel) Stop(conversationID, userID, connectionID int64) (bool, error) {
+	return cache.DelTyping(m.Redis, conversationID, userID, connectionID)
+}
@@ -0,0 +1,164 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/cache"
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/RickinShah/BuzzChat/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// UnreadModel keeps per-conversation unread and mention counters in Redis.
+// They are bumped as messages arrive, recounted from the read watermark when
+// it moves, and rebuilt from Postgres whenever they are missing and by
+// Reconcile, which also corrects drift from deletions.
+type UnreadModel struct {
+	DB    *db.Queries
+	Redis *redis.Client
+}
+
+// Get returns the counters of every conversation of a user with unread
+// messages and the user's badge.
+func (m UnreadModel) Get(userID int64) (map[int64]*model.UnreadCount, *model.Badge, error) {
+	counts, built, err := cache.GetUnread(m.Redis, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !built {
+		counts, err = m.rebuild(userID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	badge := &model.Badge{}
+	for _, count := range counts {
+		badge.Unread += count.Unread
+		badge.Mentions += count.Mentions
+	}
+
+	return counts, badge, nil
+}
+
+// MessageCreated counts a new message as unread for its recipients, and as a
+// mention for the ones it mentions. Updates are returned for the recipients
+// whose counters are built; the others get theirs on the next rebuild.
+func (m UnreadModel) MessageCreated(conversationID, messageID int64, recipientIDs, mentionedIDs []int64) ([]*model.UnreadUpdate, error) {
+	return cache.IncrUnread(m.Redis, recipientIDs, conversationID, messageID, mentionedIDs)
+}
+
+// Recount recounts one conversation for a user, typically after their read
+// watermark moved.
+func (m UnreadModel) Recount(conversationID, userID int64) (*model.UnreadUpdate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	counts, countedTo, err := m.count(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, ok := counts[conversationID]
+	if !ok {
+		count = &model.UnreadCount{ConversationID: conversationID}
+	}
+
+	update, err := cache.SetUnread(m.Redis, userID, count, countedTo)
+	if err != nil || update != nil {
+		return update, err
+	}
+
+	counts, badge, err := m.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if counts[conversationID] != nil {
+		count = counts[conversationID]
+	}
+
+	return &model.UnreadUpdate{UserID: userID, Count: count, Badge: badge}, nil
+}
+
+// Reconcile rebuilds the counters of every user who has them and returns
+// how many were rebuilt.
+func (m UnreadModel) Reconcile() (int, error) {
+	var cursor uint64
+	var rebuilt int
+
+	for {
+		userIDs, next, err := cache.ScanUnreadUsers(m.Redis, cursor)
+		if err != nil {
+			return rebuilt, err
+		}
+
+		for _, userID := range userIDs {
+			if _, err := m.rebuild(userID); err != nil {
+				return rebuilt, err
+			}
+			rebuilt++
+		}
+
+		if next == 0 {
+			return rebuilt, nil
+		}
+		cursor = next
+	}
+}
+
+// Lock claims the next reconciliation round for ttl across instances.
+func (m UnreadModel) Lock(ttl time.Duration) (bool, error) {
+	return cache.AcquireLock(m.Redis, "unread-reconcile", ttl)
+}
+
+func (m UnreadModel) rebuild(userID int64) (map[int64]*model.UnreadCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	counts, countedTo, err := m.count(ctx, userID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.SetAllUnread(m.Redis, userID, counts, countedTo); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// count reads unread counts from Postgres, for one conversation or for all
+// of them when conversationID is 0. Only messages up to the newest one at
+// the time are counted, and its id is returned so that the cache can tell
+// which increments the counts already include.
+func (m UnreadModel) count(ctx context.Context, userID, conversationID int64) (map[int64]*model.UnreadCount, int64, error) {
+	countedTo, err := m.DB.GetLastMessageID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args := db.ListUnreadCountsParams{
+		CountedTo:      countedTo,
+		UserPid:        userID,
+		ConversationID: conversationID,
+	}
+
+	rows, err := m.DB.ListUnreadCounts(ctx, args)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	counts := make(map[int64]*model.UnreadCount, len(rows))
+	for _, row := range rows {
+		counts[row.ConversationID] = &model.UnreadCount{
+			ConversationID: row.ConversationID,
+			Unread:         row.UnreadCount,
+			Mentions:       row.MentionCount,
+		}
+	}
+
+	return counts, countedTo, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/RickinShah/BuzzChat/internal/cache"
@@ -316,3 +317,45 @@ func (m UserModel) GetByToken(tokenScope, tokenPlaintext string) (*model.User, e
 
 	return customUser, nil
 }
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (m UserModel) Search(viewerID int64, query string, filters Filters) ([]*model.User, Metadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := db.SearchUsersParams{
+		Query:     likeEscaper.Replace(query),
+		UserPid:   viewerID,
+		PageLimit: filters.keysetLimit(),
+	}
+
+	if c := filters.keyset(); c != nil {
+		args.HasCursor = true
+		args.CursorUsername = c.Value
+		args.Backward = c.Backward
+	}
+
+	rows, err := m.DB.SearchUsers(ctx, args)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	users := make([]*model.User, 0, len(rows))
+	for _, row := range rows {
+		user := model.NewUser(&db.User{
+			UserPid:    row.UserPid,
+			Username:   row.Username,
+			Name:       row.Name,
+			ProfilePic: row.ProfilePic,
+		})
+		user.SetMarshalType(model.Minimal)
+		users = append(users, user)
+	}
+
+	users, metadata := calculateKeysetMetadata(users, filters, func(u *model.User) (string, int64) {
+		return u.Username, u.UserPid
+	})
+
+	return users, metadata, nil
+}
@@ -8,6 +8,143 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Attachment struct {
+	AttachmentID int64
+	UserPid      int64
+	MessageID    pgtype.Int8
+	Sha256       string
+	Filename     string
+	Size         int64
+	ContentType  string
+	CreatedAt    pgtype.Timestamptz
+}
+
+type Blob struct {
+	Sha256         string
+	Size           int64
+	ContentType    string
+	StorageKey     string
+	CreatedAt      pgtype.Timestamptz
+	LastUsedAt     pgtype.Timestamptz
+	Status         string
+	Width          pgtype.Int4
+	Height         pgtype.Int4
+	DurationMs     pgtype.Int8
+	Blurhash       pgtype.Text
+	ThumbnailSizes []int32
+	ThumbnailType  pgtype.Text
+}
+
+type Block struct {
+	BlockerPid int64
+	BlockedPid int64
+	CreatedAt  pgtype.Timestamptz
+}
+
+type Contact struct {
+	RequesterPid int64
+	AddresseePid int64
+	Status       string
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+}
+
+type Conversation struct {
+	ConversationID  int64
+	Kind            string
+	DirectKey       pgtype.Text
+	LastMessageID   pgtype.Int8
+	LastMessageAt   pgtype.Timestamptz
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+	Version         int32
+	Title           pgtype.Text
+	Avatar          pgtype.Text
+	Description     pgtype.Text
+	LinkPreviews    bool
+	MentionAll      string
+	MessageTtl      int32
+	AllowForwarding bool
+}
+
+type ConversationParticipant struct {
+	ConversationID  int64
+	UserPid         int64
+	JoinedAt        pgtype.Timestamptz
+	Role            string
+	LastDeliveredID int64
+	LastReadID      int64
+	MutedUntil      pgtype.Timestamptz
+}
+
+type HiddenMessage struct {
+	UserPid   int64
+	MessageID int64
+	HiddenAt  pgtype.Timestamptz
+}
+
+type LinkPreview struct {
+	MessageID   int64
+	Url         string
+	Title       pgtype.Text
+	Description pgtype.Text
+	ImageUrl    pgtype.Text
+	SiteName    pgtype.Text
+	CreatedAt   pgtype.Timestamptz
+}
+
+type Message struct {
+	MessageID            int64
+	ConversationID       int64
+	SenderPid            pgtype.Int8
+	Kind                 string
+	Body                 string
+	CreatedAt            pgtype.Timestamptz
+	EditedAt             pgtype.Timestamptz
+	Version              int32
+	DeletedAt            pgtype.Timestamptz
+	DeleterPid           pgtype.Int8
+	ParentID             pgtype.Int8
+	ThreadID             pgtype.Int8
+	Quote                []byte
+	ReplyCount           int32
+	LastReplyAt          pgtype.Timestamptz
+	ThreadParticipantIds []int64
+	Entities             []byte
+	ExpiresAt            pgtype.Timestamptz
+	Forward              []byte
+}
+
+type MessageEdit struct {
+	MessageID  int64
+	Version    int32
+	Body       string
+	EditorPid  pgtype.Int8
+	ReplacedAt pgtype.Timestamptz
+}
+
+type MessageMention struct {
+	MessageID int64
+	UserPid   int64
+}
+
+type MessageReaction struct {
+	MessageID int64
+	UserPid   int64
+	Emoji     string
+	CreatedAt pgtype.Timestamptz
+}
+
+type Notification struct {
+	NotificationID int64
+	UserPid        int64
+	ActorPid       pgtype.Int8
+	Kind           string
+	Payload        []byte
+	ReadAt         pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+}
+
 type Otp struct {
 	UserPid   int64
 	CreatedAt pgtype.Timestamptz
@@ -15,6 +152,65 @@ type Otp struct {
 	Expiry    pgtype.Timestamptz
 }
 
+type PinnedMessage struct {
+	MessageID      int64
+	ConversationID int64
+	PinnerPid      pgtype.Int8
+	PinnedAt       pgtype.Timestamptz
+}
+
+type Poll struct {
+	MessageID      int64
+	ConversationID int64
+	Question       string
+	Options        []string
+	MultipleChoice bool
+	Anonymous      bool
+	ClosesAt       pgtype.Timestamptz
+	ClosedAt       pgtype.Timestamptz
+}
+
+type PollVote struct {
+	MessageID int64
+	OptionID  int32
+	UserPid   int64
+	CreatedAt pgtype.Timestamptz
+}
+
+type PrivacySetting struct {
+	UserPid              int64
+	BioVisibility        string
+	LastSeenVisibility   string
+	ProfilePicVisibility string
+	EmailVisibility      string
+	WhoCanMessage        string
+	Searchable           bool
+	UpdatedAt            pgtype.Timestamptz
+	Version              int32
+	ReadReceipts         bool
+	ForwardAttribution   bool
+}
+
+type ScheduledMessage struct {
+	ScheduledID    int64
+	ConversationID int64
+	SenderPid      int64
+	Body           string
+	SendAt         pgtype.Timestamptz
+	Status         string
+	Error          pgtype.Text
+	ClaimedAt      pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	Version        int32
+}
+
+type ThreadFollower struct {
+	ThreadID  int64
+	UserPid   int64
+	Following bool
+	UpdatedAt pgtype.Timestamptz
+}
+
 type Token struct {
 	Hash   []byte
 	UserID int64
@@ -22,6 +218,19 @@ type Token struct {
 	Scope  string
 }
 
+type Upload struct {
+	UploadID     int64
+	UserPid      int64
+	Filename     string
+	Size         int64
+	UploadOffset int64
+	ContentType  string
+	HashState    []byte
+	PartKeys     []string
+	CreatedAt    pgtype.Timestamptz
+	ExpiresAt    pgtype.Timestamptz
+}
+
 type User struct {
 	UserPid      int64
 	Username     string
@@ -34,4 +243,5 @@ type User struct {
 	CreatedAt    pgtype.Timestamptz
 	UpdatedAt    pgtype.Timestamptz
 	Version      int32
+	LastSeen     pgtype.Timestamptz
 }
@@ -39,7 +39,8 @@ SELECT
     u.profile_pic,
     u.created_at,
     u.updated_at,
-    u.version
+    u.version,
+    u.last_seen
 FROM
     users u
     INNER JOIN tokens t ON u.user_pid = t.user_id
@@ -70,6 +71,7 @@ func (q *Queries) GetUserByToken(ctx context.Context, arg GetUserByTokenParams)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Version,
+		&i.LastSeen,
 	)
 	return i, err
 }
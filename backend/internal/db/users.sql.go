@@ -33,7 +33,8 @@ SELECT
     profile_pic,
     created_at,
     updated_at,
-    version
+    version,
+    last_seen
 FROM
     users
 WHERE
@@ -55,6 +56,7 @@ func (q *Queries) GetUser(ctx context.Context, userPid int64) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Version,
+		&i.LastSeen,
 	)
 	return i, err
 }
@@ -71,7 +73,8 @@ SELECT
     profile_pic,
     created_at,
     updated_at,
-    version
+    version,
+    last_seen
 FROM
     users
 WHERE
@@ -93,6 +96,7 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Version,
+		&i.LastSeen,
 	)
 	return i, err
 }
@@ -109,7 +113,8 @@ SELECT
     profile_pic,
     created_at,
     updated_at,
-    version
+    version,
+    last_seen
 FROM
     users
 WHERE
@@ -132,6 +137,7 @@ func (q *Queries) GetUserByEmailOrUsername(ctx context.Context, email string) (U
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Version,
+		&i.LastSeen,
 	)
 	return i, err
 }
@@ -148,7 +154,8 @@ SELECT
     profile_pic,
     created_at,
     updated_at,
-    version
+    version,
+    last_seen
 FROM
     users
 WHERE
@@ -170,6 +177,7 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Version,
+		&i.LastSeen,
 	)
 	return i, err
 }
@@ -216,6 +224,149 @@ func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (InsertU
 	return i, err
 }
 
+const listLastSeen = `-- name: ListLastSeen :many
+SELECT
+    u.user_pid,
+    u.last_seen,
+    COALESCE(s.last_seen_visibility, 'contacts')::text AS last_seen_visibility
+FROM
+    users u
+    LEFT JOIN privacy_settings s ON s.user_pid = u.user_pid
+WHERE
+    u.user_pid = ANY ($1::bigint[])
+`
+
+type ListLastSeenRow struct {
+	UserPid            int64
+	LastSeen           pgtype.Timestamptz
+	LastSeenVisibility string
+}
+
+func (q *Queries) ListLastSeen(ctx context.Context, userPids []int64) ([]ListLastSeenRow, error) {
+	rows, err := q.db.Query(ctx, listLastSeen, userPids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLastSeenRow
+	for rows.Next() {
+		var i ListLastSeenRow
+		if err := rows.Scan(&i.UserPid, &i.LastSeen, &i.LastSeenVisibility); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchUsers = `-- name: SearchUsers :many
+SELECT
+    u.user_pid,
+    u.username,
+    u.name,
+    CASE WHEN profile_pic_visible (p.profile_pic_visibility, u.user_pid, $1::bigint) THEN
+        u.profile_pic
+    END AS profile_pic
+FROM
+    users u
+    LEFT JOIN privacy_settings p ON p.user_pid = u.user_pid
+WHERE (u.username ILIKE $2::text || '%'
+    OR u.name ILIKE '%' || $2::text || '%')
+    AND COALESCE(p.searchable, TRUE)
+    AND u.user_pid <> $1
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            blocks b
+        WHERE
+            b.blocker_pid = u.user_pid
+            AND b.blocked_pid = $1)
+    AND (NOT $3::bool
+        OR ($4::bool
+            AND u.username < $5::citext)
+        OR (NOT $4::bool
+            AND u.username > $5::citext))
+ORDER BY
+    CASE WHEN $4::bool THEN
+        u.username
+    END DESC,
+    CASE WHEN NOT $4::bool THEN
+        u.username
+    END ASC
+LIMIT $6
+`
+
+type SearchUsersParams struct {
+	UserPid        int64
+	Query          string
+	HasCursor      bool
+	Backward       bool
+	CursorUsername string
+	PageLimit      int32
+}
+
+type SearchUsersRow struct {
+	UserPid    int64
+	Username   string
+	Name       pgtype.Text
+	ProfilePic pgtype.Text
+}
+
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]SearchUsersRow, error) {
+	rows, err := q.db.Query(ctx, searchUsers,
+		arg.UserPid,
+		arg.Query,
+		arg.HasCursor,
+		arg.Backward,
+		arg.CursorUsername,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchUsersRow
+	for rows.Next() {
+		var i SearchUsersRow
+		if err := rows.Scan(
+			&i.UserPid,
+			&i.Username,
+			&i.Name,
+			&i.ProfilePic,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateLastSeen = `-- name: UpdateLastSeen :exec
+UPDATE
+    users
+SET
+    last_seen = $1
+WHERE
+    user_pid = $2
+`
+
+type UpdateLastSeenParams struct {
+	LastSeen pgtype.Timestamptz
+	UserPid  int64
+}
+
+func (q *Queries) UpdateLastSeen(ctx context.Context, arg UpdateLastSeenParams) error {
+	_, err := q.db.Exec(ctx, updateLastSeen, arg.LastSeen, arg.UserPid)
+	return err
+}
+
 const updatePassword = `-- name: UpdatePassword :one
 UPDATE
     users
@@ -0,0 +1,320 @@
+// Package jobs is a small background job queue on a Redis list. Any
+// instance can enqueue a job and whichever worker takes it first runs it.
+//
+// Workers move a job into their own processing list while it runs and
+// only remove it once it's finished, so a job taken by an instance that
+// crashes is put back on the queue by the others instead of being lost.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/jsonlog"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	queueKey = "job_queue"
+
+	// deadKey keeps jobs that failed every attempt, for inspection.
+	deadKey = "job_queue:dead"
+
+	// processingPrefix and alivePrefix are followed by the instance id.
+	// Each worker has its own processing list, and the alive key is
+	// refreshed for as long as the instance's workers are running.
+	processingPrefix = "job_queue:processing:"
+	alivePrefix      = "job_queue:alive:"
+
+	aliveTTL      = 30 * time.Second
+	heartbeat     = 10 * time.Second
+	sweepInterval = time.Minute
+
+	// MaxAttempts is how many times a failing job runs before it's given up.
+	MaxAttempts = 5
+)
+
+var logger = jsonlog.New(os.Stdout, jsonlog.LevelInfo)
+
+// Job is a queued unit of work. Payload is decoded by the handler
+// registered for Type.
+type Job struct {
+	Type     string          `json:"type"`
+	Payload  json.RawMessage `json:"payload"`
+	Attempts int             `json:"attempts"`
+}
+
+// Handler runs a job. Returning an error retries the job later, so
+// handlers should be safe to run more than once.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+type finalAttemptKey struct{}
+
+// IsFinalAttempt reports whether the job being handled is on its last
+// attempt, so a handler can record the failure instead of leaving it to
+// the dead list.
+func IsFinalAttempt(ctx context.Context) bool {
+	final, _ := ctx.Value(finalAttemptKey{}).(bool)
+	return final
+}
+
+type Queue struct {
+	rdb      *redis.Client
+	id       string
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func New(rdb *redis.Client) *Queue {
+	id := make([]byte, 8)
+	rand.Read(id)
+
+	return &Queue{
+		rdb:      rdb,
+		id:       hex.EncodeToString(id),
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers the handler for a job type. Register every type before
+// calling Run.
+func (q *Queue) Handle(jobType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.handlers[jobType] = handler
+}
+
+func (q *Queue) Enqueue(jobType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return q.push(Job{Type: jobType, Payload: data})
+}
+
+func (q *Queue) push(job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	key := queueKey
+	if job.Attempts >= MaxAttempts {
+		key = deadKey
+	}
+
+	return q.rdb.RPush(ctx, key, data).Err()
+}
+
+// Run starts workers goroutines that take jobs off the queue until ctx is
+// cancelled. It returns once every worker has finished its current job.
+func (q *Queue) Run(ctx context.Context, workers int) {
+	q.keepAlive()
+
+	done := make(chan struct{})
+	go q.maintain(done)
+
+	var wg sync.WaitGroup
+
+	for n := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx, processingKey(q.id, n))
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+
+	delCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := q.rdb.Del(delCtx, alivePrefix+q.id).Err(); err != nil {
+		logger.PrintError(err, nil)
+	}
+}
+
+// maintain refreshes this instance's alive key and periodically puts back
+// the jobs held by instances that stopped refreshing theirs.
+func (q *Queue) maintain(done <-chan struct{}) {
+	beat := time.NewTicker(heartbeat)
+	defer beat.Stop()
+
+	sweep := time.NewTicker(sweepInterval)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-beat.C:
+			q.keepAlive()
+		case <-sweep.C:
+			if err := q.requeueOrphans(); err != nil {
+				logger.PrintError(err, nil)
+			}
+		}
+	}
+}
+
+func (q *Queue) keepAlive() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := q.rdb.Set(ctx, alivePrefix+q.id, time.Now().Unix(), aliveTTL).Err(); err != nil {
+		logger.PrintError(err, nil)
+	}
+}
+
+// requeueOrphans moves the jobs left in the processing lists of instances
+// that are no longer alive back onto the queue.
+func (q *Queue) requeueOrphans() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	iter := q.rdb.Scan(ctx, 0, processingPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+
+		instance, ok := instanceOf(key)
+		if !ok || instance == q.id {
+			continue
+		}
+
+		alive, err := q.rdb.Exists(ctx, alivePrefix+instance).Result()
+		if err != nil {
+			return err
+		}
+		if alive > 0 {
+			continue
+		}
+
+		for {
+			err := q.rdb.LMove(ctx, key, queueKey, "LEFT", "RIGHT").Err()
+			if errors.Is(err, redis.Nil) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return iter.Err()
+}
+
+func processingKey(instance string, worker int) string {
+	return processingPrefix + instance + ":" + strconv.Itoa(worker)
+}
+
+// instanceOf returns the instance id in a processing list key.
+func instanceOf(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, processingPrefix)
+	if !ok {
+		return "", false
+	}
+
+	instance, _, ok := strings.Cut(rest, ":")
+	if !ok || instance == "" {
+		return "", false
+	}
+
+	return instance, true
+}
+
+func (q *Queue) work(ctx context.Context, processing string) {
+	for ctx.Err() == nil {
+		data, err := q.rdb.BLMove(ctx, queueKey, processing, "LEFT", "RIGHT", 5*time.Second).Result()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+				logger.PrintError(fmt.Errorf("redis error: %w", err), nil)
+				time.Sleep(2 * time.Second)
+			}
+			continue
+		}
+
+		var job Job
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			logger.PrintError(fmt.Errorf("invalid job: %w", err), nil)
+		} else {
+			q.run(ctx, job)
+		}
+
+		// The job is only dropped from the processing list once it has
+		// finished or been pushed back, so a crash in between leaves it
+		// for requeueOrphans.
+		if err := q.ack(processing, data); err != nil {
+			logger.PrintError(err, map[string]any{"type": job.Type})
+		}
+	}
+}
+
+func (q *Queue) ack(processing, data string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return q.rdb.LRem(ctx, processing, 1, data).Err()
+}
+
+func (q *Queue) run(ctx context.Context, job Job) {
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+
+	if !ok {
+		logger.PrintError(errors.New("no handler for job"), map[string]any{"type": job.Type})
+		return
+	}
+
+	jobCtx := context.WithValue(ctx, finalAttemptKey{}, job.Attempts+1 >= MaxAttempts)
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+		return handler(jobCtx, job.Payload)
+	}()
+	if err == nil {
+		return
+	}
+
+	// A job interrupted by shutdown didn't really fail, so it goes back
+	// without using up an attempt.
+	if ctx.Err() != nil {
+		if err := q.push(job); err != nil {
+			logger.PrintError(err, map[string]any{"type": job.Type})
+		}
+		return
+	}
+
+	job.Attempts++
+	logger.PrintError(err, map[string]any{"type": job.Type, "attempts": job.Attempts})
+
+	// Back off a little before the job goes back on the queue, so a
+	// failing dependency isn't hammered.
+	select {
+	case <-time.After(time.Duration(job.Attempts) * time.Second):
+	case <-ctx.Done():
+	}
+
+	if err := q.push(job); err != nil {
+		logger.PrintError(err, map[string]any{"type": job.Type})
+	}
+}
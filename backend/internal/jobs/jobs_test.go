@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+)
+
+func TestInstanceOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		instance string
+		ok       bool
+	}{
+		{"processing list", processingKey("a1b2", 3), "a1b2", true},
+		{"queue", queueKey, "", false},
+		{"dead list", deadKey, "", false},
+		{"alive key", alivePrefix + "a1b2", "", false},
+		{"no worker", processingPrefix + "a1b2", "", false},
+		{"no instance", processingPrefix + ":0", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance, ok := instanceOf(tt.key)
+			if ok != tt.ok || instance != tt.instance {
+				t.Errorf("got %q, %v, want %q, %v", instance, ok, tt.instance, tt.ok)
+			}
+		})
+	}
+}
+
+func TestIsFinalAttempt(t *testing.T) {
+	if IsFinalAttempt(context.Background()) {
+		t.Error("got final attempt outside a job")
+	}
+
+	ctx := context.WithValue(context.Background(), finalAttemptKey{}, true)
+	if !IsFinalAttempt(ctx) {
+		t.Error("got not final attempt")
+	}
+}
@@ -0,0 +1,185 @@
+// Package media inspects uploaded images in pure Go: it strips metadata,
+// measures them, and renders thumbnails and blurhash placeholders.
+//
+// MP4 and QuickTime videos are only measured, from their container headers.
+// Reading frames out of them needs a codec library such as ffmpeg, which
+// this package deliberately doesn't depend on, so videos get no thumbnail
+// and are stored and served exactly as uploaded. Other video formats aren't
+// inspected at all.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ThumbnailSizes are the bounding boxes, in pixels, thumbnails are rendered
+// at. Images already smaller than a size don't get a thumbnail for it.
+var ThumbnailSizes = []int{160, 640}
+
+// MaxPixels guards against decompression bombs: a small file that decodes to
+// an enormous bitmap.
+const MaxPixels = 50_000_000
+
+var (
+	ErrUnsupported = errors.New("media: unsupported format")
+	ErrTooLarge    = errors.New("media: image too large")
+)
+
+// Thumbnail is an encoded thumbnail that fits in a Size x Size box.
+type Thumbnail struct {
+	Size int
+	Data []byte
+}
+
+// Result describes a processed image. Data is the image with its metadata
+// removed, to be stored in place of the upload, in the original format.
+// Thumbnails are JPEG unless the image has transparency, then PNG.
+type Result struct {
+	Data          []byte
+	Width         int
+	Height        int
+	Blurhash      string
+	Thumbnails    []Thumbnail
+	ThumbnailType string
+}
+
+// CanProcess reports whether contentType is an image Process handles or a
+// video ProbeVideo reads.
+func CanProcess(contentType string) bool {
+	return isImage(contentType) || IsVideo(contentType)
+}
+
+func isImage(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// Process decodes an image and prepares everything the app stores about it.
+//
+// JPEG and PNG are re-encoded, which drops EXIF, GPS and any other
+// metadata; the EXIF orientation is applied first so the image doesn't turn
+// sideways once the tag is gone. GIFs are kept as they are so animations
+// survive, since GIF has no EXIF to begin with. WebP can't be encoded in pure
+// Go, so its metadata chunks are cut out of the container instead.
+func Process(data []byte, contentType string) (*Result, error) {
+	if !isImage(contentType) {
+		return nil, ErrUnsupported
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Width*config.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{ThumbnailType: "image/jpeg"}
+	if !isOpaque(img) {
+		result.ThumbnailType = "image/png"
+	}
+
+	switch contentType {
+	case "image/jpeg":
+		img = orient(img, jpegOrientation(data))
+		result.Data, err = encode(img, contentType)
+	case "image/png":
+		result.Data, err = encode(img, contentType)
+	case "image/gif":
+		result.Data = data
+	case "image/webp":
+		result.Data, err = stripWebP(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	result.Width = bounds.Dx()
+	result.Height = bounds.Dy()
+
+	result.Blurhash = Blurhash(resize(img, 32), 4, 3)
+
+	for _, size := range ThumbnailSizes {
+		if result.Width <= size && result.Height <= size {
+			continue
+		}
+
+		thumbnail, err := encode(resize(img, size), result.ThumbnailType)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Thumbnails = append(result.Thumbnails, Thumbnail{Size: size, Data: thumbnail})
+	}
+
+	return result, nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
+
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+
+	switch contentType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90})
+	case "image/png":
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// resize scales img down to fit in a size x size box, keeping its aspect
+// ratio. Images that already fit are returned as they are.
+func resize(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= size && height <= size {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
@@ -0,0 +1,168 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(width, height int, alpha uint8) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: alpha})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		contentType   string
+		width         int
+		height        int
+		thumbnails    []int
+		thumbnailType string
+	}{
+		{
+			name:          "large jpeg",
+			data:          encodeJPEG(t, testImage(800, 400, 255)),
+			contentType:   "image/jpeg",
+			width:         800,
+			height:        400,
+			thumbnails:    []int{160, 640},
+			thumbnailType: "image/jpeg",
+		},
+		{
+			name:          "medium png",
+			data:          encodePNG(t, testImage(300, 200, 255)),
+			contentType:   "image/png",
+			width:         300,
+			height:        200,
+			thumbnails:    []int{160},
+			thumbnailType: "image/jpeg",
+		},
+		{
+			name:          "small transparent png",
+			data:          encodePNG(t, testImage(100, 50, 128)),
+			contentType:   "image/png",
+			width:         100,
+			height:        50,
+			thumbnailType: "image/png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Process(tt.data, tt.contentType)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Width != tt.width || result.Height != tt.height {
+				t.Errorf("got %dx%d, want %dx%d", result.Width, result.Height, tt.width, tt.height)
+			}
+
+			if result.ThumbnailType != tt.thumbnailType {
+				t.Errorf("got thumbnail type %q, want %q", result.ThumbnailType, tt.thumbnailType)
+			}
+
+			if result.Blurhash == "" {
+				t.Error("got no blurhash")
+			}
+
+			if _, format, err := image.DecodeConfig(bytes.NewReader(result.Data)); err != nil || "image/"+format != tt.contentType {
+				t.Errorf("got stripped image of format %q (%v), want %s", format, err, tt.contentType)
+			}
+
+			if len(result.Thumbnails) != len(tt.thumbnails) {
+				t.Fatalf("got %d thumbnails, want %d", len(result.Thumbnails), len(tt.thumbnails))
+			}
+
+			for i, thumbnail := range result.Thumbnails {
+				if thumbnail.Size != tt.thumbnails[i] {
+					t.Errorf("got thumbnail size %d, want %d", thumbnail.Size, tt.thumbnails[i])
+				}
+
+				config, _, err := image.DecodeConfig(bytes.NewReader(thumbnail.Data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if max(config.Width, config.Height) != thumbnail.Size {
+					t.Errorf("got %dx%d thumbnail, want it to fit %d", config.Width, config.Height, thumbnail.Size)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		err         error
+	}{
+		{
+			name:        "video",
+			data:        []byte("\x00\x00\x00\x08moov"),
+			contentType: "video/mp4",
+			err:         ErrUnsupported,
+		},
+		{
+			name:        "document",
+			data:        []byte("%PDF-1.7"),
+			contentType: "application/pdf",
+			err:         ErrUnsupported,
+		},
+		{
+			name:        "too many pixels",
+			data:        encodePNG(t, image.NewGray(image.Rect(0, 0, 10_000, 5_001))),
+			contentType: "image/png",
+			err:         ErrTooLarge,
+		},
+		{
+			name:        "corrupt",
+			data:        []byte("\x89PNG\r\n\x1a\nnot really"),
+			contentType: "image/png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Process(tt.data, tt.contentType)
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
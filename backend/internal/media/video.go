@@ -0,0 +1,302 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// MaxMovieHeader caps how much of a video's moov box is read into memory.
+// It holds the sample tables, which grow with the length of the video, but
+// stay far below this even for hours of footage.
+const MaxMovieHeader = 64 << 20
+
+var ErrInvalidVideo = errors.New("media: invalid video")
+
+// Video describes a video container. Width and Height are the display size
+// of the first video track, after its rotation, and are zero for files
+// without one.
+type Video struct {
+	Width    int
+	Height   int
+	Duration time.Duration
+}
+
+// IsVideo reports whether contentType is a video container ProbeVideo reads.
+func IsVideo(contentType string) bool {
+	switch contentType {
+	case "video/mp4", "video/quicktime":
+		return true
+	}
+	return false
+}
+
+// ProbeVideo reads the size and duration of an MP4 or QuickTime file from
+// its moov box. Only the box headers and the moov box itself are read, so
+// the media data is skipped over, with Seek when r supports it.
+func ProbeVideo(r io.Reader) (*Video, error) {
+	for {
+		boxType, size, err := readBoxHeader(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, ErrInvalidVideo
+			}
+			return nil, err
+		}
+
+		if boxType != "moov" {
+			// A box of unknown size runs to the end of the file, so
+			// there is nothing after it to look for.
+			if size < 0 {
+				return nil, ErrInvalidVideo
+			}
+			if err := skip(r, size); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if size > MaxMovieHeader {
+			return nil, ErrInvalidVideo
+		}
+
+		limit := size
+		if size < 0 {
+			limit = MaxMovieHeader + 1
+		}
+
+		moov, err := io.ReadAll(io.LimitReader(r, limit))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(moov)) > MaxMovieHeader || (size >= 0 && int64(len(moov)) < size) {
+			return nil, ErrInvalidVideo
+		}
+
+		return parseMovie(moov)
+	}
+}
+
+// readBoxHeader reads a box header and returns the box type and the size of
+// its body, or -1 when the box runs to the end of the file.
+func readBoxHeader(r io.Reader) (string, int64, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return "", 0, ErrInvalidVideo
+		}
+		return "", 0, err
+	}
+
+	size := int64(binary.BigEndian.Uint32(header[:4]))
+	boxType := string(header[4:])
+
+	switch size {
+	case 0:
+		return boxType, -1, nil
+	case 1:
+		var large [8]byte
+		if _, err := io.ReadFull(r, large[:]); err != nil {
+			return "", 0, truncated(err)
+		}
+		size = int64(binary.BigEndian.Uint64(large[:]))
+		if size < 16 {
+			return "", 0, ErrInvalidVideo
+		}
+		return boxType, size - 16, nil
+	}
+
+	if size < 8 {
+		return "", 0, ErrInvalidVideo
+	}
+
+	return boxType, size - 8, nil
+}
+
+func skip(r io.Reader, n int64) error {
+	if s, ok := r.(io.Seeker); ok {
+		_, err := s.Seek(n, io.SeekCurrent)
+		return err
+	}
+
+	_, err := io.CopyN(io.Discard, r, n)
+	return truncated(err)
+}
+
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrInvalidVideo
+	}
+	return err
+}
+
+func parseMovie(moov []byte) (*Video, error) {
+	video := &Video{}
+	foundHeader := false
+
+	err := eachBox(moov, func(boxType string, body []byte) error {
+		switch boxType {
+		case "mvhd":
+			duration, err := parseMovieHeader(body)
+			if err != nil {
+				return err
+			}
+			video.Duration = duration
+			foundHeader = true
+		case "trak":
+			if video.Width > 0 {
+				return nil
+			}
+			width, height, err := parseTrack(body)
+			if err != nil {
+				return err
+			}
+			video.Width, video.Height = width, height
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !foundHeader {
+		return nil, ErrInvalidVideo
+	}
+
+	return video, nil
+}
+
+// eachBox calls fn for every box directly inside data.
+func eachBox(data []byte, fn func(boxType string, body []byte) error) error {
+	r := bytes.NewReader(data)
+
+	for r.Len() > 0 {
+		boxType, size, err := readBoxHeader(r)
+		if err != nil {
+			return err
+		}
+
+		if size < 0 {
+			size = int64(r.Len())
+		}
+		if size > int64(r.Len()) {
+			return ErrInvalidVideo
+		}
+
+		offset := len(data) - r.Len()
+		if err := fn(boxType, data[offset:offset+int(size)]); err != nil {
+			return err
+		}
+
+		r.Seek(size, io.SeekCurrent)
+	}
+
+	return nil
+}
+
+// parseMovieHeader returns the duration in an mvhd box.
+func parseMovieHeader(body []byte) (time.Duration, error) {
+	if len(body) < 1 {
+		return 0, ErrInvalidVideo
+	}
+
+	var timescale, duration uint64
+
+	switch body[0] {
+	case 0:
+		if len(body) < 20 {
+			return 0, ErrInvalidVideo
+		}
+		timescale = uint64(binary.BigEndian.Uint32(body[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(body[16:20]))
+		if duration == 0xffffffff {
+			duration = 0
+		}
+	case 1:
+		if len(body) < 32 {
+			return 0, ErrInvalidVideo
+		}
+		timescale = uint64(binary.BigEndian.Uint32(body[20:24]))
+		duration = binary.BigEndian.Uint64(body[24:32])
+		if duration == 0xffffffffffffffff {
+			duration = 0
+		}
+	default:
+		return 0, ErrInvalidVideo
+	}
+
+	if timescale == 0 {
+		return 0, ErrInvalidVideo
+	}
+
+	seconds := float64(duration) / float64(timescale)
+	if seconds > float64(1<<62)/float64(time.Second) {
+		return 0, ErrInvalidVideo
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// parseTrack returns the display size of a video track, or zeros for any
+// other kind of track.
+func parseTrack(trak []byte) (int, int, error) {
+	var header []byte
+	isVideo := false
+
+	err := eachBox(trak, func(boxType string, body []byte) error {
+		switch boxType {
+		case "tkhd":
+			header = body
+		case "mdia":
+			return eachBox(body, func(boxType string, body []byte) error {
+				if boxType == "hdlr" && len(body) >= 12 && string(body[8:12]) == "vide" {
+					isVideo = true
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !isVideo || header == nil {
+		return 0, 0, nil
+	}
+
+	return parseTrackHeader(header)
+}
+
+// parseTrackHeader returns the width and height in a tkhd box, swapped when
+// its matrix turns the picture by 90 or 270 degrees.
+func parseTrackHeader(body []byte) (int, int, error) {
+	if len(body) < 1 {
+		return 0, 0, ErrInvalidVideo
+	}
+
+	// Version 1 widens the times and the duration before the matrix.
+	matrix := 40
+	if body[0] == 1 {
+		matrix = 52
+	}
+
+	size := matrix + 36
+	if len(body) < size+8 {
+		return 0, 0, ErrInvalidVideo
+	}
+
+	// Both are 16.16 fixed point.
+	width := int(binary.BigEndian.Uint32(body[size:size+4]) >> 16)
+	height := int(binary.BigEndian.Uint32(body[size+4:size+8]) >> 16)
+
+	a := int32(binary.BigEndian.Uint32(body[matrix : matrix+4]))
+	b := int32(binary.BigEndian.Uint32(body[matrix+4 : matrix+8]))
+	if a == 0 && b != 0 {
+		width, height = height, width
+	}
+
+	return width, height, nil
+}
@@ -0,0 +1,176 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func box(boxType string, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	out = append(out, boxType...)
+	return append(out, body...)
+}
+
+func movieHeader(timescale, duration uint32) []byte {
+	body := make([]byte, 100)
+	binary.BigEndian.PutUint32(body[12:], timescale)
+	binary.BigEndian.PutUint32(body[16:], duration)
+	return box("mvhd", body)
+}
+
+func trackHeader(width, height int, rotated bool) []byte {
+	body := make([]byte, 84)
+	if rotated {
+		binary.BigEndian.PutUint32(body[44:], 0x00010000)
+	} else {
+		binary.BigEndian.PutUint32(body[40:], 0x00010000)
+	}
+	binary.BigEndian.PutUint32(body[76:], uint32(width)<<16)
+	binary.BigEndian.PutUint32(body[80:], uint32(height)<<16)
+	return box("tkhd", body)
+}
+
+func track(handler string, header []byte) []byte {
+	hdlr := make([]byte, 24)
+	copy(hdlr[8:], handler)
+	return box("trak", header, box("mdia", box("hdlr", hdlr)))
+}
+
+// onlyReader hides Seek, so ProbeVideo has to read past skipped boxes.
+type onlyReader struct{ io.Reader }
+
+func TestProbeVideo(t *testing.T) {
+	ftyp := box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41"))
+	mdat := box("mdat", make([]byte, 4096))
+
+	moov := box("moov",
+		movieHeader(1000, 12_500),
+		track("soun", trackHeader(0, 0, false)),
+		track("vide", trackHeader(1920, 1080, false)),
+	)
+
+	largeMdat := binary.BigEndian.AppendUint32(nil, 1)
+	largeMdat = append(largeMdat, "mdat"...)
+	largeMdat = binary.BigEndian.AppendUint64(largeMdat, 16+64)
+	largeMdat = append(largeMdat, make([]byte, 64)...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want *Video
+		err  error
+	}{
+		{
+			name: "moov after mdat",
+			data: bytes.Join([][]byte{ftyp, mdat, moov}, nil),
+			want: &Video{Width: 1920, Height: 1080, Duration: 12500 * time.Millisecond},
+		},
+		{
+			name: "moov first",
+			data: bytes.Join([][]byte{ftyp, moov, mdat}, nil),
+			want: &Video{Width: 1920, Height: 1080, Duration: 12500 * time.Millisecond},
+		},
+		{
+			name: "64-bit box size",
+			data: bytes.Join([][]byte{ftyp, largeMdat, moov}, nil),
+			want: &Video{Width: 1920, Height: 1080, Duration: 12500 * time.Millisecond},
+		},
+		{
+			name: "rotated",
+			data: box("moov", movieHeader(600, 300), track("vide", trackHeader(1920, 1080, true))),
+			want: &Video{Width: 1080, Height: 1920, Duration: 500 * time.Millisecond},
+		},
+		{
+			name: "no video track",
+			data: box("moov", movieHeader(44100, 44100), track("soun", trackHeader(0, 0, false))),
+			want: &Video{Duration: time.Second},
+		},
+		{
+			name: "no moov",
+			data: bytes.Join([][]byte{ftyp, mdat}, nil),
+			err:  ErrInvalidVideo,
+		},
+		{
+			name: "no movie header",
+			data: box("moov", track("vide", trackHeader(640, 480, false))),
+			err:  ErrInvalidVideo,
+		},
+		{
+			name: "zero timescale",
+			data: box("moov", movieHeader(0, 100)),
+			err:  ErrInvalidVideo,
+		},
+		{
+			name: "truncated moov",
+			data: box("moov", movieHeader(1000, 1000))[:50],
+			err:  ErrInvalidVideo,
+		},
+		{
+			name: "box overruns parent",
+			data: box("moov", movieHeader(1000, 1000)[:40]),
+			err:  ErrInvalidVideo,
+		},
+		{
+			name: "empty",
+			err:  ErrInvalidVideo,
+		},
+		{
+			name: "not a container",
+			data: []byte("GIF89a\x01\x00\x01\x00"),
+			err:  ErrInvalidVideo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readers := map[string]io.Reader{
+				"seeker": bytes.NewReader(tt.data),
+				"reader": onlyReader{bytes.NewReader(tt.data)},
+			}
+
+			for kind, r := range readers {
+				got, err := ProbeVideo(r)
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("%s: got error %v, want %v", kind, err, tt.err)
+				}
+				if tt.err != nil {
+					continue
+				}
+				if *got != *tt.want {
+					t.Errorf("%s: got %+v, want %+v", kind, *got, *tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCanProcess(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"image/jpeg", true},
+		{"image/png", true},
+		{"image/gif", true},
+		{"image/webp", true},
+		{"video/mp4", true},
+		{"video/quicktime", true},
+		{"video/webm", false},
+		{"image/svg+xml", false},
+		{"application/pdf", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			if got := CanProcess(tt.contentType); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
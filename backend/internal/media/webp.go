@@ -0,0 +1,59 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errInvalidWebP = errors.New("media: invalid webp container")
+
+// stripWebP removes the EXIF and XMP chunks from a WebP file and clears the
+// matching flags in its VP8X header. The image data itself isn't touched.
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errInvalidWebP
+	}
+
+	var out bytes.Buffer
+	out.Write(data[:12])
+
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, errInvalidWebP
+		}
+
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+
+		// Chunks are padded to an even length.
+		end := i + 8 + size + size%2
+		if end > len(data) {
+			if i+8+size != len(data) {
+				return nil, errInvalidWebP
+			}
+			end = len(data)
+		}
+
+		chunk := data[i:end]
+		i = end
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+			continue
+		case "VP8X":
+			if len(chunk) > 8 {
+				chunk = bytes.Clone(chunk)
+				// Bit 3 flags EXIF, bit 2 XMP.
+				chunk[8] &^= 0x08 | 0x04
+			}
+		}
+
+		out.Write(chunk)
+	}
+
+	result := out.Bytes()
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+
+	return result, nil
+}
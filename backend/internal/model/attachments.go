@@ -0,0 +1,133 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+)
+
+// Upload is a file being uploaded in chunks. Offset is how many bytes have
+// been received so far.
+type Upload struct {
+	db.Upload
+}
+
+func (u *Upload) IsComplete() bool {
+	return u.UploadOffset == u.Size
+}
+
+func (u *Upload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"uploadId":  strconv.FormatInt(u.UploadID, 10),
+		"filename":  u.Filename,
+		"size":      u.Size,
+		"offset":    u.UploadOffset,
+		"createdAt": u.CreatedAt,
+		"expiresAt": u.ExpiresAt,
+	})
+}
+
+const (
+	MediaPending = "pending"
+	MediaReady   = "ready"
+	MediaFailed  = "failed"
+)
+
+// Attachment is a finished upload. Its bytes live in a blob shared by every
+// attachment with the same content. URL is a signed download link that
+// stops working at URLExpiresAt. ConversationID is only set when the
+// attachment is loaded together with its message.
+type Attachment struct {
+	db.Attachment
+	StorageKey     string
+	URL            string
+	URLExpiresAt   time.Time
+	Media          *Media
+	ConversationID int64
+}
+
+// Media is what the media processing job found out about an attachment.
+// Until it has run Status is pending and the rest is empty; files it
+// doesn't handle go straight to ready. Videos have no blurhash or
+// thumbnails, only their size and DurationMs.
+type Media struct {
+	Status        string
+	Width         int32
+	Height        int32
+	DurationMs    int64
+	Blurhash      string
+	Thumbnails    []*Thumbnail
+	ThumbnailType string
+}
+
+// CanDownload reports whether the bytes of the attachment may be served.
+// An image may carry the place it was taken in its EXIF data until
+// processing has stripped it, so images are held back while pending and
+// for good once processing has failed.
+func (a *Attachment) CanDownload() bool {
+	if a.Media == nil {
+		return true
+	}
+
+	switch a.Media.Status {
+	case MediaPending:
+		return false
+	case MediaFailed:
+		return !strings.HasPrefix(a.ContentType, "image/")
+	}
+
+	return true
+}
+
+// Thumbnail is a downscaled copy of an image that fits in a Size x Size box.
+type Thumbnail struct {
+	Size int32  `json:"size"`
+	URL  string `json:"url"`
+}
+
+func (a *Attachment) MarshalJSON() ([]byte, error) {
+	attachment := map[string]any{
+		"attachmentId": strconv.FormatInt(a.AttachmentID, 10),
+		"filename":     a.Filename,
+		"size":         a.Size,
+		"contentType":  a.ContentType,
+		"sha256":       a.Sha256,
+		"createdAt":    a.CreatedAt,
+	}
+
+	if a.MessageID.Valid {
+		attachment["messageId"] = strconv.FormatInt(a.MessageID.Int64, 10)
+	}
+
+	if a.ConversationID != 0 {
+		attachment["conversationId"] = strconv.FormatInt(a.ConversationID, 10)
+	}
+
+	if a.URL != "" {
+		attachment["url"] = a.URL
+		attachment["urlExpiresAt"] = a.URLExpiresAt
+	}
+
+	if a.Media != nil {
+		attachment["status"] = a.Media.Status
+
+		if a.Media.Width > 0 {
+			attachment["width"] = a.Media.Width
+			attachment["height"] = a.Media.Height
+		}
+
+		if a.Media.Blurhash != "" {
+			attachment["blurhash"] = a.Media.Blurhash
+			attachment["thumbnails"] = a.Media.Thumbnails
+		}
+
+		if a.Media.DurationMs > 0 {
+			attachment["durationMs"] = a.Media.DurationMs
+		}
+	}
+
+	return json.Marshal(attachment)
+}
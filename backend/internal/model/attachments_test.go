@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+)
+
+func TestAttachmentCanDownload(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		media       *Media
+		valid       bool
+	}{
+		{"no media", "application/pdf", nil, true},
+		{"ready image", "image/jpeg", &Media{Status: MediaReady}, true},
+		{"pending image", "image/jpeg", &Media{Status: MediaPending}, false},
+		{"failed image", "image/png", &Media{Status: MediaFailed}, false},
+		{"pending video", "video/mp4", &Media{Status: MediaPending}, false},
+		{"failed video", "video/mp4", &Media{Status: MediaFailed}, true},
+		{"ready document", "application/pdf", &Media{Status: MediaReady}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attachment := &Attachment{
+				Attachment: db.Attachment{ContentType: tt.contentType},
+				Media:      tt.media,
+			}
+
+			if attachment.CanDownload() != tt.valid {
+				t.Errorf("got %v, want %v", !tt.valid, tt.valid)
+			}
+		})
+	}
+}
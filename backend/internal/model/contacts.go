@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	ContactPending  = "pending"
+	ContactAccepted = "accepted"
+	ContactDeclined = "declined"
+	ContactBlocked  = "blocked"
+)
+
+type Contact struct {
+	db.Contact
+}
+
+func (c *Contact) IsRequester(userID int64) bool {
+	return c.RequesterPid == userID
+}
+
+func (c *Contact) OtherUser(userID int64) int64 {
+	if c.RequesterPid == userID {
+		return c.AddresseePid
+	}
+	return c.RequesterPid
+}
+
+func (c *Contact) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"requesterId": strconv.FormatInt(c.RequesterPid, 10),
+		"addresseeId": strconv.FormatInt(c.AddresseePid, 10),
+		"status":      c.Status,
+		"createdAt":   c.CreatedAt,
+		"updatedAt":   c.UpdatedAt,
+	})
+}
+
+type ContactEntry struct {
+	User      *User              `json:"user"`
+	Status    string             `json:"status"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
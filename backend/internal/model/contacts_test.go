@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+)
+
+func TestContactOtherUser(t *testing.T) {
+	contact := &Contact{Contact: db.Contact{RequesterPid: 1, AddresseePid: 2}}
+
+	tests := []struct {
+		userID        int64
+		wantOther     int64
+		wantRequester bool
+	}{
+		{1, 2, true},
+		{2, 1, false},
+	}
+
+	for _, tt := range tests {
+		if got := contact.OtherUser(tt.userID); got != tt.wantOther {
+			t.Errorf("OtherUser(%d) = %d, want %d", tt.userID, got, tt.wantOther)
+		}
+		if got := contact.IsRequester(tt.userID); got != tt.wantRequester {
+			t.Errorf("IsRequester(%d) = %v, want %v", tt.userID, got, tt.wantRequester)
+		}
+	}
+}
+
+func TestNewNotification(t *testing.T) {
+	tests := []struct {
+		name      string
+		actorID   int64
+		wantActor bool
+	}{
+		{"with actor", 7, true},
+		{"without actor", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewNotification(1, tt.actorID, NotificationContactRequest, map[string]string{"username": "alice"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if n.ActorPid.Valid != tt.wantActor {
+				t.Errorf("got actor %v, want one: %v", n.ActorPid, tt.wantActor)
+			}
+			if string(n.Payload) != `{"username":"alice"}` {
+				t.Errorf("got payload %s", n.Payload)
+			}
+		})
+	}
+}
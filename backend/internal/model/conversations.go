@@ -0,0 +1,196 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	ConversationDirect = "direct"
+	ConversationGroup  = "group"
+)
+
+// MessageTimers are the disappearing message timers a conversation can use,
+// as message lifetimes in seconds. Off keeps messages forever.
+var MessageTimers = map[string]int32{
+	"off": 0,
+	"1h":  3600,
+	"24h": 86400,
+	"7d":  604800,
+}
+
+// MessageTimer names the timer of a message lifetime.
+func MessageTimer(ttl int32) string {
+	for name, seconds := range MessageTimers {
+		if seconds == ttl {
+			return name
+		}
+	}
+	return "off"
+}
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+type Conversation struct {
+	db.Conversation
+	Participants []*Participant
+	LastMessage  *Message
+	Unread       *UnreadCount
+	MutedUntil   pgtype.Timestamptz
+}
+
+type Participant struct {
+	User     *User              `json:"user"`
+	Role     string             `json:"role"`
+	JoinedAt pgtype.Timestamptz `json:"joinedAt"`
+	Receipt  *Receipt           `json:"receipt"`
+}
+
+// Membership is the cached view of a conversation used to authorize
+// requests without loading the whole conversation.
+type Membership struct {
+	Kind         string           `json:"kind"`
+	Roles        map[int64]string `json:"roles"`
+	LinkPreviews bool             `json:"linkPreviews"`
+	MentionAll   string           `json:"mentionAll"`
+	// ForwardingRestricted is stored inverted so that memberships cached
+	// before the setting existed still allow forwarding.
+	ForwardingRestricted bool `json:"forwardingRestricted"`
+}
+
+func (m *Membership) IsMember(userID int64) bool {
+	_, ok := m.Roles[userID]
+	return ok
+}
+
+func (m *Membership) Role(userID int64) string {
+	return m.Roles[userID]
+}
+
+// CanMentionAll reports whether userID may use @here and @all.
+func (m *Membership) CanMentionAll(userID int64) bool {
+	return m.Kind == ConversationGroup && (m.MentionAll == MentionAllEveryone || IsManager(m.Role(userID)))
+}
+
+// CanViewEdits reports whether userID may see the edit history of a
+// message: its sender, either party in a direct conversation and group
+// admins.
+func (m *Membership) CanViewEdits(userID int64, message *Message) bool {
+	if !m.IsMember(userID) {
+		return false
+	}
+	return message.IsSentBy(userID) || m.Kind == ConversationDirect || IsManager(m.Role(userID))
+}
+
+// CanPin reports whether userID may pin and unpin messages: either party in
+// a direct conversation, admins in a group.
+func (m *Membership) CanPin(userID int64) bool {
+	if !m.IsMember(userID) {
+		return false
+	}
+	return m.Kind == ConversationDirect || IsManager(m.Role(userID))
+}
+
+func (m *Membership) UserIDs() []int64 {
+	ids := make([]int64, 0, len(m.Roles))
+	for id := range m.Roles {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// RoleRank orders roles so that a higher rank can manage a lower one.
+func RoleRank(role string) int {
+	switch role {
+	case RoleOwner:
+		return 2
+	case RoleAdmin:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func IsManager(role string) bool {
+	return role == RoleOwner || role == RoleAdmin
+}
+
+func DirectKey(userID, otherID int64) string {
+	if userID > otherID {
+		userID, otherID = otherID, userID
+	}
+	return strconv.FormatInt(userID, 10) + ":" + strconv.FormatInt(otherID, 10)
+}
+
+// ActivityID orders conversations by their latest message, falling back to
+// the conversation's own id. Both are time ordered.
+func (c *Conversation) ActivityID() int64 {
+	if c.LastMessageID.Valid {
+		return c.LastMessageID.Int64
+	}
+	return c.ConversationID
+}
+
+func (c *Conversation) ParticipantIDs() []int64 {
+	ids := make([]int64, 0, len(c.Participants))
+	for _, p := range c.Participants {
+		ids = append(ids, p.User.UserPid)
+	}
+	return ids
+}
+
+func (c *Conversation) Participant(userID int64) *Participant {
+	for _, p := range c.Participants {
+		if p.User.UserPid == userID {
+			return p
+		}
+	}
+	return nil
+}
+
+func (c *Conversation) MarshalJSON() ([]byte, error) {
+	conversation := map[string]any{
+		"conversationId": strconv.FormatInt(c.ConversationID, 10),
+		"kind":           c.Kind,
+		"participants":   c.Participants,
+		"lastMessage":    c.LastMessage,
+		"lastMessageAt":  c.LastMessageAt,
+		"createdAt":      c.CreatedAt,
+		"updatedAt":      c.UpdatedAt,
+		"version":        c.Version,
+		"mutedUntil":     c.MutedUntil,
+	}
+
+	settings := map[string]any{
+		"linkPreviews":    c.LinkPreviews,
+		"messageTimer":    MessageTimer(c.MessageTtl),
+		"allowForwarding": c.AllowForwarding,
+	}
+
+	if c.Kind == ConversationGroup {
+		conversation["title"] = c.Title
+		conversation["avatar"] = c.Avatar
+		conversation["description"] = c.Description
+		settings["mentionAll"] = c.MentionAll
+	}
+
+	conversation["settings"] = settings
+
+	if c.Participants == nil {
+		conversation["participants"] = []*Participant{}
+	}
+
+	if c.Unread != nil {
+		conversation["unreadCount"] = c.Unread.Unread
+		conversation["mentionCount"] = c.Unread.Mentions
+	}
+
+	return json.Marshal(conversation)
+}
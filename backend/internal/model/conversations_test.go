@@ -0,0 +1,225 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestDirectKey(t *testing.T) {
+	tests := []struct {
+		userID, otherID int64
+		want            string
+	}{
+		{1, 2, "1:2"},
+		{2, 1, "1:2"},
+		{10, 9, "9:10"},
+		{123456789012, 42, "42:123456789012"},
+	}
+
+	for _, tt := range tests {
+		if got := DirectKey(tt.userID, tt.otherID); got != tt.want {
+			t.Errorf("DirectKey(%d, %d) = %q, want %q", tt.userID, tt.otherID, got, tt.want)
+		}
+	}
+}
+
+func TestConversationActivityID(t *testing.T) {
+	tests := []struct {
+		name         string
+		conversation db.Conversation
+		want         int64
+	}{
+		{"no messages", db.Conversation{ConversationID: 5}, 5},
+		{"with messages", db.Conversation{ConversationID: 5, LastMessageID: pgtype.Int8{Int64: 9, Valid: true}}, 9},
+	}
+
+	for _, tt := range tests {
+		c := &Conversation{Conversation: tt.conversation}
+		if got := c.ActivityID(); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRoleRank(t *testing.T) {
+	tests := []struct {
+		role        string
+		wantRank    int
+		wantManager bool
+	}{
+		{RoleOwner, 2, true},
+		{RoleAdmin, 1, true},
+		{RoleMember, 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := RoleRank(tt.role); got != tt.wantRank {
+			t.Errorf("RoleRank(%q) = %d, want %d", tt.role, got, tt.wantRank)
+		}
+		if got := IsManager(tt.role); got != tt.wantManager {
+			t.Errorf("IsManager(%q) = %v, want %v", tt.role, got, tt.wantManager)
+		}
+	}
+}
+
+func TestMembership(t *testing.T) {
+	membership := &Membership{
+		Kind:  ConversationGroup,
+		Roles: map[int64]string{1: RoleOwner, 2: RoleMember},
+	}
+
+	tests := []struct {
+		userID     int64
+		wantMember bool
+		wantRole   string
+	}{
+		{1, true, RoleOwner},
+		{2, true, RoleMember},
+		{3, false, ""},
+	}
+
+	for _, tt := range tests {
+		if got := membership.IsMember(tt.userID); got != tt.wantMember {
+			t.Errorf("IsMember(%d) = %v, want %v", tt.userID, got, tt.wantMember)
+		}
+		if got := membership.Role(tt.userID); got != tt.wantRole {
+			t.Errorf("Role(%d) = %q, want %q", tt.userID, got, tt.wantRole)
+		}
+	}
+
+	if got := membership.UserIDs(); len(got) != 2 {
+		t.Errorf("UserIDs() = %v, want two ids", got)
+	}
+}
+
+func TestMembershipCanViewEdits(t *testing.T) {
+	message := &Message{Message: db.Message{SenderPid: pgtype.Int8{Int64: 2, Valid: true}}}
+
+	group := &Membership{
+		Kind:  ConversationGroup,
+		Roles: map[int64]string{1: RoleOwner, 2: RoleMember, 3: RoleMember},
+	}
+	direct := &Membership{
+		Kind:  ConversationDirect,
+		Roles: map[int64]string{2: RoleMember, 3: RoleMember},
+	}
+
+	tests := []struct {
+		name       string
+		membership *Membership
+		userID     int64
+		want       bool
+	}{
+		{"group sender", group, 2, true},
+		{"group member", group, 3, false},
+		{"group owner", group, 1, true},
+		{"direct sender", direct, 2, true},
+		{"direct recipient", direct, 3, true},
+		{"non-member", direct, 4, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.membership.CanViewEdits(tt.userID, message); got != tt.want {
+			t.Errorf("%s: CanViewEdits(%d) = %v, want %v", tt.name, tt.userID, got, tt.want)
+		}
+	}
+}
+
+func TestMembershipCanMentionAll(t *testing.T) {
+	adminsOnly := &Membership{
+		Kind:       ConversationGroup,
+		Roles:      map[int64]string{1: RoleOwner, 2: RoleAdmin, 3: RoleMember},
+		MentionAll: MentionAllAdmins,
+	}
+	everyone := &Membership{
+		Kind:       ConversationGroup,
+		Roles:      map[int64]string{1: RoleOwner, 3: RoleMember},
+		MentionAll: MentionAllEveryone,
+	}
+	direct := &Membership{
+		Kind:       ConversationDirect,
+		Roles:      map[int64]string{1: RoleMember, 3: RoleMember},
+		MentionAll: MentionAllEveryone,
+	}
+
+	tests := []struct {
+		name       string
+		membership *Membership
+		userID     int64
+		want       bool
+	}{
+		{"admins only, owner", adminsOnly, 1, true},
+		{"admins only, admin", adminsOnly, 2, true},
+		{"admins only, member", adminsOnly, 3, false},
+		{"everyone, owner", everyone, 1, true},
+		{"everyone, member", everyone, 3, true},
+		{"direct", direct, 1, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.membership.CanMentionAll(tt.userID); got != tt.want {
+			t.Errorf("%s: CanMentionAll(%d) = %v, want %v", tt.name, tt.userID, got, tt.want)
+		}
+	}
+}
+
+func TestMembershipCanPin(t *testing.T) {
+	group := &Membership{
+		Kind:  ConversationGroup,
+		Roles: map[int64]string{1: RoleOwner, 2: RoleAdmin, 3: RoleMember},
+	}
+	direct := &Membership{
+		Kind:  ConversationDirect,
+		Roles: map[int64]string{3: RoleMember, 4: RoleMember},
+	}
+
+	tests := []struct {
+		name       string
+		membership *Membership
+		userID     int64
+		want       bool
+	}{
+		{"group owner", group, 1, true},
+		{"group admin", group, 2, true},
+		{"group member", group, 3, false},
+		{"group non-member", group, 4, false},
+		{"direct participant", direct, 3, true},
+		{"direct other participant", direct, 4, true},
+		{"direct non-member", direct, 1, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.membership.CanPin(tt.userID); got != tt.want {
+			t.Errorf("%s: CanPin(%d) = %v, want %v", tt.name, tt.userID, got, tt.want)
+		}
+	}
+}
+
+func TestMessageTimer(t *testing.T) {
+	tests := []struct {
+		ttl  int32
+		want string
+	}{
+		{0, "off"},
+		{3600, "1h"},
+		{86400, "24h"},
+		{604800, "7d"},
+		{60, "off"},
+		{-1, "off"},
+	}
+
+	for _, tt := range tests {
+		if got := MessageTimer(tt.ttl); got != tt.want {
+			t.Errorf("MessageTimer(%d) = %q, want %q", tt.ttl, got, tt.want)
+		}
+	}
+
+	for name, ttl := range MessageTimers {
+		if got := MessageTimer(ttl); got != name {
+			t.Errorf("MessageTimer(MessageTimers[%q]) = %q", name, got)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// LinkPreview describes the first link in a message, as the linked page
+// describes itself.
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"imageUrl,omitempty"`
+	SiteName    string `json:"siteName,omitempty"`
+}
+
+// LinkPreviewUpdate is the payload of message.preview events. A nil
+// LinkPreview means the message's preview was removed.
+type LinkPreviewUpdate struct {
+	ConversationID int64
+	MessageID      int64
+	LinkPreview    *LinkPreview
+}
+
+func (u *LinkPreviewUpdate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"conversationId": strconv.FormatInt(u.ConversationID, 10),
+		"messageId":      strconv.FormatInt(u.MessageID, 10),
+		"linkPreview":    u.LinkPreview,
+	})
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MentionUser = "user"
+	MentionHere = "here"
+	MentionAll  = "all"
+)
+
+// Who may use @here and @all in a group.
+const (
+	MentionAllEveryone = "everyone"
+	MentionAllAdmins   = "admins"
+)
+
+// maxUsernameLength matches the limit usernames are validated against.
+const maxUsernameLength = 30
+
+// mentionRX finds @ followed by a username, as UsernameRX defines one. The @
+// must not follow a word character or another @, so email addresses and
+// "@@x" aren't mentions.
+var mentionRX = regexp.MustCompile(`(^|[^\w@])@(\w+)`)
+
+// Mention is an @mention in a message body. Offset and Length are in UTF-16
+// code units, the way JavaScript clients index strings. UserID is set for
+// user mentions once resolved against the conversation's members.
+type Mention struct {
+	Kind     string `json:"kind"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	UserID   int64  `json:"userId,string,omitempty"`
+	Username string `json:"-"`
+}
+
+// ParseMentions finds the @mentions in body. Usernames are lowercased,
+// since they are matched case-insensitively. @here and @all are only
+// recognised in groups.
+func ParseMentions(body string, kind string) []*Mention {
+	var mentions []*Mention
+
+	for _, match := range mentionRX.FindAllStringSubmatchIndex(body, -1) {
+		start, end := match[4]-1, match[5]
+		username := strings.ToLower(body[match[4]:match[5]])
+
+		if len(username) > maxUsernameLength {
+			continue
+		}
+
+		mention := &Mention{
+			Kind:     MentionUser,
+			Offset:   utf16Len(body[:start]),
+			Length:   utf16Len(body[start:end]),
+			Username: username,
+		}
+
+		if kind == ConversationGroup && (username == MentionHere || username == MentionAll) {
+			mention.Kind = username
+			mention.Username = ""
+		}
+
+		mentions = append(mentions, mention)
+	}
+
+	return mentions
+}
+
+// NewEntities encodes the mentions of a message for storage, or returns nil
+// if there are none.
+func NewEntities(mentions []*Mention) ([]byte, error) {
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(mentions)
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+
+		n++
+		if r >= 0x10000 {
+			n++
+		}
+	}
+	return n
+}
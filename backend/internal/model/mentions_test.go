@@ -0,0 +1,95 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	long := "a123456789b123456789c123456789d"
+
+	tests := []struct {
+		name string
+		body string
+		kind string
+		want []*Mention
+	}{
+		{
+			name: "none",
+			body: "hello there",
+			kind: ConversationGroup,
+		},
+		{
+			name: "user",
+			body: "hi @Alice_1!",
+			kind: ConversationGroup,
+			want: []*Mention{{Kind: MentionUser, Offset: 3, Length: 8, Username: "alice_1"}},
+		},
+		{
+			name: "start of body and repeated",
+			body: "@bob ping @bob",
+			kind: ConversationDirect,
+			want: []*Mention{
+				{Kind: MentionUser, Offset: 0, Length: 4, Username: "bob"},
+				{Kind: MentionUser, Offset: 10, Length: 4, Username: "bob"},
+			},
+		},
+		{
+			name: "here and all in a group",
+			body: "@here and (@ALL)",
+			kind: ConversationGroup,
+			want: []*Mention{
+				{Kind: MentionHere, Offset: 0, Length: 5},
+				{Kind: MentionAll, Offset: 11, Length: 4},
+			},
+		},
+		{
+			name: "here and all in a direct conversation",
+			body: "@here @all",
+			kind: ConversationDirect,
+			want: []*Mention{
+				{Kind: MentionUser, Offset: 0, Length: 5, Username: "here"},
+				{Kind: MentionUser, Offset: 6, Length: 4, Username: "all"},
+			},
+		},
+		{
+			name: "email address",
+			body: "mail me at carol@example.com",
+			kind: ConversationGroup,
+		},
+		{
+			name: "double at",
+			body: "@@dave",
+			kind: ConversationGroup,
+		},
+		{
+			name: "username too long",
+			body: "@" + long,
+			kind: ConversationGroup,
+		},
+		{
+			name: "utf-16 offsets",
+			body: "\U0001F600 é @erin",
+			kind: ConversationGroup,
+			want: []*Mention{{Kind: MentionUser, Offset: 5, Length: 5, Username: "erin"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseMentions(tt.body, tt.kind)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", values(got), values(tt.want))
+			}
+		})
+	}
+}
+
+// values dereferences mentions so failures print their fields.
+func values(mentions []*Mention) []Mention {
+	out := make([]Mention, 0, len(mentions))
+	for _, m := range mentions {
+		out = append(out, *m)
+	}
+	return out
+}
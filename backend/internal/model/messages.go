@@ -0,0 +1,270 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+)
+
+const (
+	MessageText   = "text"
+	MessageSystem = "system"
+	MessagePoll   = "poll"
+)
+
+const (
+	SystemGroupCreated         = "group.created"
+	SystemGroupRenamed         = "group.renamed"
+	SystemGroupUpdated         = "group.updated"
+	SystemMemberAdded          = "member.added"
+	SystemMemberRemoved        = "member.removed"
+	SystemMemberLeft           = "member.left"
+	SystemMemberPromoted       = "member.promoted"
+	SystemMemberDemoted        = "member.demoted"
+	SystemOwnershipTransferred = "ownership.transferred"
+	SystemMessagePinned        = "message.pinned"
+	SystemTimerChanged         = "timer.changed"
+)
+
+type Message struct {
+	db.Message
+	Reactions   []*ReactionSummary
+	Attachments []*Attachment
+	LinkPreview *LinkPreview
+	Poll        *Poll
+}
+
+// maxThreadParticipants is how many thread participants are listed on the
+// root message. The full count is always included.
+const maxThreadParticipants = 5
+
+const maxQuoteLength = 200
+
+// SystemEvent is stored as the body of system messages so clients can render
+// timeline events themselves.
+type SystemEvent struct {
+	Event     string   `json:"event"`
+	ActorID   string   `json:"actorId"`
+	UserIDs   []string `json:"userIds,omitempty"`
+	Title     string   `json:"title,omitempty"`
+	MessageID string   `json:"messageId,omitempty"`
+	Timer     string   `json:"timer,omitempty"`
+}
+
+func NewSystemEvent(event string, actorID int64, userIDs ...int64) *SystemEvent {
+	e := &SystemEvent{
+		Event:   event,
+		ActorID: strconv.FormatInt(actorID, 10),
+	}
+
+	for _, id := range userIDs {
+		e.UserIDs = append(e.UserIDs, strconv.FormatInt(id, 10))
+	}
+
+	return e
+}
+
+func NewSystemMessage(conversationID int64, event *SystemEvent) (*Message, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Message: db.Message{
+			ConversationID: conversationID,
+			Kind:           MessageSystem,
+			Body:           string(body),
+		},
+	}, nil
+}
+
+const (
+	DeleteScopeMe       = "me"
+	DeleteScopeEveryone = "everyone"
+	DeleteScopeExpired  = "expired"
+)
+
+// MessageDeletedEvent is the payload of message.deleted events. It has the
+// same shape whether the message was hidden, removed for everyone or expired.
+type MessageDeletedEvent struct {
+	ConversationID int64
+	MessageID      int64
+	Scope          string
+}
+
+func (e *MessageDeletedEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"conversationId": strconv.FormatInt(e.ConversationID, 10),
+		"messageId":      strconv.FormatInt(e.MessageID, 10),
+		"scope":          e.Scope,
+	})
+}
+
+// NewQuote snapshots the parent of a quote-reply, so the preview stays
+// readable even if the parent scrolls out of the loaded history.
+func NewQuote(parent *Message) ([]byte, error) {
+	body := []rune(parent.Body)
+	if len(body) > maxQuoteLength {
+		body = body[:maxQuoteLength]
+	}
+
+	quote := map[string]any{
+		"messageId": strconv.FormatInt(parent.MessageID, 10),
+		"senderId":  nil,
+		"kind":      parent.Kind,
+		"body":      string(body),
+		"createdAt": parent.CreatedAt,
+	}
+
+	if parent.SenderPid.Valid {
+		quote["senderId"] = strconv.FormatInt(parent.SenderPid.Int64, 10)
+	}
+
+	return json.Marshal(quote)
+}
+
+// NewForward records where a forwarded message came from. A message that
+// was itself forwarded keeps its origin. The original sender is left out
+// when showSender is false.
+func NewForward(source *Message, showSender bool) ([]byte, error) {
+	if source.Forward != nil {
+		return source.Forward, nil
+	}
+
+	forward := map[string]any{
+		"senderId": nil,
+	}
+
+	if showSender && source.SenderPid.Valid {
+		forward["senderId"] = strconv.FormatInt(source.SenderPid.Int64, 10)
+	}
+
+	return json.Marshal(forward)
+}
+
+func (m *Message) IsThreadRoot() bool {
+	return !m.ThreadID.Valid
+}
+
+func (m *Message) IsDeleted() bool {
+	return m.DeletedAt.Valid
+}
+
+func (m *Message) IsSentBy(userID int64) bool {
+	return m.SenderPid.Valid && m.SenderPid.Int64 == userID
+}
+
+func (m *Message) MarshalJSON() ([]byte, error) {
+	message := map[string]any{
+		"messageId":      strconv.FormatInt(m.MessageID, 10),
+		"conversationId": strconv.FormatInt(m.ConversationID, 10),
+		"senderId":       nil,
+		"kind":           m.Kind,
+		"body":           m.Body,
+		"createdAt":      m.CreatedAt,
+		"editedAt":       m.EditedAt,
+		"version":        m.Version,
+	}
+
+	if m.SenderPid.Valid {
+		message["senderId"] = strconv.FormatInt(m.SenderPid.Int64, 10)
+	}
+
+	if m.Kind == MessageSystem {
+		message["body"] = json.RawMessage(m.Body)
+	}
+
+	if m.ExpiresAt.Valid {
+		message["expiresAt"] = m.ExpiresAt
+	}
+
+	if m.ParentID.Valid {
+		message["parentId"] = strconv.FormatInt(m.ParentID.Int64, 10)
+	}
+
+	if m.ThreadID.Valid {
+		message["threadId"] = strconv.FormatInt(m.ThreadID.Int64, 10)
+	}
+
+	if m.Entities != nil {
+		message["mentions"] = json.RawMessage(m.Entities)
+	}
+
+	if m.Quote != nil {
+		message["quote"] = json.RawMessage(m.Quote)
+	}
+
+	if m.Forward != nil {
+		message["forwarded"] = json.RawMessage(m.Forward)
+	}
+
+	if m.ReplyCount > 0 {
+		participants := m.ThreadParticipantIds
+		if len(participants) > maxThreadParticipants {
+			participants = participants[len(participants)-maxThreadParticipants:]
+		}
+
+		participantIDs := make([]string, 0, len(participants))
+		for _, id := range participants {
+			participantIDs = append(participantIDs, strconv.FormatInt(id, 10))
+		}
+
+		message["thread"] = map[string]any{
+			"replyCount":       m.ReplyCount,
+			"lastReplyAt":      m.LastReplyAt,
+			"participantIds":   participantIDs,
+			"participantCount": len(m.ThreadParticipantIds),
+		}
+	}
+
+	// Reactions depend on the viewer, so they are left out of payloads
+	// that are broadcast to every participant.
+	if m.Reactions != nil {
+		message["reactions"] = m.Reactions
+	}
+
+	if m.Attachments != nil {
+		message["attachments"] = m.Attachments
+	}
+
+	if m.Poll != nil {
+		message["poll"] = m.Poll
+	}
+
+	if m.LinkPreview != nil {
+		message["linkPreview"] = m.LinkPreview
+	}
+
+	if m.IsDeleted() {
+		message["body"] = nil
+		message["deletedAt"] = m.DeletedAt
+		message["deletedBy"] = nil
+		if m.DeleterPid.Valid {
+			message["deletedBy"] = strconv.FormatInt(m.DeleterPid.Int64, 10)
+		}
+	}
+
+	return json.Marshal(message)
+}
+
+type MessageEdit struct {
+	db.MessageEdit
+}
+
+func (e *MessageEdit) MarshalJSON() ([]byte, error) {
+	edit := map[string]any{
+		"messageId":  strconv.FormatInt(e.MessageID, 10),
+		"version":    e.Version,
+		"body":       e.Body,
+		"editorId":   nil,
+		"replacedAt": e.ReplacedAt,
+	}
+
+	if e.EditorPid.Valid {
+		edit["editorId"] = strconv.FormatInt(e.EditorPid.Int64, 10)
+	}
+
+	return json.Marshal(edit)
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestMessageDeletedEvent(t *testing.T) {
+	scopes := []string{DeleteScopeMe, DeleteScopeEveryone, DeleteScopeExpired}
+
+	for _, scope := range scopes {
+		event := &MessageDeletedEvent{ConversationID: 7, MessageID: 42, Scope: scope}
+
+		js, err := json.Marshal(event)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", scope, err)
+		}
+
+		var got map[string]string
+		if err := json.Unmarshal(js, &got); err != nil {
+			t.Fatalf("%s: unexpected error: %v", scope, err)
+		}
+
+		want := map[string]string{"conversationId": "7", "messageId": "42", "scope": scope}
+		if len(got) != len(want) {
+			t.Errorf("%s: payload = %v, want %v", scope, got, want)
+			continue
+		}
+		for key, value := range want {
+			if got[key] != value {
+				t.Errorf("%s: %s = %q, want %q", scope, key, got[key], value)
+			}
+		}
+	}
+}
+
+func TestNewQuote(t *testing.T) {
+	createdAt := pgtype.Timestamptz{Time: time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC), Valid: true}
+
+	tests := []struct {
+		name       string
+		parent     db.Message
+		wantSender any
+		wantBody   string
+	}{
+		{
+			name:       "short body",
+			parent:     db.Message{MessageID: 42, SenderPid: pgtype.Int8{Int64: 7, Valid: true}, Kind: MessageText, Body: "hello"},
+			wantSender: "7",
+			wantBody:   "hello",
+		},
+		{
+			name:       "long body is truncated by character",
+			parent:     db.Message{MessageID: 42, SenderPid: pgtype.Int8{Int64: 7, Valid: true}, Kind: MessageText, Body: strings.Repeat("é", maxQuoteLength+10)},
+			wantSender: "7",
+			wantBody:   strings.Repeat("é", maxQuoteLength),
+		},
+		{
+			name:       "deleted sender",
+			parent:     db.Message{MessageID: 42, Kind: MessageText, Body: "hello"},
+			wantSender: nil,
+			wantBody:   "hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.parent.CreatedAt = createdAt
+
+			js, err := NewQuote(&Message{Message: tt.parent})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal(js, &got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got["messageId"] != "42" {
+				t.Errorf("messageId = %v, want 42", got["messageId"])
+			}
+			if got["senderId"] != tt.wantSender {
+				t.Errorf("senderId = %v, want %v", got["senderId"], tt.wantSender)
+			}
+			if got["kind"] != MessageText {
+				t.Errorf("kind = %v, want %v", got["kind"], MessageText)
+			}
+			if got["body"] != tt.wantBody {
+				t.Errorf("body = %q, want %q", got["body"], tt.wantBody)
+			}
+			if got["createdAt"] != "2026-03-14T15:09:26Z" {
+				t.Errorf("createdAt = %v, want 2026-03-14T15:09:26Z", got["createdAt"])
+			}
+		})
+	}
+}
+
+func TestNewForward(t *testing.T) {
+	sender := pgtype.Int8{Int64: 42, Valid: true}
+
+	tests := []struct {
+		name       string
+		source     db.Message
+		showSender bool
+		want       string
+	}{
+		{"sender shown", db.Message{SenderPid: sender}, true, `{"senderId":"42"}`},
+		{"sender hidden", db.Message{SenderPid: sender}, false, `{"senderId":null}`},
+		{"deleted sender", db.Message{}, true, `{"senderId":null}`},
+		{
+			name:       "forward of a forward keeps the first origin",
+			source:     db.Message{SenderPid: sender, Forward: []byte(`{"senderId":"7"}`)},
+			showSender: true,
+			want:       `{"senderId":"7"}`,
+		},
+		{
+			name:   "hidden origin stays hidden",
+			source: db.Message{SenderPid: sender, Forward: []byte(`{"senderId":null}`)},
+			want:   `{"senderId":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := NewForward(&Message{Message: tt.source}, tt.showSender)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	NotificationContactRequest  = "contact.request"
+	NotificationContactAccepted = "contact.accepted"
+	NotificationThreadReply     = "thread.reply"
+	NotificationMention         = "message.mention"
+)
+
+type Notification struct {
+	db.Notification
+}
+
+func NewNotification(userID, actorID int64, kind string, payload any) (*Notification, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notification{
+		Notification: db.Notification{
+			UserPid:  userID,
+			ActorPid: pgtype.Int8{Int64: actorID, Valid: actorID != 0},
+			Kind:     kind,
+			Payload:  data,
+		},
+	}, nil
+}
+
+func (n *Notification) MarshalJSON() ([]byte, error) {
+	notification := map[string]any{
+		"notificationId": strconv.FormatInt(n.NotificationID, 10),
+		"kind":           n.Kind,
+		"actorId":        nil,
+		"payload":        json.RawMessage(n.Payload),
+		"readAt":         n.ReadAt,
+		"createdAt":      n.CreatedAt,
+	}
+
+	if n.ActorPid.Valid {
+		notification["actorId"] = strconv.FormatInt(n.ActorPid.Int64, 10)
+	}
+
+	return json.Marshal(notification)
+}
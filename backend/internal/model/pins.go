@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Pin is a message pinned to the top of a conversation.
+type Pin struct {
+	Message  *Message
+	PinnerID pgtype.Int8
+	PinnedAt pgtype.Timestamptz
+}
+
+func (p *Pin) MarshalJSON() ([]byte, error) {
+	pin := map[string]any{
+		"message":  p.Message,
+		"pinnedBy": nil,
+		"pinnedAt": p.PinnedAt,
+	}
+
+	if p.PinnerID.Valid {
+		pin["pinnedBy"] = strconv.FormatInt(p.PinnerID.Int64, 10)
+	}
+
+	return json.Marshal(pin)
+}
+
+// PinEvent is the payload of pin.added and pin.removed events. UserID is
+// whoever pinned or unpinned the message, and zero when it was unpinned
+// because its disappearing timer ran out.
+type PinEvent struct {
+	ConversationID int64
+	MessageID      int64
+	UserID         int64
+}
+
+func (e *PinEvent) MarshalJSON() ([]byte, error) {
+	event := map[string]any{
+		"conversationId": strconv.FormatInt(e.ConversationID, 10),
+		"messageId":      strconv.FormatInt(e.MessageID, 10),
+		"userId":         nil,
+	}
+
+	if e.UserID != 0 {
+		event["userId"] = strconv.FormatInt(e.UserID, 10)
+	}
+
+	return json.Marshal(event)
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPinEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *PinEvent
+		want  string
+	}{
+		{
+			name:  "by a user",
+			event: &PinEvent{ConversationID: 1, MessageID: 2, UserID: 3},
+			want:  `{"conversationId":"1","messageId":"2","userId":"3"}`,
+		},
+		{
+			name:  "by the timer",
+			event: &PinEvent{ConversationID: 1, MessageID: 2},
+			want:  `{"conversationId":"1","messageId":"2","userId":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.event)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
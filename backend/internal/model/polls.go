@@ -0,0 +1,106 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+)
+
+// Poll is the question and options of a poll message along with its
+// tallies. Votes are the counts per option, in the order of Options.
+// Voted holds the viewer's own choices and VoterIDs who picked each option;
+// both are only set when loaded for a viewer, and VoterIDs never for
+// anonymous polls.
+type Poll struct {
+	db.Poll
+	Votes    []int64
+	Voters   int64
+	Voted    []int32
+	VoterIDs [][]int64
+}
+
+// IsClosed reports whether the poll has been closed or its close time has
+// passed.
+func (p *Poll) IsClosed() bool {
+	return p.ClosedAt.Valid || (p.ClosesAt.Valid && !p.ClosesAt.Time.After(time.Now()))
+}
+
+// Tallies copies the poll without anything that depends on the viewer, for
+// payloads that are broadcast to every participant.
+func (p *Poll) Tallies() *Poll {
+	return &Poll{
+		Poll:   p.Poll,
+		Votes:  p.Votes,
+		Voters: p.Voters,
+	}
+}
+
+func (p *Poll) MarshalJSON() ([]byte, error) {
+	options := make([]map[string]any, 0, len(p.Options))
+	for i, text := range p.Options {
+		option := map[string]any{
+			"optionId": i,
+			"text":     text,
+			"votes":    int64(0),
+		}
+
+		if i < len(p.Votes) {
+			option["votes"] = p.Votes[i]
+		}
+
+		if i < len(p.VoterIDs) {
+			voterIDs := make([]string, 0, len(p.VoterIDs[i]))
+			for _, id := range p.VoterIDs[i] {
+				voterIDs = append(voterIDs, strconv.FormatInt(id, 10))
+			}
+			option["voterIds"] = voterIDs
+		}
+
+		options = append(options, option)
+	}
+
+	poll := map[string]any{
+		"question":       p.Question,
+		"options":        options,
+		"multipleChoice": p.MultipleChoice,
+		"anonymous":      p.Anonymous,
+		"closesAt":       p.ClosesAt,
+		"closedAt":       p.ClosedAt,
+		"closed":         p.IsClosed(),
+		"voters":         p.Voters,
+	}
+
+	if p.Voted != nil {
+		poll["voted"] = p.Voted
+	}
+
+	return json.Marshal(poll)
+}
+
+// PollEvent is the payload of poll.updated and poll.closed events. UserID
+// and Options name the vote that changed the tallies, and are left out for
+// anonymous polls and when a poll closes.
+type PollEvent struct {
+	ConversationID int64
+	MessageID      int64
+	Poll           *Poll
+	UserID         int64
+	Options        []int32
+}
+
+func (e *PollEvent) MarshalJSON() ([]byte, error) {
+	event := map[string]any{
+		"conversationId": strconv.FormatInt(e.ConversationID, 10),
+		"messageId":      strconv.FormatInt(e.MessageID, 10),
+		"poll":           e.Poll,
+	}
+
+	if e.UserID != 0 && !e.Poll.Anonymous {
+		event["userId"] = strconv.FormatInt(e.UserID, 10)
+		event["options"] = e.Options
+	}
+
+	return json.Marshal(event)
+}
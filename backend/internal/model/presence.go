@@ -0,0 +1,33 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Presence is whether a user is connected right now and when they were last
+// connected. Hidden is set when their privacy settings keep both from the
+// viewer.
+type Presence struct {
+	UserID   int64
+	Online   bool
+	LastSeen pgtype.Timestamptz
+	Hidden   bool
+}
+
+func (p *Presence) MarshalJSON() ([]byte, error) {
+	presence := map[string]any{
+		"userId":   strconv.FormatInt(p.UserID, 10),
+		"online":   p.Online,
+		"lastSeen": p.LastSeen,
+	}
+
+	if p.Hidden {
+		presence["online"] = nil
+		presence["lastSeen"] = nil
+	}
+
+	return json.Marshal(presence)
+}
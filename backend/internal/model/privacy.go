@@ -0,0 +1,122 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	VisibilityEveryone = "everyone"
+	VisibilityContacts = "contacts"
+	VisibilityNobody   = "nobody"
+)
+
+type Relationship uint8
+
+const (
+	RelationNone Relationship = iota
+	RelationContact
+	RelationSelf
+	// RelationBlocking means the viewer blocked the user, RelationBlockedBy
+	// that the user blocked the viewer.
+	RelationBlocking
+	RelationBlockedBy
+)
+
+func (r Relationship) IsBlocked() bool {
+	return r == RelationBlocking || r == RelationBlockedBy
+}
+
+type PrivacySettings struct {
+	db.PrivacySetting
+}
+
+func DefaultPrivacySettings(userID int64) *PrivacySettings {
+	return &PrivacySettings{
+		PrivacySetting: db.PrivacySetting{
+			UserPid:              userID,
+			BioVisibility:        VisibilityEveryone,
+			LastSeenVisibility:   VisibilityContacts,
+			ProfilePicVisibility: VisibilityEveryone,
+			EmailVisibility:      VisibilityNobody,
+			WhoCanMessage:        VisibilityEveryone,
+			Searchable:           true,
+			ReadReceipts:         true,
+			ForwardAttribution:   true,
+		},
+	}
+}
+
+func (p *PrivacySettings) Allows(visibility string, rel Relationship) bool {
+	switch {
+	case rel == RelationSelf:
+		return true
+	case rel.IsBlocked():
+		return false
+	case visibility == VisibilityEveryone:
+		return true
+	case visibility == VisibilityContacts:
+		return rel == RelationContact
+	default:
+		return false
+	}
+}
+
+func (p *PrivacySettings) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"userId":               strconv.FormatInt(p.UserPid, 10),
+		"bioVisibility":        p.BioVisibility,
+		"lastSeenVisibility":   p.LastSeenVisibility,
+		"profilePicVisibility": p.ProfilePicVisibility,
+		"emailVisibility":      p.EmailVisibility,
+		"whoCanMessage":        p.WhoCanMessage,
+		"searchable":           p.Searchable,
+		"readReceipts":         p.ReadReceipts,
+		"forwardAttribution":   p.ForwardAttribution,
+		"updatedAt":            p.UpdatedAt,
+		"version":              p.Version,
+	})
+}
+
+func (p *PrivacySettings) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		UserID               string             `json:"userId"`
+		BioVisibility        string             `json:"bioVisibility"`
+		LastSeenVisibility   string             `json:"lastSeenVisibility"`
+		ProfilePicVisibility string             `json:"profilePicVisibility"`
+		EmailVisibility      string             `json:"emailVisibility"`
+		WhoCanMessage        string             `json:"whoCanMessage"`
+		Searchable           bool               `json:"searchable"`
+		ReadReceipts         *bool              `json:"readReceipts"`
+		ForwardAttribution   *bool              `json:"forwardAttribution"`
+		UpdatedAt            pgtype.Timestamptz `json:"updatedAt"`
+		Version              int32              `json:"version"`
+	}
+
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	userID, err := strconv.ParseInt(temp.UserID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	p.UserPid = userID
+	p.BioVisibility = temp.BioVisibility
+	p.LastSeenVisibility = temp.LastSeenVisibility
+	p.ProfilePicVisibility = temp.ProfilePicVisibility
+	p.EmailVisibility = temp.EmailVisibility
+	p.WhoCanMessage = temp.WhoCanMessage
+	p.Searchable = temp.Searchable
+	// Settings cached before read receipts existed leave the field out.
+	p.ReadReceipts = temp.ReadReceipts == nil || *temp.ReadReceipts
+	p.ForwardAttribution = temp.ForwardAttribution == nil || *temp.ForwardAttribution
+	p.UpdatedAt = temp.UpdatedAt
+	p.Version = temp.Version
+
+	return nil
+}
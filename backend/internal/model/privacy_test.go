@@ -0,0 +1,57 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPrivacySettingsAllows(t *testing.T) {
+	settings := DefaultPrivacySettings(1)
+
+	tests := []struct {
+		visibility string
+		rel        Relationship
+		want       bool
+	}{
+		{VisibilityEveryone, RelationNone, true},
+		{VisibilityEveryone, RelationContact, true},
+		{VisibilityEveryone, RelationBlocking, false},
+		{VisibilityEveryone, RelationBlockedBy, false},
+		{VisibilityContacts, RelationNone, false},
+		{VisibilityContacts, RelationContact, true},
+		{VisibilityNobody, RelationContact, false},
+		{VisibilityNobody, RelationSelf, true},
+		{"unknown", RelationContact, false},
+	}
+
+	for _, tt := range tests {
+		if got := settings.Allows(tt.visibility, tt.rel); got != tt.want {
+			t.Errorf("Allows(%q, %d) = %v, want %v", tt.visibility, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestPrivacySettingsUnmarshalDefaults(t *testing.T) {
+	// Settings cached before read receipts and forwarding existed.
+	var settings PrivacySettings
+	if err := json.Unmarshal([]byte(`{"userId":"1","whoCanMessage":"contacts"}`), &settings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !settings.ReadReceipts || !settings.ForwardAttribution {
+		t.Errorf("got readReceipts %v and forwardAttribution %v, want both true", settings.ReadReceipts, settings.ForwardAttribution)
+	}
+
+	data, err := json.Marshal(&PrivacySettings{PrivacySetting: DefaultPrivacySettings(1).PrivacySetting})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var roundTrip PrivacySettings
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if roundTrip.PrivacySetting != DefaultPrivacySettings(1).PrivacySetting {
+		t.Errorf("got %+v after a round trip", roundTrip.PrivacySetting)
+	}
+}
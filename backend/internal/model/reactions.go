@@ -0,0 +1,31 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// ReactionSummary aggregates the reactions of one emoji on a message from
+// the point of view of a single viewer.
+type ReactionSummary struct {
+	Emoji   string `json:"emoji"`
+	Count   int64  `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
+
+// ReactionEvent is the payload of incremental reaction events.
+type ReactionEvent struct {
+	ConversationID int64
+	MessageID      int64
+	UserID         int64
+	Emoji          string
+}
+
+func (e *ReactionEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"conversationId": strconv.FormatInt(e.ConversationID, 10),
+		"messageId":      strconv.FormatInt(e.MessageID, 10),
+		"userId":         strconv.FormatInt(e.UserID, 10),
+		"emoji":          e.Emoji,
+	})
+}
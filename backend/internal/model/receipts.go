@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Receipt holds a participant's delivery and read watermarks: every message
+// with an id up to the watermark counts as delivered or read. LastReadID is
+// null when the participant doesn't share read receipts with the viewer.
+type Receipt struct {
+	ConversationID  int64
+	UserID          int64
+	LastDeliveredID int64
+	LastReadID      pgtype.Int8
+}
+
+// Redacted returns the receipt as other participants may see it.
+func (r *Receipt) Redacted(shareRead bool) *Receipt {
+	if shareRead {
+		return r
+	}
+
+	redacted := *r
+	redacted.LastReadID = pgtype.Int8{}
+
+	return &redacted
+}
+
+func (r *Receipt) MarshalJSON() ([]byte, error) {
+	receipt := map[string]any{
+		"conversationId":  strconv.FormatInt(r.ConversationID, 10),
+		"userId":          strconv.FormatInt(r.UserID, 10),
+		"lastDeliveredId": strconv.FormatInt(r.LastDeliveredID, 10),
+		"lastReadId":      nil,
+	}
+
+	if r.LastReadID.Valid {
+		receipt["lastReadId"] = strconv.FormatInt(r.LastReadID.Int64, 10)
+	}
+
+	return json.Marshal(receipt)
+}
+
+// MessageReceipts answers who a message has reached, computed by comparing
+// its id against the other participants' watermarks.
+type MessageReceipts struct {
+	SeenBy      []*User `json:"seenBy"`
+	DeliveredTo []*User `json:"deliveredTo"`
+}
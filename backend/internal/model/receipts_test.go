@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestReceiptRedacted(t *testing.T) {
+	receipt := &Receipt{
+		ConversationID:  7,
+		UserID:          2,
+		LastDeliveredID: 50,
+		LastReadID:      pgtype.Int8{Int64: 40, Valid: true},
+	}
+
+	tests := []struct {
+		name       string
+		shareRead  bool
+		wantReadID any
+	}{
+		{"shared", true, "40"},
+		{"hidden", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			js, err := json.Marshal(receipt.Redacted(tt.shareRead))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal(js, &got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got["lastDeliveredId"] != "50" {
+				t.Errorf("lastDeliveredId = %v, want 50", got["lastDeliveredId"])
+			}
+			if got["lastReadId"] != tt.wantReadID {
+				t.Errorf("lastReadId = %v, want %v", got["lastReadId"], tt.wantReadID)
+			}
+		})
+	}
+
+	if !receipt.LastReadID.Valid {
+		t.Fatal("Redacted modified the original receipt")
+	}
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/RickinShah/BuzzChat/internal/db"
+)
+
+const (
+	ScheduledPending = "pending"
+	ScheduledSending = "sending"
+	ScheduledFailed  = "failed"
+)
+
+// ScheduledMessage is a message waiting to be sent at SendAt. Only its
+// sender can see it. Error explains why sending failed.
+type ScheduledMessage struct {
+	db.ScheduledMessage
+}
+
+func (m *ScheduledMessage) MarshalJSON() ([]byte, error) {
+	message := map[string]any{
+		"scheduledId":    strconv.FormatInt(m.ScheduledID, 10),
+		"conversationId": strconv.FormatInt(m.ConversationID, 10),
+		"body":           m.Body,
+		"sendAt":         m.SendAt,
+		"status":         m.Status,
+		"createdAt":      m.CreatedAt,
+		"version":        m.Version,
+	}
+
+	if m.Error.Valid {
+		message["error"] = m.Error.String
+	}
+
+	return json.Marshal(message)
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// UnreadCount is how many messages of a conversation a user hasn't read and
+// how many of those mention them.
+type UnreadCount struct {
+	ConversationID int64
+	Unread         int64
+	Mentions       int64
+}
+
+// Badge totals the unread counts of all of a user's conversations.
+type Badge struct {
+	Unread   int64 `json:"unreadCount"`
+	Mentions int64 `json:"mentionCount"`
+}
+
+// UnreadUpdate is the payload of unread.updated events.
+type UnreadUpdate struct {
+	UserID int64
+	Count  *UnreadCount
+	Badge  *Badge
+}
+
+func (u *UnreadUpdate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"conversationId": strconv.FormatInt(u.Count.ConversationID, 10),
+		"unreadCount":    u.Count.Unread,
+		"mentionCount":   u.Count.Mentions,
+		"badge":          u.Badge,
+	})
+}
@@ -21,7 +21,9 @@ const (
 
 type User struct {
 	db.User
-	marshalType MarshalType
+	marshalType  MarshalType
+	relationship Relationship
+	privacy      *PrivacySettings
 }
 
 func NewUser(user *db.User) *User {
@@ -45,12 +47,22 @@ func (u *User) MarshalJSON() ([]byte, error) {
 	if u.marshalType >= Minimal {
 		user["userId"] = strconv.FormatInt(u.UserPid, 10)
 		user["username"] = u.Username
-		user["profilePic"] = u.ProfilePic
 		user["name"] = u.Name
+		if u.visible(func(p *PrivacySettings) string { return p.ProfilePicVisibility }) {
+			user["profilePic"] = u.ProfilePic
+		}
 	}
 
 	if u.marshalType >= Frontend {
-		user["bio"] = u.Bio
+		if u.visible(func(p *PrivacySettings) string { return p.BioVisibility }) {
+			user["bio"] = u.Bio
+		}
+		if u.privacy != nil && u.visible(func(p *PrivacySettings) string { return p.EmailVisibility }) {
+			user["email"] = u.Email
+		}
+		if u.privacy != nil && u.marshalType == Frontend {
+			user["blocked"] = u.relationship == RelationBlocking
+		}
 	}
 
 	if u.marshalType >= Self {
@@ -110,3 +122,17 @@ func (u *User) UnmarshalJSON(data []byte) error {
 func (u *User) SetMarshalType(mt MarshalType) {
 	u.marshalType = mt
 }
+
+// SetViewer makes the Minimal and Frontend views honor the user's privacy
+// settings for someone with the given relationship to them.
+func (u *User) SetViewer(rel Relationship, privacy *PrivacySettings) {
+	u.relationship = rel
+	u.privacy = privacy
+}
+
+func (u *User) visible(field func(*PrivacySettings) string) bool {
+	if u.privacy == nil || u.marshalType >= Self {
+		return true
+	}
+	return u.privacy.Allows(field(u.privacy), u.relationship)
+}
@@ -0,0 +1,68 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+)
+
+// Deliver hands a frame received from the bus to the local connections of
+// a user.
+type Deliver func(userID int64, frame []byte)
+
+// Bus fans events out across API instances. Every instance subscribes to the
+// users that hold a connection to it and delivers what it receives locally.
+type Bus interface {
+	Publish(ctx context.Context, userIDs []int64, frame []byte) error
+	Subscribe(ctx context.Context, userID int64) error
+	Unsubscribe(ctx context.Context, userID int64) error
+	Listen(ctx context.Context, deliver Deliver) error
+}
+
+// MemoryBus delivers events within a single process. It is meant for single
+// node deployments and tests.
+type MemoryBus struct {
+	mu      sync.RWMutex
+	deliver Deliver
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{}
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, userIDs []int64, frame []byte) error {
+	b.mu.RLock()
+	deliver := b.deliver
+	b.mu.RUnlock()
+
+	if deliver == nil {
+		return nil
+	}
+
+	for _, userID := range userIDs {
+		deliver(userID, frame)
+	}
+
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(ctx context.Context, userID int64) error {
+	return nil
+}
+
+func (b *MemoryBus) Unsubscribe(ctx context.Context, userID int64) error {
+	return nil
+}
+
+func (b *MemoryBus) Listen(ctx context.Context, deliver Deliver) error {
+	b.mu.Lock()
+	b.deliver = deliver
+	b.mu.Unlock()
+
+	<-ctx.Done()
+
+	b.mu.Lock()
+	b.deliver = nil
+	b.mu.Unlock()
+
+	return ctx.Err()
+}
@@ -0,0 +1,161 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/time/rate"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = (pongWait * 9) / 10
+	maxMessageSize = 8192
+	sendBufferSize = 64
+
+	// Frames other than ping are rate limited per connection.
+	frameRate  = 2
+	frameBurst = 5
+)
+
+// Handler is called for every frame a client sends other than ping.
+type Handler func(c *Client, event *Event)
+
+type Client struct {
+	UserID  int64
+	hub     *Hub
+	conn    *websocket.Conn
+	send    chan []byte
+	limiter *rate.Limiter
+}
+
+func NewClient(hub *Hub, conn *websocket.Conn, userID int64) *Client {
+	return &Client{
+		UserID:  userID,
+		hub:     hub,
+		conn:    conn,
+		send:    make(chan []byte, sendBufferSize),
+		limiter: rate.NewLimiter(frameRate, frameBurst),
+	}
+}
+
+// enqueue never blocks the hub. A client that can't keep up is disconnected
+// and is expected to reconnect and catch up over REST.
+func (c *Client) enqueue(frame []byte) {
+	select {
+	case c.send <- frame:
+	default:
+		c.conn.Close()
+	}
+}
+
+func (c *Client) Send(event *Event) error {
+	frame, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	c.hub.mu.RLock()
+	defer c.hub.mu.RUnlock()
+
+	if _, ok := c.hub.clients[c.UserID][c]; ok {
+		c.enqueue(frame)
+	}
+
+	return nil
+}
+
+// Run registers the client and serves it until the connection closes. It
+// blocks, so anything after it runs once the client is gone.
+func (c *Client) Run(handler Handler) error {
+	if err := c.hub.Register(c); err != nil {
+		c.conn.Close()
+		return err
+	}
+
+	go c.writePump()
+	c.readPump(handler)
+
+	return nil
+}
+
+func (c *Client) readPump(handler Handler) {
+	defer func() {
+		c.hub.Unregister(c)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var event Event
+		if err := c.conn.ReadJSON(&event); err != nil {
+			var syntaxError *json.SyntaxError
+			var unmarshalTypeError *json.UnmarshalTypeError
+			if errors.As(err, &syntaxError) || errors.As(err, &unmarshalTypeError) {
+				c.SendError("malformed frame")
+				continue
+			}
+			return
+		}
+
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		if event.Type == EventPing {
+			c.Send(&Event{Type: EventPong})
+			continue
+		}
+
+		if !c.limiter.Allow() {
+			c.SendError("rate limit exceeded")
+			continue
+		}
+
+		if handler != nil {
+			handler(c, &event)
+		}
+	}
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case frame, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+
+			if err := c.conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) SendError(message string) {
+	event, err := NewEvent(EventError, map[string]string{"error": message})
+	if err != nil {
+		return
+	}
+	c.Send(event)
+}
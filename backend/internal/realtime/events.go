@@ -0,0 +1,49 @@
+package realtime
+
+import "encoding/json"
+
+const (
+	EventMessageCreated      = "message.created"
+	EventMessageUpdated      = "message.updated"
+	EventMessageDeleted      = "message.deleted"
+	EventMessagePreview      = "message.preview"
+	EventConversationUpdated = "conversation.updated"
+	EventReactionAdded       = "reaction.added"
+	EventReactionRemoved     = "reaction.removed"
+	EventPinAdded            = "pin.added"
+	EventPinRemoved          = "pin.removed"
+	EventScheduledSent       = "scheduled.sent"
+	EventScheduledFailed     = "scheduled.failed"
+	EventScheduledCancelled  = "scheduled.cancelled"
+	EventPollUpdated         = "poll.updated"
+	EventPollClosed          = "poll.closed"
+	EventReceiptUpdated      = "receipt.updated"
+	EventTypingStart         = "typing.start"
+	EventTypingStop          = "typing.stop"
+	EventPresenceUpdated     = "presence.updated"
+	EventUnreadUpdated       = "unread.updated"
+	EventAttachmentProcessed = "attachment.processed"
+	EventPing                = "ping"
+	EventPong                = "pong"
+	EventError               = "error"
+)
+
+// Event is the JSON frame exchanged over a socket in both directions.
+type Event struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+func NewEvent(eventType string, data any) (*Event, error) {
+	event := &Event{Type: eventType}
+
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		event.Data = raw
+	}
+
+	return event, nil
+}
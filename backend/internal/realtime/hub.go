@@ -0,0 +1,147 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/RickinShah/BuzzChat/internal/jsonlog"
+)
+
+var logger = jsonlog.New(os.Stdout, jsonlog.LevelInfo)
+
+// Hub tracks the sockets connected to this process, keyed by user. A user
+// can hold several connections at once, one per device or tab. Events are
+// published through the bus so users connected to other instances get them
+// too.
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[int64]map[*Client]struct{}
+	bus     Bus
+
+	// subMu serializes bus subscriptions so that they can be made without
+	// holding mu, which every delivery needs.
+	subMu      sync.Mutex
+	subscribed map[int64]bool
+}
+
+func NewHub(bus Bus) *Hub {
+	return &Hub{
+		clients:    make(map[int64]map[*Client]struct{}),
+		bus:        bus,
+		subscribed: make(map[int64]bool),
+	}
+}
+
+// Run forwards events received from the bus to local connections until ctx
+// is cancelled.
+func (h *Hub) Run(ctx context.Context) error {
+	return h.bus.Listen(ctx, h.deliver)
+}
+
+// Register adds a client, subscribing to the user's events on the first
+// connection.
+func (h *Hub) Register(c *Client) error {
+	h.mu.Lock()
+	if h.clients[c.UserID] == nil {
+		h.clients[c.UserID] = make(map[*Client]struct{})
+	}
+	h.clients[c.UserID][c] = struct{}{}
+	h.mu.Unlock()
+
+	if err := h.syncSubscription(c.UserID); err != nil {
+		h.Unregister(c)
+		return err
+	}
+
+	return nil
+}
+
+// Unregister removes the client and closes its send channel. Sends happen
+// under the read lock, so the channel is never written after it is closed.
+// The user is unsubscribed once their last connection is gone.
+func (h *Hub) Unregister(c *Client) {
+	h.mu.Lock()
+	clients, ok := h.clients[c.UserID]
+	if ok {
+		_, ok = clients[c]
+	}
+	if ok {
+		delete(clients, c)
+		close(c.send)
+
+		if len(clients) == 0 {
+			delete(h.clients, c.UserID)
+		}
+	}
+	h.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if err := h.syncSubscription(c.UserID); err != nil {
+		logger.PrintError(err, map[string]any{"user_id": c.UserID})
+	}
+}
+
+// syncSubscription subscribes to or unsubscribes from a user's events to
+// match whether they still hold a connection. Checking under subMu means a
+// connect racing a last disconnect can't leave the user unsubscribed.
+func (h *Hub) syncSubscription(userID int64) error {
+	h.subMu.Lock()
+	defer h.subMu.Unlock()
+
+	connected := h.IsConnected(userID)
+	if connected == h.subscribed[userID] {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if connected {
+		if err := h.bus.Subscribe(ctx, userID); err != nil {
+			return err
+		}
+		h.subscribed[userID] = true
+		return nil
+	}
+
+	if err := h.bus.Unsubscribe(ctx, userID); err != nil {
+		return err
+	}
+	delete(h.subscribed, userID)
+
+	return nil
+}
+
+func (h *Hub) IsConnected(userID int64) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.clients[userID]) > 0
+}
+
+func (h *Hub) Publish(userIDs []int64, event *Event) error {
+	frame, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return h.bus.Publish(ctx, userIDs, frame)
+}
+
+func (h *Hub) deliver(userID int64, frame []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.clients[userID] {
+		c.enqueue(frame)
+	}
+}
@@ -0,0 +1,172 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recordingBus remembers which users are subscribed and can be made to fail.
+type recordingBus struct {
+	MemoryBus
+	mu         sync.Mutex
+	subscribed map[int64]bool
+	fail       bool
+}
+
+func newRecordingBus() *recordingBus {
+	return &recordingBus{subscribed: make(map[int64]bool)}
+}
+
+func (b *recordingBus) Subscribe(ctx context.Context, userID int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.fail {
+		return errors.New("bus unavailable")
+	}
+	b.subscribed[userID] = true
+	return nil
+}
+
+func (b *recordingBus) Unsubscribe(ctx context.Context, userID int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribed, userID)
+	return nil
+}
+
+func (b *recordingBus) isSubscribed(userID int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.subscribed[userID]
+}
+
+func newTestClient(hub *Hub, userID int64) *Client {
+	return &Client{UserID: userID, hub: hub, send: make(chan []byte, sendBufferSize)}
+}
+
+func TestHubSubscribesWhileConnected(t *testing.T) {
+	bus := newRecordingBus()
+	hub := NewHub(bus)
+
+	first := newTestClient(hub, 1)
+	second := newTestClient(hub, 1)
+
+	if err := hub.Register(first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := hub.Register(second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bus.isSubscribed(1) {
+		t.Fatal("user is not subscribed after connecting")
+	}
+
+	hub.Unregister(first)
+	if !bus.isSubscribed(1) || !hub.IsConnected(1) {
+		t.Fatal("user lost their subscription while still connected")
+	}
+
+	hub.Unregister(second)
+	if bus.isSubscribed(1) || hub.IsConnected(1) {
+		t.Fatal("user is still subscribed after their last connection closed")
+	}
+
+	// Unregistering twice is harmless.
+	hub.Unregister(second)
+}
+
+func TestHubRegisterFailure(t *testing.T) {
+	bus := newRecordingBus()
+	bus.fail = true
+	hub := NewHub(bus)
+
+	client := newTestClient(hub, 1)
+	if err := hub.Register(client); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if hub.IsConnected(1) {
+		t.Fatal("client stayed registered after subscribing failed")
+	}
+	if _, open := <-client.send; open {
+		t.Fatal("send channel was left open")
+	}
+}
+
+func TestHubConcurrentConnections(t *testing.T) {
+	bus := newRecordingBus()
+	hub := NewHub(bus)
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			client := newTestClient(hub, 1)
+			if err := hub.Register(client); err != nil {
+				t.Error(err)
+				return
+			}
+			hub.Unregister(client)
+		}()
+	}
+	wg.Wait()
+
+	if bus.isSubscribed(1) {
+		t.Fatal("user is still subscribed with no connections")
+	}
+
+	client := newTestClient(hub, 1)
+	if err := hub.Register(client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bus.isSubscribed(1) {
+		t.Fatal("user is not subscribed while connected")
+	}
+}
+
+func TestHubPublishDelivers(t *testing.T) {
+	bus := NewMemoryBus()
+	hub := NewHub(bus)
+
+	// What Listen does, without blocking.
+	bus.deliver = hub.deliver
+
+	connected := newTestClient(hub, 1)
+	other := newTestClient(hub, 2)
+	for _, c := range []*Client{connected, other} {
+		if err := hub.Register(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	event, err := NewEvent(EventMessageCreated, map[string]string{"body": "hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := hub.Publish([]int64{1}, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case frame := <-connected.send:
+		if len(frame) == 0 {
+			t.Fatal("got an empty frame")
+		}
+	default:
+		t.Fatal("event was not delivered")
+	}
+
+	select {
+	case <-other.send:
+		t.Fatal("event was delivered to another user")
+	default:
+	}
+}
@@ -0,0 +1,75 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const userChannelPrefix = "events:user:"
+
+// RedisBus fans events out over Redis pub/sub using one channel per user.
+type RedisBus struct {
+	rdb    *redis.Client
+	pubsub *redis.PubSub
+}
+
+func NewRedisBus(rdb *redis.Client) *RedisBus {
+	return &RedisBus{
+		rdb:    rdb,
+		pubsub: rdb.Subscribe(context.Background()),
+	}
+}
+
+func userChannel(userID int64) string {
+	return userChannelPrefix + strconv.FormatInt(userID, 10)
+}
+
+func (b *RedisBus) Publish(ctx context.Context, userIDs []int64, frame []byte) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	pipe := b.rdb.Pipeline()
+	for _, userID := range userIDs {
+		pipe.Publish(ctx, userChannel(userID), frame)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (b *RedisBus) Subscribe(ctx context.Context, userID int64) error {
+	return b.pubsub.Subscribe(ctx, userChannel(userID))
+}
+
+func (b *RedisBus) Unsubscribe(ctx context.Context, userID int64) error {
+	return b.pubsub.Unsubscribe(ctx, userChannel(userID))
+}
+
+func (b *RedisBus) Listen(ctx context.Context, deliver Deliver) error {
+	defer b.pubsub.Close()
+
+	messages := b.pubsub.Channel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case message, ok := <-messages:
+			if !ok {
+				return errors.New("redis subscription closed")
+			}
+
+			userID, err := strconv.ParseInt(strings.TrimPrefix(message.Channel, userChannelPrefix), 10, 64)
+			if err != nil {
+				continue
+			}
+
+			deliver(userID, []byte(message.Payload))
+		}
+	}
+}
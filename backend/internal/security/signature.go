@@ -0,0 +1,47 @@
+package security
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+)
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// signingKey derives the HMAC key from the encryption key, so the AES key is
+// never used as a MAC key as well.
+func signingKey() []byte {
+	key, err := hkdf.Key(sha256.New, []byte(getEncryptionKey()), nil, "buzzchat signature", sha256.Size)
+	if err != nil {
+		panic(err)
+	}
+
+	return key
+}
+
+func Sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, signingKey())
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func SignWithPayload(data []byte) []byte {
+	signature := Sign(data)
+	return append(signature, data...)
+}
+
+func VerifyWithPayload(signed []byte) ([]byte, error) {
+	if len(signed) < sha256.Size {
+		return nil, ErrInvalidSignature
+	}
+
+	signature := signed[:sha256.Size]
+	data := signed[sha256.Size:]
+
+	if !hmac.Equal(signature, Sign(data)) {
+		return nil, ErrInvalidSignature
+	}
+
+	return data, nil
+}
@@ -0,0 +1,65 @@
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"flag"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	flag.String("encryption-key", "abcdefghijklmnopqrstuvwxyzabcdef", "")
+	os.Exit(m.Run())
+}
+
+func TestVerifyWithPayload(t *testing.T) {
+	payload := []byte(`{"s":"username","i":42}`)
+	signed := SignWithPayload(payload)
+
+	tampered := bytes.Clone(signed)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name    string
+		signed  []byte
+		wantErr bool
+	}{
+		{"valid", signed, false},
+		{"tampered payload", tampered, true},
+		{"tampered signature", append([]byte{signed[0] ^ 1}, signed[1:]...), true},
+		{"too short", signed[:sha256.Size-1], true},
+		{"empty", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyWithPayload(tt.signed)
+			if tt.wantErr {
+				if err != ErrInvalidSignature {
+					t.Fatalf("got error %v, want %v", err, ErrInvalidSignature)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Fatalf("got payload %q, want %q", got, payload)
+			}
+		})
+	}
+}
+
+func TestSigningKeyIsNotEncryptionKey(t *testing.T) {
+	data := []byte("payload")
+
+	mac := hmac.New(sha256.New, []byte(getEncryptionKey()))
+	mac.Write(data)
+
+	if hmac.Equal(Sign(data), mac.Sum(nil)) {
+		t.Fatal("signature is keyed with the encryption key")
+	}
+}
@@ -0,0 +1,119 @@
+package unfurl
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxTitleLength       = 300
+	maxDescriptionLength = 1000
+)
+
+// parse reads metadata out of the head of an HTML document. OpenGraph wins
+// over Twitter cards, which win over the plain title and description.
+func parse(r io.Reader, base *url.URL) *Preview {
+	meta := make(map[string]string)
+	var title string
+
+	z := html.NewTokenizer(r)
+
+loop:
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			break loop
+		case html.EndTagToken:
+			if name, _ := z.TagName(); atom.Lookup(name) == atom.Head {
+				break loop
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+
+			switch atom.Lookup(name) {
+			case atom.Body:
+				break loop
+			case atom.Title:
+				if title == "" && z.Next() == html.TextToken {
+					title = string(z.Text())
+				}
+			case atom.Meta:
+				var key, content string
+				for hasAttr {
+					var k, v []byte
+					k, v, hasAttr = z.TagAttr()
+
+					switch string(k) {
+					case "property", "name":
+						key = strings.ToLower(string(v))
+					case "content":
+						content = string(v)
+					}
+				}
+
+				if _, ok := meta[key]; key != "" && !ok {
+					meta[key] = content
+				}
+			}
+		}
+	}
+
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if value := strings.TrimSpace(meta[key]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	preview := &Preview{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		SiteName:    first("og:site_name", "application-name"),
+		ImageURL:    resolve(base, first("og:image", "og:image:url", "og:image:secure_url", "twitter:image", "twitter:image:src")),
+	}
+
+	if preview.Title == "" {
+		preview.Title = strings.TrimSpace(title)
+	}
+
+	preview.Title = truncate(collapse(preview.Title), maxTitleLength)
+	preview.Description = truncate(collapse(preview.Description), maxDescriptionLength)
+	preview.SiteName = truncate(collapse(preview.SiteName), maxTitleLength)
+
+	return preview
+}
+
+// resolve makes an image reference absolute. Only http and https images are
+// kept; clients load them directly.
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+
+	return u.String()
+}
+
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,210 @@
+// Package unfurl fetches OpenGraph and Twitter card metadata for link
+// previews.
+//
+// Every URL comes from a chat message, so the fetcher treats it as hostile:
+// requests have tight timeouts, follow few redirects, read a bounded amount
+// of HTML, and refuse to connect to private, loopback and link-local
+// addresses. That check runs on the address actually being dialled, after
+// DNS resolution, so a public hostname resolving to an internal address
+// (or a redirect to one) is caught as well.
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrBlockedAddress = errors.New("unfurl: address not allowed")
+	ErrNotHTML        = errors.New("unfurl: response is not html")
+	ErrNoPreview      = errors.New("unfurl: no preview metadata")
+)
+
+// URLRX matches http and https URLs in message text.
+var URLRX = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"']+`)
+
+// Preview is what a page says about itself in its metadata.
+type Preview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"imageUrl,omitempty"`
+	SiteName    string `json:"siteName,omitempty"`
+}
+
+type Options struct {
+	// Timeout bounds a whole fetch, redirects included.
+	Timeout time.Duration
+
+	// MaxBytes is how much of a page is read looking for metadata.
+	MaxBytes int64
+
+	// MaxRedirects is how many redirects are followed.
+	MaxRedirects int
+
+	// AllowPrivate turns off the address checks so previews can be tested
+	// against a server on localhost. Never set it in production.
+	AllowPrivate bool
+
+	UserAgent string
+}
+
+type Fetcher struct {
+	client    *http.Client
+	maxBytes  int64
+	userAgent string
+}
+
+func New(opts Options) *Fetcher {
+	dialer := &net.Dialer{
+		Timeout: 3 * time.Second,
+	}
+	if !opts.AllowPrivate {
+		dialer.Control = guard
+	}
+
+	transport := &http.Transport{
+		// A proxy would do the dialling for us and bypass the guard.
+		Proxy:                  nil,
+		DialContext:            dialer.DialContext,
+		TLSHandshakeTimeout:    3 * time.Second,
+		ResponseHeaderTimeout:  3 * time.Second,
+		MaxResponseHeaderBytes: 16 << 10,
+		MaxIdleConns:           10,
+		IdleConnTimeout:        30 * time.Second,
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			// via holds the original request too, so it's one longer than
+			// the number of redirects followed so far.
+			if len(via)-1 >= opts.MaxRedirects {
+				return fmt.Errorf("unfurl: stopped after %d redirects", opts.MaxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("unfurl: redirect to %s not allowed", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+
+	return &Fetcher{
+		client:    client,
+		maxBytes:  opts.MaxBytes,
+		userAgent: opts.UserAgent,
+	}
+}
+
+// FirstURL returns the first URL in text that can be previewed, or "".
+func FirstURL(text string) string {
+	for _, match := range URLRX.FindAllString(text, 5) {
+		// Trailing punctuation is almost always part of the sentence.
+		match = strings.TrimRight(match, ".,;:!?)]}")
+
+		u, err := url.Parse(match)
+		if err != nil || u.Host == "" || u.User != nil {
+			continue
+		}
+
+		return u.String()
+	}
+
+	return ""
+}
+
+// Fetch downloads rawURL and reads its preview metadata.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", f.userAgent)
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unfurl: unexpected status %d", res.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+
+	preview := parse(io.LimitReader(res.Body, f.maxBytes), res.Request.URL)
+	if preview.Title == "" && preview.Description == "" {
+		return nil, ErrNoPreview
+	}
+
+	preview.URL = rawURL
+
+	return preview, nil
+}
+
+// guard refuses connections to addresses that aren't on the public
+// internet. It runs for every connection, after the hostname is resolved.
+func guard(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return ErrBlockedAddress
+	}
+
+	if !IsPublic(addrPort.Addr()) {
+		return ErrBlockedAddress
+	}
+
+	return nil
+}
+
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// IsPublic reports whether addr is a globally routable unicast address.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return false
+	}
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
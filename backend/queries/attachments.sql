@@ -0,0 +1,326 @@
+-- name: LockStorageUsage :exec
+SELECT
+    1
+FROM
+    users
+WHERE
+    user_pid = $1
+FOR NO KEY UPDATE;
+
+-- name: GetStorageUsage :one
+SELECT
+    (COALESCE((
+        SELECT
+            sum(size)
+        FROM attachments
+        WHERE
+            user_pid = @user_pid), 0) + COALESCE((
+        SELECT
+            sum(size)
+        FROM uploads
+        WHERE
+            user_pid = @user_pid), 0))::bigint AS used;
+
+-- name: InsertUpload :one
+INSERT INTO uploads (user_pid, filename, size, expires_at)
+    VALUES (@user_pid, @filename, @size, @expires_at)
+RETURNING
+    upload_id,
+    upload_offset,
+    content_type,
+    created_at;
+
+-- name: GetUpload :one
+SELECT
+    upload_id,
+    user_pid,
+    filename,
+    size,
+    upload_offset,
+    content_type,
+    hash_state,
+    part_keys,
+    created_at,
+    expires_at
+FROM
+    uploads
+WHERE
+    upload_id = @upload_id
+    AND user_pid = @user_pid
+    AND expires_at > now();
+
+-- name: AdvanceUpload :execrows
+UPDATE
+    uploads
+SET
+    upload_offset = upload_offset + @written::bigint,
+    content_type = @content_type,
+    hash_state = @hash_state,
+    part_keys = array_append(part_keys, @part_key::text)
+WHERE
+    upload_id = @upload_id
+    AND upload_offset = @upload_offset;
+
+-- name: DeleteUpload :execrows
+DELETE FROM uploads
+WHERE upload_id = @upload_id
+    AND user_pid = @user_pid;
+
+-- name: DeleteExpiredUploads :many
+DELETE FROM uploads
+WHERE upload_id IN (
+        SELECT
+            upload_id
+        FROM
+            uploads
+        WHERE
+            expires_at <= now()
+        LIMIT @batch_size)
+RETURNING
+    upload_id;
+
+-- name: TouchBlob :one
+UPDATE
+    blobs
+SET
+    last_used_at = now()
+WHERE
+    sha256 = @sha256
+RETURNING
+    size,
+    status;
+
+-- name: UpsertBlob :exec
+INSERT INTO blobs (sha256, size, content_type, storage_key, status)
+    VALUES (@sha256, @size, @content_type, @storage_key, @status)
+ON CONFLICT (sha256)
+    DO UPDATE SET
+        last_used_at = now();
+
+-- name: GetBlob :one
+SELECT
+    sha256,
+    size,
+    content_type,
+    storage_key,
+    status
+FROM
+    blobs
+WHERE
+    sha256 = @sha256;
+
+-- name: UpdateBlobMedia :exec
+WITH updated AS (
+    UPDATE
+        blobs
+    SET
+        size = @size,
+        status = @status,
+        width = @width,
+        height = @height,
+        duration_ms = @duration_ms,
+        blurhash = @blurhash,
+        thumbnail_sizes = @thumbnail_sizes::int[],
+        thumbnail_type = @thumbnail_type
+    WHERE
+        blobs.sha256 = @sha256
+    RETURNING
+        blobs.sha256,
+        blobs.size)
+UPDATE
+    attachments
+SET
+    size = updated.size
+FROM
+    updated
+WHERE
+    attachments.sha256 = updated.sha256;
+
+-- name: ListBlobMedia :many
+SELECT
+    sha256,
+    status,
+    width,
+    height,
+    duration_ms,
+    blurhash,
+    thumbnail_sizes
+FROM
+    blobs
+WHERE
+    sha256 = ANY (@sha256s::text[]);
+
+-- name: FailBlobMedia :execrows
+UPDATE
+    blobs
+SET
+    status = 'failed'
+WHERE
+    sha256 = @sha256
+    AND status = 'pending';
+
+-- name: ListStalePendingBlobs :many
+SELECT
+    sha256
+FROM
+    blobs
+WHERE
+    status = 'pending'
+    AND created_at < @created_before
+ORDER BY
+    created_at
+LIMIT @batch_size;
+
+-- name: ListBlobAttachments :many
+SELECT
+    a.attachment_id,
+    a.user_pid,
+    a.message_id,
+    a.sha256,
+    a.filename,
+    a.size,
+    a.content_type,
+    a.created_at,
+    m.conversation_id
+FROM
+    attachments a
+    LEFT JOIN messages m ON m.message_id = a.message_id
+WHERE
+    a.sha256 = @sha256;
+
+-- name: DeleteOrphanBlobs :many
+DELETE FROM blobs
+WHERE sha256 IN (
+        SELECT
+            b.sha256
+        FROM
+            blobs b
+        WHERE
+            b.last_used_at < @used_before
+            AND NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    attachments a
+                WHERE
+                    a.sha256 = b.sha256)
+            LIMIT @batch_size)
+RETURNING
+    sha256,
+    storage_key,
+    thumbnail_sizes;
+
+-- name: InsertAttachment :one
+INSERT INTO attachments (user_pid, sha256, filename, size, content_type)
+    VALUES (@user_pid, @sha256, @filename, @size, @content_type)
+RETURNING
+    attachment_id,
+    created_at;
+
+-- name: GetAttachment :one
+SELECT
+    a.attachment_id,
+    a.user_pid,
+    a.message_id,
+    a.sha256,
+    a.filename,
+    b.size,
+    a.content_type,
+    a.created_at,
+    b.storage_key,
+    b.status,
+    b.thumbnail_sizes,
+    b.thumbnail_type
+FROM
+    attachments a
+    INNER JOIN blobs b ON b.sha256 = a.sha256
+WHERE
+    a.attachment_id = @attachment_id
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            messages m
+        WHERE
+            m.message_id = a.message_id
+            AND m.expires_at <= now());
+
+-- name: ListUnlinkedAttachments :many
+SELECT
+    attachment_id,
+    user_pid,
+    message_id,
+    sha256,
+    filename,
+    size,
+    content_type,
+    created_at
+FROM
+    attachments
+WHERE
+    attachment_id = ANY (@attachment_ids::bigint[])
+    AND user_pid = @user_pid
+    AND message_id IS NULL;
+
+-- name: LinkAttachments :execrows
+UPDATE
+    attachments
+SET
+    message_id = @message_id
+WHERE
+    attachment_id = ANY (@attachment_ids::bigint[])
+    AND user_pid = @user_pid
+    AND message_id IS NULL;
+
+-- name: GetMessageAttachmentSize :one
+SELECT
+    COALESCE(sum(size), 0)::bigint AS size
+FROM
+    attachments
+WHERE
+    message_id = @message_id::bigint;
+
+-- name: CopyAttachments :execrows
+INSERT INTO attachments (user_pid, message_id, sha256, filename, size, content_type)
+SELECT
+    @user_pid::bigint,
+    @message_id::bigint,
+    sha256,
+    filename,
+    size,
+    content_type
+FROM
+    attachments
+WHERE
+    attachments.message_id = @source_id::bigint
+ORDER BY
+    attachment_id;
+
+-- name: ListMessageAttachments :many
+SELECT
+    attachment_id,
+    user_pid,
+    message_id,
+    sha256,
+    filename,
+    size,
+    content_type,
+    created_at
+FROM
+    attachments
+WHERE
+    message_id = ANY (@message_ids::bigint[])
+ORDER BY
+    attachment_id;
+
+-- name: DeleteUnlinkedAttachments :execrows
+DELETE FROM attachments
+WHERE attachment_id IN (
+        SELECT
+            attachment_id
+        FROM
+            attachments
+        WHERE
+            message_id IS NULL
+            AND created_at < @created_before
+        LIMIT @batch_size);
@@ -0,0 +1,238 @@
+-- name: GetContact :one
+SELECT
+    requester_pid,
+    addressee_pid,
+    status,
+    created_at,
+    updated_at
+FROM
+    contacts
+WHERE (requester_pid = $1
+    AND addressee_pid = $2)
+    OR (requester_pid = $2
+        AND addressee_pid = $1);
+
+-- name: InsertContact :one
+INSERT INTO contacts (requester_pid, addressee_pid, status)
+    VALUES ($1, $2, 'pending')
+ON CONFLICT (LEAST(requester_pid, addressee_pid), GREATEST(requester_pid, addressee_pid))
+    DO UPDATE SET
+        requester_pid = EXCLUDED.requester_pid,
+        addressee_pid = EXCLUDED.addressee_pid,
+        status = 'pending',
+        created_at = now(),
+        updated_at = now()
+    WHERE
+        contacts.status = 'declined'
+    RETURNING
+        requester_pid,
+        addressee_pid,
+        status,
+        created_at,
+        updated_at;
+
+-- name: BlockUser :exec
+WITH removed AS (
+    DELETE FROM contacts
+    WHERE (requester_pid = @blocker_pid
+            AND addressee_pid = @blocked_pid)
+        OR (requester_pid = @blocked_pid
+            AND addressee_pid = @blocker_pid))
+INSERT INTO blocks (blocker_pid, blocked_pid)
+    VALUES (@blocker_pid, @blocked_pid)
+ON CONFLICT (blocker_pid, blocked_pid)
+    DO NOTHING;
+
+-- name: UnblockUser :execrows
+DELETE FROM blocks
+WHERE blocker_pid = $1
+    AND blocked_pid = $2;
+
+-- name: GetBlocks :one
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            blocks
+        WHERE
+            blocker_pid = @user_pid
+            AND blocked_pid = @other_pid)::bool AS blocking,
+    EXISTS (
+        SELECT
+            1
+        FROM
+            blocks
+        WHERE
+            blocker_pid = @other_pid
+            AND blocked_pid = @user_pid)::bool AS blocked_by;
+
+-- name: UpdateContactStatus :one
+UPDATE
+    contacts
+SET
+    status = sqlc.arg(new_status),
+    updated_at = now()
+WHERE
+    requester_pid = sqlc.arg(requester_pid)
+    AND addressee_pid = sqlc.arg(addressee_pid)
+    AND status = sqlc.arg(old_status)
+RETURNING
+    requester_pid,
+    addressee_pid,
+    status,
+    created_at,
+    updated_at;
+
+-- name: DeleteContactRequest :execrows
+DELETE FROM contacts
+WHERE requester_pid = $1
+    AND addressee_pid = $2
+    AND status = $3;
+
+-- name: DeleteContact :execrows
+DELETE FROM contacts
+WHERE ((requester_pid = $1
+        AND addressee_pid = $2)
+    OR (requester_pid = $2
+        AND addressee_pid = $1))
+    AND status = 'accepted';
+
+-- name: ListContacts :many
+SELECT
+    u.user_pid,
+    u.username,
+    u.name,
+    CASE WHEN profile_pic_visible (p.profile_pic_visibility, u.user_pid, @user_pid::bigint) THEN
+        u.profile_pic
+    END AS profile_pic,
+    c.status,
+    c.created_at,
+    c.updated_at
+FROM
+    contacts c
+    INNER JOIN users u ON u.user_pid = CASE WHEN c.requester_pid = @user_pid THEN
+        c.addressee_pid
+    ELSE
+        c.requester_pid
+    END
+    LEFT JOIN privacy_settings p ON p.user_pid = u.user_pid
+WHERE (c.requester_pid = @user_pid
+    OR c.addressee_pid = @user_pid)
+    AND c.status = 'accepted'
+    AND (NOT @has_cursor::bool
+        OR (@descending::bool
+            AND (u.username, u.user_pid) < (@cursor_username::citext, @cursor_id::bigint))
+        OR (NOT @descending::bool
+            AND (u.username, u.user_pid) > (@cursor_username::citext, @cursor_id::bigint)))
+ORDER BY
+    CASE WHEN @descending::bool THEN
+        u.username
+    END DESC,
+    CASE WHEN @descending::bool THEN
+        u.user_pid
+    END DESC,
+    CASE WHEN NOT @descending::bool THEN
+        u.username
+    END ASC,
+    CASE WHEN NOT @descending::bool THEN
+        u.user_pid
+    END ASC
+LIMIT @page_limit;
+
+-- name: CountContacts :one
+SELECT
+    count(*)
+FROM
+    contacts
+WHERE (requester_pid = @user_pid
+    OR addressee_pid = @user_pid)
+    AND status = 'accepted';
+
+-- name: ListIncomingContactRequests :many
+SELECT
+    u.user_pid,
+    u.username,
+    u.name,
+    CASE WHEN profile_pic_visible (p.profile_pic_visibility, u.user_pid, @user_pid::bigint) THEN
+        u.profile_pic
+    END AS profile_pic,
+    c.status,
+    c.created_at,
+    c.updated_at
+FROM
+    contacts c
+    INNER JOIN users u ON u.user_pid = c.requester_pid
+    LEFT JOIN privacy_settings p ON p.user_pid = u.user_pid
+WHERE
+    c.addressee_pid = @user_pid::bigint
+    AND c.status = 'pending'
+ORDER BY
+    c.created_at DESC
+LIMIT 100;
+
+-- name: ListOutgoingContactRequests :many
+SELECT
+    u.user_pid,
+    u.username,
+    u.name,
+    CASE WHEN profile_pic_visible (p.profile_pic_visibility, u.user_pid, @user_pid::bigint) THEN
+        u.profile_pic
+    END AS profile_pic,
+    c.status,
+    c.created_at,
+    c.updated_at
+FROM
+    contacts c
+    INNER JOIN users u ON u.user_pid = c.addressee_pid
+    LEFT JOIN privacy_settings p ON p.user_pid = u.user_pid
+WHERE
+    c.requester_pid = @user_pid::bigint
+    AND c.status = 'pending'
+ORDER BY
+    c.created_at DESC
+LIMIT 100;
+
+-- name: ListBlockedUsers :many
+SELECT
+    u.user_pid,
+    u.username,
+    u.name,
+    CASE WHEN profile_pic_visible (p.profile_pic_visibility, u.user_pid, @user_pid::bigint) THEN
+        u.profile_pic
+    END AS profile_pic,
+    b.created_at
+FROM
+    blocks b
+    INNER JOIN users u ON u.user_pid = b.blocked_pid
+    LEFT JOIN privacy_settings p ON p.user_pid = u.user_pid
+WHERE
+    b.blocker_pid = @user_pid::bigint
+ORDER BY
+    b.created_at DESC;
+
+-- name: ListContactIDs :many
+SELECT
+    CASE WHEN requester_pid = @user_pid THEN
+        addressee_pid
+    ELSE
+        requester_pid
+    END::bigint AS user_pid
+FROM
+    contacts
+WHERE (requester_pid = @user_pid
+    OR addressee_pid = @user_pid)
+    AND status = 'accepted';
+
+-- name: ListBlockedIDs :many
+SELECT
+    CASE WHEN blocker_pid = @user_pid THEN
+        blocked_pid
+    ELSE
+        blocker_pid
+    END::bigint AS user_pid
+FROM
+    blocks
+WHERE
+    blocker_pid = @user_pid
+    OR blocked_pid = @user_pid;
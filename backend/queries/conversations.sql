@@ -0,0 +1,326 @@
+-- name: InsertDirectConversation :one
+WITH c AS (
+INSERT INTO conversations (kind, direct_key)
+        VALUES ('direct', @direct_key)
+    ON CONFLICT (direct_key)
+        DO NOTHING
+    RETURNING
+        conversation_id
+), p AS (
+INSERT INTO conversation_participants (conversation_id, user_pid)
+    SELECT
+        c.conversation_id,
+        unnest(@user_pids::bigint[])
+    FROM
+        c)
+SELECT
+    conversation_id
+FROM
+    c;
+
+-- name: GetConversationByDirectKey :one
+SELECT
+    conversation_id,
+    kind,
+    direct_key,
+    last_message_id,
+    last_message_at,
+    created_at,
+    updated_at,
+    version,
+    title,
+    avatar,
+    description,
+    link_previews,
+    mention_all,
+    message_ttl,
+    allow_forwarding
+FROM
+    conversations
+WHERE
+    direct_key = $1;
+
+-- name: GetConversationForParticipant :one
+SELECT
+    c.conversation_id,
+    c.kind,
+    c.direct_key,
+    c.last_message_id,
+    c.last_message_at,
+    c.created_at,
+    c.updated_at,
+    c.version,
+    c.title,
+    c.avatar,
+    c.description,
+    c.link_previews,
+    c.mention_all,
+    c.message_ttl,
+    c.allow_forwarding
+FROM
+    conversations c
+    INNER JOIN conversation_participants p ON p.conversation_id = c.conversation_id
+WHERE
+    c.conversation_id = $1
+    AND p.user_pid = $2;
+
+-- name: IsParticipant :one
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            conversation_participants
+        WHERE
+            conversation_id = $1
+            AND user_pid = $2)::bool AS is_participant;
+
+-- name: ListConversations :many
+SELECT
+    c.conversation_id,
+    c.kind,
+    c.direct_key,
+    c.last_message_id,
+    c.last_message_at,
+    c.created_at,
+    c.updated_at,
+    c.version,
+    c.title,
+    c.avatar,
+    c.description,
+    c.link_previews,
+    c.mention_all,
+    c.message_ttl,
+    c.allow_forwarding
+FROM
+    conversations c
+    INNER JOIN conversation_participants p ON p.conversation_id = c.conversation_id
+WHERE
+    p.user_pid = @user_pid
+    AND (NOT @has_cursor::bool
+        OR (@descending::bool
+            AND COALESCE(c.last_message_id, c.conversation_id) < @cursor_id::bigint)
+        OR (NOT @descending::bool
+            AND COALESCE(c.last_message_id, c.conversation_id) > @cursor_id::bigint))
+ORDER BY
+    CASE WHEN @descending::bool THEN
+        COALESCE(c.last_message_id, c.conversation_id)
+    END DESC,
+    CASE WHEN NOT @descending::bool THEN
+        COALESCE(c.last_message_id, c.conversation_id)
+    END ASC
+LIMIT @page_limit;
+
+-- name: CountConversations :one
+SELECT
+    count(*)
+FROM
+    conversation_participants
+WHERE
+    user_pid = $1;
+
+-- name: ListParticipants :many
+SELECT
+    p.conversation_id,
+    u.user_pid,
+    u.username,
+    u.name,
+    CASE WHEN profile_pic_visible (s.profile_pic_visibility, u.user_pid, @viewer_pid::bigint) THEN
+        u.profile_pic
+    END AS profile_pic,
+    p.joined_at,
+    p.role,
+    p.last_delivered_id,
+    CASE WHEN u.user_pid = @viewer_pid
+        OR COALESCE(s.read_receipts, TRUE) THEN
+        p.last_read_id
+    END AS last_read_id,
+    CASE WHEN u.user_pid = @viewer_pid THEN
+        p.muted_until
+    END AS muted_until
+FROM
+    conversation_participants p
+    INNER JOIN users u ON u.user_pid = p.user_pid
+    LEFT JOIN privacy_settings s ON s.user_pid = u.user_pid
+WHERE
+    p.conversation_id = ANY (@conversation_ids::bigint[])
+ORDER BY
+    CASE p.role
+    WHEN 'owner' THEN
+        0
+    WHEN 'admin' THEN
+        1
+    ELSE
+        2
+    END,
+    p.joined_at,
+    u.user_pid;
+
+-- name: ListParticipantIDs :many
+SELECT
+    user_pid
+FROM
+    conversation_participants
+WHERE
+    conversation_id = $1;
+
+-- name: InsertGroupConversation :one
+WITH c AS (
+INSERT INTO conversations (kind, title, avatar, description)
+        VALUES ('group', @title, sqlc.narg(avatar), sqlc.narg(description))
+    RETURNING
+        conversation_id
+), p AS (
+INSERT INTO conversation_participants (conversation_id, user_pid, role)
+    SELECT
+        c.conversation_id,
+        @owner_pid::bigint,
+        'owner'
+    FROM
+        c
+    UNION ALL
+    SELECT
+        c.conversation_id,
+        unnest(@member_pids::bigint[]),
+        'member'
+    FROM
+        c)
+SELECT
+    conversation_id
+FROM
+    c;
+
+-- name: UpdateGroupConversation :one
+UPDATE
+    conversations
+SET
+    title = @title,
+    avatar = sqlc.narg(avatar),
+    description = sqlc.narg(description),
+    updated_at = now(),
+    version = version + 1
+WHERE
+    conversation_id = @conversation_id
+    AND kind = 'group'
+    AND version = @version
+RETURNING
+    updated_at,
+    version;
+
+-- name: UpdateConversationSettings :one
+UPDATE
+    conversations
+SET
+    link_previews = @link_previews,
+    mention_all = @mention_all,
+    message_ttl = @message_ttl,
+    allow_forwarding = @allow_forwarding,
+    updated_at = now(),
+    version = version + 1
+WHERE
+    conversation_id = @conversation_id
+    AND version = @version
+RETURNING
+    updated_at,
+    version;
+
+-- name: MuteConversation :execrows
+UPDATE
+    conversation_participants
+SET
+    muted_until = sqlc.narg(muted_until)
+WHERE
+    conversation_id = @conversation_id
+    AND user_pid = @user_pid;
+
+-- name: ListMutedParticipantIDs :many
+SELECT
+    user_pid
+FROM
+    conversation_participants
+WHERE
+    conversation_id = @conversation_id
+    AND muted_until > now();
+
+-- name: DeleteConversation :exec
+DELETE FROM conversations
+WHERE conversation_id = $1;
+
+-- name: GetMembership :many
+SELECT
+    c.kind,
+    c.link_previews,
+    c.mention_all,
+    c.allow_forwarding,
+    p.user_pid,
+    p.role
+FROM
+    conversations c
+    INNER JOIN conversation_participants p ON p.conversation_id = c.conversation_id
+WHERE
+    c.conversation_id = $1;
+
+-- name: AddParticipants :many
+INSERT INTO conversation_participants (conversation_id, user_pid)
+SELECT
+    @conversation_id::bigint,
+    unnest(@user_pids::bigint[])
+ON CONFLICT (conversation_id,
+    user_pid)
+    DO NOTHING
+RETURNING
+    user_pid;
+
+-- name: RemoveParticipant :execrows
+DELETE FROM conversation_participants
+WHERE conversation_id = $1
+    AND user_pid = $2;
+
+-- name: UpdateParticipantRole :execrows
+UPDATE
+    conversation_participants
+SET
+    role = @new_role
+WHERE
+    conversation_id = @conversation_id
+    AND user_pid = @user_pid
+    AND role = @old_role;
+
+-- name: TransferOwnership :execrows
+UPDATE
+    conversation_participants
+SET
+    role = CASE WHEN user_pid = @owner_pid::bigint THEN
+        'admin'
+    ELSE
+        'owner'
+    END
+WHERE
+    conversation_id = @conversation_id
+    AND user_pid IN (@owner_pid::bigint, @new_owner_pid::bigint)
+    AND EXISTS (
+        SELECT
+            1
+        FROM
+            conversation_participants o
+        WHERE
+            o.conversation_id = @conversation_id
+            AND o.user_pid = @owner_pid::bigint
+            AND o.role = 'owner')
+    AND EXISTS (
+        SELECT
+            1
+        FROM
+            conversation_participants n
+        WHERE
+            n.conversation_id = @conversation_id
+            AND n.user_pid = @new_owner_pid::bigint);
+
+-- name: LockConversation :exec
+SELECT
+    1
+FROM
+    conversations
+WHERE
+    conversation_id = $1
+FOR NO KEY UPDATE;
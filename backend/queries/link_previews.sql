@@ -0,0 +1,24 @@
+-- name: UpsertLinkPreview :exec
+INSERT INTO link_previews (message_id, url, title, description, image_url, site_name)
+    VALUES (@message_id, @url, @title, @description, @image_url, @site_name)
+ON CONFLICT (message_id)
+    DO UPDATE SET
+        url = EXCLUDED.url, title = EXCLUDED.title, description = EXCLUDED.description, image_url = EXCLUDED.image_url, site_name = EXCLUDED.site_name, created_at = now();
+
+-- name: DeleteLinkPreview :execrows
+DELETE FROM link_previews
+WHERE message_id = @message_id;
+
+-- name: ListLinkPreviews :many
+SELECT
+    message_id,
+    url,
+    title,
+    description,
+    image_url,
+    site_name,
+    created_at
+FROM
+    link_previews
+WHERE
+    message_id = ANY (@message_ids::bigint[]);
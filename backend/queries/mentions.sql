@@ -0,0 +1,18 @@
+-- name: InsertMentions :exec
+INSERT INTO message_mentions (message_id, user_pid)
+SELECT
+    @message_id::bigint,
+    unnest(@user_pids::bigint[])
+ON CONFLICT
+    DO NOTHING;
+
+-- name: ResolveMentions :many
+SELECT
+    u.user_pid,
+    lower(u.username::text)::text AS username
+FROM
+    conversation_participants p
+    INNER JOIN users u ON u.user_pid = p.user_pid
+WHERE
+    p.conversation_id = @conversation_id
+    AND lower(u.username::text) = ANY (@usernames::text[]);
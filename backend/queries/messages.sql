@@ -0,0 +1,415 @@
+-- name: InsertMessage :one
+INSERT INTO messages (conversation_id, sender_pid, kind, body, parent_id, thread_id, quote, entities, forward, expires_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, (
+            SELECT
+                CASE WHEN c.message_ttl > 0
+                    AND $3 <> 'system' THEN
+                    now() + make_interval(secs => c.message_ttl)
+                END
+            FROM
+                conversations c
+            WHERE
+                c.conversation_id = $1))
+RETURNING
+    message_id, created_at, expires_at;
+
+-- name: GetMessage :one
+SELECT
+    message_id,
+    conversation_id,
+    sender_pid,
+    kind,
+    body,
+    created_at,
+    edited_at,
+    version,
+    deleted_at,
+    deleter_pid,
+    parent_id,
+    thread_id,
+    quote,
+    reply_count,
+    last_reply_at,
+    thread_participant_ids,
+    entities,
+    expires_at,
+    forward
+FROM
+    messages
+WHERE
+    message_id = $1
+    AND conversation_id = $2
+    AND (expires_at IS NULL
+        OR expires_at > now());
+
+-- name: LockMessage :exec
+SELECT
+    1
+FROM
+    messages
+WHERE
+    message_id = $1
+FOR NO KEY UPDATE;
+
+-- name: ListMessagesByID :many
+SELECT
+    message_id,
+    conversation_id,
+    sender_pid,
+    kind,
+    body,
+    created_at,
+    edited_at,
+    version,
+    deleted_at,
+    deleter_pid,
+    parent_id,
+    thread_id,
+    quote,
+    reply_count,
+    last_reply_at,
+    thread_participant_ids,
+    entities,
+    expires_at,
+    forward
+FROM
+    messages
+WHERE
+    message_id = ANY (@message_ids::bigint[])
+    AND (expires_at IS NULL
+        OR expires_at > now());
+
+-- name: ListMessagesBefore :many
+SELECT
+    message_id,
+    conversation_id,
+    sender_pid,
+    kind,
+    body,
+    created_at,
+    edited_at,
+    version,
+    deleted_at,
+    deleter_pid,
+    parent_id,
+    thread_id,
+    quote,
+    reply_count,
+    last_reply_at,
+    thread_participant_ids,
+    entities,
+    expires_at,
+    forward
+FROM
+    messages
+WHERE
+    conversation_id = @conversation_id
+    AND (expires_at IS NULL
+        OR expires_at > now())
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            hidden_messages h
+        WHERE
+            h.message_id = messages.message_id
+            AND h.user_pid = @viewer_pid)
+    AND ((@thread_id::bigint = 0
+            AND thread_id IS NULL)
+        OR thread_id = @thread_id::bigint)
+    AND message_id < @boundary_id::bigint
+ORDER BY
+    message_id DESC
+LIMIT @page_limit;
+
+-- name: ListMessagesAfter :many
+SELECT
+    message_id,
+    conversation_id,
+    sender_pid,
+    kind,
+    body,
+    created_at,
+    edited_at,
+    version,
+    deleted_at,
+    deleter_pid,
+    parent_id,
+    thread_id,
+    quote,
+    reply_count,
+    last_reply_at,
+    thread_participant_ids,
+    entities,
+    expires_at,
+    forward
+FROM
+    messages
+WHERE
+    conversation_id = @conversation_id
+    AND (expires_at IS NULL
+        OR expires_at > now())
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            hidden_messages h
+        WHERE
+            h.message_id = messages.message_id
+            AND h.user_pid = @viewer_pid)
+    AND ((@thread_id::bigint = 0
+            AND thread_id IS NULL)
+        OR thread_id = @thread_id::bigint)
+    AND message_id > @boundary_id::bigint
+ORDER BY
+    message_id ASC
+LIMIT @page_limit;
+
+-- name: EditMessage :one
+WITH old AS (
+    SELECT
+        message_id,
+        version,
+        body
+    FROM
+        messages
+    WHERE
+        message_id = @message_id
+        AND conversation_id = @conversation_id
+        AND version = @version
+        AND deleted_at IS NULL
+    FOR UPDATE
+), saved AS (
+INSERT INTO message_edits (message_id, version, body, editor_pid)
+    SELECT
+        old.message_id,
+        old.version,
+        old.body,
+        @editor_pid::bigint
+    FROM
+        old)
+UPDATE
+    messages m
+SET
+    body = @body,
+    entities = @entities,
+    edited_at = now(),
+    version = m.version + 1
+FROM
+    old
+WHERE
+    m.message_id = old.message_id
+RETURNING
+    m.edited_at,
+    m.version;
+
+-- name: ListMessageEdits :many
+SELECT
+    message_id,
+    version,
+    body,
+    editor_pid,
+    replaced_at
+FROM
+    message_edits
+WHERE
+    message_id = $1
+ORDER BY
+    version;
+
+-- name: DeleteMessageForEveryone :one
+WITH upd AS (
+    UPDATE
+        messages
+    SET
+        body = '',
+        quote = NULL,
+        entities = NULL,
+        deleted_at = now(),
+        deleter_pid = @deleter_pid,
+        version = version + 1
+    WHERE
+        message_id = @message_id
+        AND conversation_id = @conversation_id
+        AND version = @version
+        AND deleted_at IS NULL
+    RETURNING
+        message_id,
+        deleted_at,
+        version),
+purged AS (
+    DELETE FROM message_edits USING upd
+    WHERE message_edits.message_id = upd.message_id),
+reactions AS (
+    DELETE FROM message_reactions USING upd
+    WHERE message_reactions.message_id = upd.message_id),
+files AS (
+    DELETE FROM attachments USING upd
+    WHERE attachments.message_id = upd.message_id),
+previews AS (
+    DELETE FROM link_previews USING upd
+    WHERE link_previews.message_id = upd.message_id),
+mentions AS (
+    DELETE FROM message_mentions USING upd
+    WHERE message_mentions.message_id = upd.message_id),
+pins AS (
+    DELETE FROM pinned_messages USING upd
+    WHERE pinned_messages.message_id = upd.message_id
+    RETURNING
+        pinned_messages.message_id),
+polls AS (
+    DELETE FROM polls USING upd
+    WHERE polls.message_id = upd.message_id),
+quotes AS (
+    UPDATE
+        messages
+    SET
+        quote = NULL
+    FROM
+        upd
+    WHERE
+        messages.parent_id = upd.message_id)
+SELECT
+    deleted_at,
+    version,
+    EXISTS (
+        SELECT
+            1
+        FROM
+            pins)::bool AS unpinned
+FROM
+    upd;
+
+-- name: DeleteExpiredMessages :many
+WITH expired AS (
+    SELECT
+        message_id
+    FROM
+        messages
+    WHERE
+        expires_at <= now()
+    ORDER BY
+        expires_at
+    LIMIT @batch_size::int
+    FOR UPDATE
+        SKIP LOCKED),
+doomed AS (
+    SELECT
+        message_id
+    FROM
+        expired
+    UNION
+    SELECT
+        r.message_id
+    FROM
+        messages r
+        INNER JOIN expired e ON r.thread_id = e.message_id),
+pins AS (
+    DELETE FROM pinned_messages USING doomed
+    WHERE pinned_messages.message_id = doomed.message_id
+    RETURNING
+        pinned_messages.message_id),
+threads AS (
+    SELECT
+        r.thread_id,
+        count(*) FILTER (WHERE d.message_id IS NULL)::int AS reply_count,
+        max(r.created_at) FILTER (WHERE d.message_id IS NULL) AS last_reply_at,
+        ARRAY (
+            SELECT
+                p.sender_pid
+            FROM
+                messages p
+            WHERE
+                p.thread_id = r.thread_id
+                AND p.sender_pid IS NOT NULL
+                AND p.message_id NOT IN (
+                    SELECT
+                        message_id
+                    FROM
+                        doomed)
+            GROUP BY
+                p.sender_pid
+            ORDER BY
+                min(p.message_id)) AS participant_ids
+    FROM
+        messages r
+        LEFT JOIN doomed d ON r.message_id = d.message_id
+    WHERE
+        r.thread_id IN (
+            SELECT
+                m.thread_id
+            FROM
+                messages m
+                INNER JOIN doomed ON m.message_id = doomed.message_id
+            WHERE
+                m.thread_id NOT IN (
+                    SELECT
+                        message_id
+                    FROM
+                        doomed))
+    GROUP BY
+        r.thread_id),
+survivors AS (
+    SELECT
+        message_id
+    FROM
+        messages
+    WHERE
+        parent_id IN (
+            SELECT
+                message_id
+            FROM
+                doomed)
+        AND message_id NOT IN (
+            SELECT
+                message_id
+            FROM
+                doomed)
+    UNION
+    SELECT
+        thread_id
+    FROM
+        threads),
+updated AS (
+    UPDATE
+        messages
+    SET
+        quote = CASE WHEN messages.parent_id IN (
+            SELECT
+                message_id
+            FROM
+                doomed) THEN
+            NULL
+        ELSE
+            messages.quote
+        END,
+        reply_count = COALESCE(threads.reply_count, messages.reply_count),
+        last_reply_at = CASE WHEN threads.thread_id IS NULL THEN
+            messages.last_reply_at
+        ELSE
+            threads.last_reply_at
+        END,
+        thread_participant_ids = COALESCE(threads.participant_ids, messages.thread_participant_ids)
+    FROM
+        survivors
+        LEFT JOIN threads ON survivors.message_id = threads.thread_id
+    WHERE
+        messages.message_id = survivors.message_id)
+DELETE FROM messages USING doomed
+WHERE messages.message_id = doomed.message_id
+RETURNING
+    messages.message_id,
+    messages.conversation_id,
+    EXISTS (
+        SELECT
+            1
+        FROM
+            pins
+        WHERE
+            pins.message_id = messages.message_id)::bool AS unpinned;
+
+-- name: HideMessage :exec
+INSERT INTO hidden_messages (user_pid, message_id)
+    VALUES ($1, $2)
+ON CONFLICT (user_pid, message_id)
+    DO NOTHING;
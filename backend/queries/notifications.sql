@@ -0,0 +1,61 @@
+-- name: InsertNotification :one
+INSERT INTO notifications (user_pid, actor_pid, kind, payload)
+    VALUES ($1, $2, $3, $4)
+RETURNING
+    notification_id, read_at, created_at;
+
+-- name: ListNotifications :many
+SELECT
+    notification_id,
+    user_pid,
+    actor_pid,
+    kind,
+    payload,
+    read_at,
+    created_at
+FROM
+    notifications
+WHERE
+    user_pid = @user_pid
+    AND (NOT @unread_only::bool
+        OR read_at IS NULL)
+    AND (NOT @has_cursor::bool
+        OR (@descending::bool
+            AND notification_id < @cursor_id::bigint)
+        OR (NOT @descending::bool
+            AND notification_id > @cursor_id::bigint))
+ORDER BY
+    CASE WHEN @descending::bool THEN
+        notification_id
+    END DESC,
+    CASE WHEN NOT @descending::bool THEN
+        notification_id
+    END ASC
+LIMIT @page_limit;
+
+-- name: CountUnreadNotifications :one
+SELECT
+    count(*)
+FROM
+    notifications
+WHERE
+    user_pid = $1
+    AND read_at IS NULL;
+
+-- name: MarkNotificationRead :execrows
+UPDATE
+    notifications
+SET
+    read_at = COALESCE(read_at, now())
+WHERE
+    notification_id = $1
+    AND user_pid = $2;
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE
+    notifications
+SET
+    read_at = now()
+WHERE
+    user_pid = $1
+    AND read_at IS NULL;
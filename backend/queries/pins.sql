@@ -0,0 +1,51 @@
+-- name: InsertPin :execrows
+INSERT INTO pinned_messages (message_id, conversation_id, pinner_pid)
+SELECT
+    @message_id::bigint,
+    @conversation_id::bigint,
+    @pinner_pid::bigint
+WHERE (
+    SELECT
+        count(*)
+    FROM
+        pinned_messages
+    WHERE
+        conversation_id = @conversation_id::bigint) < @max_pins::int
+ON CONFLICT (message_id)
+    DO NOTHING;
+
+-- name: PinExists :one
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            pinned_messages
+        WHERE
+            message_id = $1)::bool AS pinned;
+
+-- name: DeletePin :execrows
+DELETE FROM pinned_messages
+WHERE message_id = $1
+    AND conversation_id = $2;
+
+-- name: ListPins :many
+SELECT
+    p.message_id,
+    p.pinner_pid,
+    p.pinned_at
+FROM
+    pinned_messages p
+WHERE
+    p.conversation_id = @conversation_id
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            hidden_messages h
+        WHERE
+            h.message_id = p.message_id
+            AND h.user_pid = @viewer_pid)
+ORDER BY
+    p.pinned_at DESC,
+    p.message_id DESC;
@@ -0,0 +1,149 @@
+-- name: InsertPoll :exec
+INSERT INTO polls (message_id, conversation_id, question, options, multiple_choice, anonymous, closes_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: GetPoll :one
+SELECT
+    message_id,
+    conversation_id,
+    question,
+    options,
+    multiple_choice,
+    anonymous,
+    closes_at,
+    closed_at
+FROM
+    polls
+WHERE
+    message_id = $1;
+
+-- name: ListPolls :many
+SELECT
+    message_id,
+    conversation_id,
+    question,
+    options,
+    multiple_choice,
+    anonymous,
+    closes_at,
+    closed_at
+FROM
+    polls
+WHERE
+    message_id = ANY (@message_ids::bigint[]);
+
+-- name: ListPollTallies :many
+SELECT
+    message_id,
+    option_id,
+    count(*) AS votes
+FROM
+    poll_votes
+WHERE
+    message_id = ANY (@message_ids::bigint[])
+GROUP BY
+    message_id,
+    option_id;
+
+-- name: ListPollVoterCounts :many
+SELECT
+    message_id,
+    count(DISTINCT user_pid) AS voters
+FROM
+    poll_votes
+WHERE
+    message_id = ANY (@message_ids::bigint[])
+GROUP BY
+    message_id;
+
+-- name: ListPollVotesByUser :many
+SELECT
+    message_id,
+    option_id
+FROM
+    poll_votes
+WHERE
+    message_id = ANY (@message_ids::bigint[])
+    AND user_pid = @user_pid
+ORDER BY
+    message_id,
+    option_id;
+
+-- name: ListPollVoters :many
+SELECT
+    option_id,
+    user_pid
+FROM
+    poll_votes
+WHERE
+    message_id = $1
+ORDER BY
+    option_id,
+    created_at,
+    user_pid;
+
+-- name: LockOpenPoll :one
+SELECT
+    message_id
+FROM
+    polls
+WHERE
+    message_id = @message_id
+    AND closed_at IS NULL
+    AND (closes_at IS NULL
+        OR closes_at > now())
+FOR NO KEY UPDATE;
+
+-- name: RetractPollVotes :execrows
+DELETE FROM poll_votes
+WHERE message_id = @message_id::bigint
+    AND user_pid = @user_pid::bigint
+    AND NOT (option_id = ANY (@option_ids::int[]));
+
+-- name: CastPollVotes :execrows
+INSERT INTO poll_votes (message_id, option_id, user_pid)
+SELECT
+    @message_id::bigint,
+    o.option_id,
+    @user_pid::bigint
+FROM
+    unnest(@option_ids::int[]) AS o (option_id)
+ON CONFLICT (message_id,
+    user_pid,
+    option_id)
+    DO NOTHING;
+
+-- name: ClosePoll :one
+UPDATE
+    polls
+SET
+    closed_at = now()
+WHERE
+    message_id = $1
+    AND closed_at IS NULL
+RETURNING
+    closed_at;
+
+-- name: CloseDuePolls :many
+UPDATE
+    polls p
+SET
+    closed_at = p.closes_at
+FROM (
+    SELECT
+        message_id
+    FROM
+        polls
+    WHERE
+        closed_at IS NULL
+        AND closes_at <= now()
+    ORDER BY
+        closes_at
+    LIMIT @batch_size::int
+    FOR UPDATE
+        SKIP LOCKED) due
+WHERE
+    p.message_id = due.message_id
+RETURNING
+    p.message_id,
+    p.conversation_id;
@@ -0,0 +1,38 @@
+-- name: GetPrivacySettings :one
+SELECT
+    user_pid,
+    bio_visibility,
+    last_seen_visibility,
+    profile_pic_visibility,
+    email_visibility,
+    who_can_message,
+    searchable,
+    updated_at,
+    version,
+    read_receipts,
+    forward_attribution
+FROM
+    privacy_settings
+WHERE
+    user_pid = $1;
+
+-- name: UpsertPrivacySettings :one
+INSERT INTO privacy_settings (user_pid, bio_visibility, last_seen_visibility, profile_pic_visibility, email_visibility, who_can_message, searchable, read_receipts, forward_attribution)
+    VALUES (@user_pid, @bio_visibility, @last_seen_visibility, @profile_pic_visibility, @email_visibility, @who_can_message, @searchable, @read_receipts, @forward_attribution)
+ON CONFLICT (user_pid)
+    DO UPDATE SET
+        bio_visibility = EXCLUDED.bio_visibility,
+        last_seen_visibility = EXCLUDED.last_seen_visibility,
+        profile_pic_visibility = EXCLUDED.profile_pic_visibility,
+        email_visibility = EXCLUDED.email_visibility,
+        who_can_message = EXCLUDED.who_can_message,
+        searchable = EXCLUDED.searchable,
+        read_receipts = EXCLUDED.read_receipts,
+        forward_attribution = EXCLUDED.forward_attribution,
+        updated_at = now(),
+        version = privacy_settings.version + 1
+    WHERE
+        privacy_settings.version = @version
+    RETURNING
+        updated_at,
+        version;
@@ -0,0 +1,105 @@
+-- name: InsertReaction :execrows
+INSERT INTO message_reactions (message_id, user_pid, emoji)
+SELECT
+    @message_id::bigint,
+    @user_pid::bigint,
+    @emoji::text
+WHERE
+    EXISTS (
+        SELECT
+            1
+        FROM
+            message_reactions
+        WHERE
+            message_id = @message_id::bigint
+            AND emoji = @emoji::text)
+    OR (
+        SELECT
+            count(DISTINCT emoji)
+        FROM
+            message_reactions
+        WHERE
+            message_id = @message_id::bigint) < @max_emoji::int
+ON CONFLICT (message_id,
+    user_pid,
+    emoji)
+    DO NOTHING;
+
+-- name: ReactionExists :one
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            message_reactions
+        WHERE
+            message_id = $1
+            AND user_pid = $2
+            AND emoji = $3)::bool AS reacted;
+
+-- name: DeleteReaction :execrows
+DELETE FROM message_reactions
+WHERE message_id = $1
+    AND user_pid = $2
+    AND emoji = $3;
+
+-- name: ListReactionSummaries :many
+SELECT
+    message_id,
+    emoji,
+    count(*) AS count,
+    bool_or(user_pid = @viewer_pid)::bool AS reacted
+FROM
+    message_reactions
+WHERE
+    message_id = ANY (@message_ids::bigint[])
+GROUP BY
+    message_id,
+    emoji
+ORDER BY
+    message_id,
+    min(created_at);
+
+-- name: ListReactionUsers :many
+SELECT
+    u.user_pid,
+    u.username,
+    u.name,
+    CASE WHEN profile_pic_visible (s.profile_pic_visibility, u.user_pid, @viewer_pid::bigint) THEN
+        u.profile_pic
+    END AS profile_pic
+FROM
+    message_reactions r
+    INNER JOIN users u ON u.user_pid = r.user_pid
+    LEFT JOIN privacy_settings s ON s.user_pid = u.user_pid
+WHERE
+    r.message_id = @message_id
+    AND r.emoji = @emoji
+    AND (NOT @has_cursor::bool
+        OR (@descending::bool
+            AND (u.username, u.user_pid) < (@cursor_username::citext, @cursor_id::bigint))
+        OR (NOT @descending::bool
+            AND (u.username, u.user_pid) > (@cursor_username::citext, @cursor_id::bigint)))
+ORDER BY
+    CASE WHEN @descending::bool THEN
+        u.username
+    END DESC,
+    CASE WHEN @descending::bool THEN
+        u.user_pid
+    END DESC,
+    CASE WHEN NOT @descending::bool THEN
+        u.username
+    END ASC,
+    CASE WHEN NOT @descending::bool THEN
+        u.user_pid
+    END ASC
+LIMIT @page_limit;
+
+-- name: CountReactionUsers :one
+SELECT
+    count(*)
+FROM
+    message_reactions
+WHERE
+    message_id = $1
+    AND emoji = $2;
@@ -0,0 +1,109 @@
+-- name: AdvanceDeliveredReceipt :one
+UPDATE
+    conversation_participants
+SET
+    last_delivered_id = @message_id
+WHERE
+    conversation_id = @conversation_id
+    AND user_pid = @user_pid
+    AND last_delivered_id < @message_id
+RETURNING
+    last_delivered_id,
+    last_read_id;
+
+-- name: AdvanceReadReceipt :one
+UPDATE
+    conversation_participants
+SET
+    last_delivered_id = GREATEST (last_delivered_id, @message_id),
+    last_read_id = @message_id
+WHERE
+    conversation_id = @conversation_id
+    AND user_pid = @user_pid
+    AND last_read_id < @message_id
+RETURNING
+    last_delivered_id,
+    last_read_id;
+
+-- name: ListMessageReceipts :many
+SELECT
+    u.user_pid,
+    u.username,
+    u.name,
+    CASE WHEN profile_pic_visible (s.profile_pic_visibility, u.user_pid, @viewer_pid::bigint) THEN
+        u.profile_pic
+    END AS profile_pic,
+    (p.last_read_id >= @message_id::bigint
+        AND COALESCE(s.read_receipts, TRUE))::bool AS seen
+FROM
+    conversation_participants p
+    INNER JOIN users u ON u.user_pid = p.user_pid
+    LEFT JOIN privacy_settings s ON s.user_pid = u.user_pid
+WHERE
+    p.conversation_id = @conversation_id
+    AND p.user_pid <> @sender_pid
+    AND p.last_delivered_id >= @message_id::bigint
+ORDER BY
+    u.username,
+    u.user_pid;
+
+-- name: GetReceipt :one
+SELECT
+    last_delivered_id,
+    last_read_id
+FROM
+    conversation_participants
+WHERE
+    conversation_id = @conversation_id
+    AND user_pid = @user_pid;
+
+-- name: GetLastMessageID :one
+SELECT
+    COALESCE(max(message_id), 0)::bigint AS message_id
+FROM
+    messages;
+
+-- name: ListUnreadCounts :many
+SELECT
+    p.conversation_id,
+    count(m.message_id) AS unread_count,
+    count(mm.message_id) AS mention_count
+FROM
+    conversation_participants p
+    INNER JOIN messages m ON m.conversation_id = p.conversation_id
+        AND m.message_id > p.last_read_id
+        AND m.message_id <= @counted_to::bigint
+        AND m.created_at >= p.joined_at
+    LEFT JOIN message_mentions mm ON mm.message_id = m.message_id
+        AND mm.user_pid = p.user_pid
+WHERE
+    p.user_pid = @user_pid
+    AND (@conversation_id::bigint = 0
+        OR p.conversation_id = @conversation_id)
+    AND m.kind <> 'system'
+    AND (m.thread_id IS NULL
+        OR mm.message_id IS NOT NULL
+        OR EXISTS (
+            SELECT
+                1
+            FROM
+                thread_followers f
+            WHERE
+                f.thread_id = m.thread_id
+                AND f.user_pid = p.user_pid
+                AND f.following))
+    AND m.deleted_at IS NULL
+    AND (m.expires_at IS NULL
+        OR m.expires_at > now())
+    AND (m.sender_pid IS NULL
+        OR m.sender_pid <> @user_pid)
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            hidden_messages h
+        WHERE
+            h.user_pid = @user_pid
+            AND h.message_id = m.message_id)
+GROUP BY
+    p.conversation_id;
@@ -0,0 +1,126 @@
+-- name: InsertScheduledMessage :one
+INSERT INTO scheduled_messages (conversation_id, sender_pid, body, send_at)
+    VALUES ($1, $2, $3, $4)
+RETURNING
+    scheduled_id, status, created_at, version;
+
+-- name: GetScheduledMessage :one
+SELECT
+    scheduled_id,
+    conversation_id,
+    sender_pid,
+    body,
+    send_at,
+    status,
+    error,
+    claimed_at,
+    created_at,
+    version
+FROM
+    scheduled_messages
+WHERE
+    scheduled_id = $1
+    AND conversation_id = $2
+    AND sender_pid = $3;
+
+-- name: ListScheduledMessages :many
+SELECT
+    scheduled_id,
+    conversation_id,
+    sender_pid,
+    body,
+    send_at,
+    status,
+    error,
+    claimed_at,
+    created_at,
+    version
+FROM
+    scheduled_messages
+WHERE
+    conversation_id = $1
+    AND sender_pid = $2
+ORDER BY
+    send_at,
+    scheduled_id;
+
+-- name: UpdateScheduledMessage :one
+UPDATE
+    scheduled_messages
+SET
+    body = @body,
+    send_at = @send_at,
+    status = 'pending',
+    error = NULL,
+    version = version + 1
+WHERE
+    scheduled_id = @scheduled_id
+    AND version = @version
+    AND status <> 'sending'
+RETURNING
+    status,
+    version;
+
+-- name: DeleteScheduledMessage :execrows
+DELETE FROM scheduled_messages
+WHERE scheduled_id = @scheduled_id
+    AND version = @version
+    AND status <> 'sending';
+
+-- name: CancelScheduledMessages :many
+DELETE FROM scheduled_messages
+WHERE conversation_id = $1
+    AND sender_pid = $2
+    AND status <> 'sending'
+RETURNING
+    scheduled_id;
+
+-- name: ClaimScheduledMessages :many
+UPDATE
+    scheduled_messages s
+SET
+    status = 'sending',
+    claimed_at = now(),
+    version = s.version + 1
+FROM (
+    SELECT
+        scheduled_id
+    FROM
+        scheduled_messages
+    WHERE (status = 'pending'
+        AND send_at <= now())
+        OR (status = 'sending'
+            AND claimed_at < @stale_before::timestamptz)
+    ORDER BY
+        send_at
+    LIMIT @batch_size::int
+    FOR UPDATE
+        SKIP LOCKED) due
+WHERE
+    s.scheduled_id = due.scheduled_id
+RETURNING
+    s.scheduled_id,
+    s.conversation_id,
+    s.sender_pid,
+    s.body,
+    s.send_at,
+    s.status,
+    s.error,
+    s.claimed_at,
+    s.created_at,
+    s.version;
+
+-- name: CompleteScheduledMessage :exec
+DELETE FROM scheduled_messages
+WHERE scheduled_id = $1;
+
+-- name: FailScheduledMessage :exec
+UPDATE
+    scheduled_messages
+SET
+    status = 'failed',
+    error = $2,
+    claimed_at = NULL,
+    version = version + 1
+WHERE
+    scheduled_id = $1;
@@ -0,0 +1,37 @@
+-- name: FollowThread :exec
+INSERT INTO thread_followers (thread_id, user_pid, following)
+    VALUES ($1, $2, $3)
+ON CONFLICT (thread_id, user_pid)
+    DO UPDATE SET
+        following = EXCLUDED.following,
+        updated_at = now();
+
+-- name: AutoFollowThread :exec
+INSERT INTO thread_followers (thread_id, user_pid)
+    VALUES ($1, $2)
+ON CONFLICT (thread_id, user_pid)
+    DO NOTHING;
+
+-- name: IsFollowingThread :one
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            thread_followers
+        WHERE
+            thread_id = $1
+            AND user_pid = $2
+            AND following)::bool AS following;
+
+-- name: ListThreadFollowerIDs :many
+SELECT
+    f.user_pid
+FROM
+    thread_followers f
+    INNER JOIN messages m ON m.message_id = f.thread_id
+    INNER JOIN conversation_participants p ON p.conversation_id = m.conversation_id
+        AND p.user_pid = f.user_pid
+WHERE
+    f.thread_id = $1
+    AND f.following;
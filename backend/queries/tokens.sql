@@ -19,7 +19,8 @@ SELECT
     u.profile_pic,
     u.created_at,
     u.updated_at,
-    u.version
+    u.version,
+    u.last_seen
 FROM
     users u
     INNER JOIN tokens t ON u.user_pid = t.user_id
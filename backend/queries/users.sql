@@ -10,7 +10,8 @@ SELECT
     profile_pic,
     created_at,
     updated_at,
-    version
+    version,
+    last_seen
 FROM
     users
 WHERE
@@ -28,7 +29,8 @@ SELECT
     profile_pic,
     created_at,
     updated_at,
-    version
+    version,
+    last_seen
 FROM
     users
 WHERE
@@ -46,7 +48,8 @@ SELECT
     profile_pic,
     created_at,
     updated_at,
-    version
+    version,
+    last_seen
 FROM
     users
 WHERE
@@ -65,7 +68,8 @@ SELECT
     profile_pic,
     created_at,
     updated_at,
-    version
+    version,
+    last_seen
 FROM
     users
 WHERE
@@ -114,3 +118,59 @@ RETURNING
 DELETE FROM users
 WHERE user_pid = $1;
 
+
+-- name: SearchUsers :many
+SELECT
+    u.user_pid,
+    u.username,
+    u.name,
+    CASE WHEN profile_pic_visible (p.profile_pic_visibility, u.user_pid, @user_pid::bigint) THEN
+        u.profile_pic
+    END AS profile_pic
+FROM
+    users u
+    LEFT JOIN privacy_settings p ON p.user_pid = u.user_pid
+WHERE (u.username ILIKE @query::text || '%'
+    OR u.name ILIKE '%' || @query::text || '%')
+    AND COALESCE(p.searchable, TRUE)
+    AND u.user_pid <> @user_pid
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            blocks b
+        WHERE
+            b.blocker_pid = u.user_pid
+            AND b.blocked_pid = @user_pid)
+    AND (NOT @has_cursor::bool
+        OR (@backward::bool
+            AND u.username < @cursor_username::citext)
+        OR (NOT @backward::bool
+            AND u.username > @cursor_username::citext))
+ORDER BY
+    CASE WHEN @backward::bool THEN
+        u.username
+    END DESC,
+    CASE WHEN NOT @backward::bool THEN
+        u.username
+    END ASC
+LIMIT @page_limit;
+
+-- name: UpdateLastSeen :exec
+UPDATE
+    users
+SET
+    last_seen = @last_seen
+WHERE
+    user_pid = @user_pid;
+
+-- name: ListLastSeen :many
+SELECT
+    u.user_pid,
+    u.last_seen,
+    COALESCE(s.last_seen_visibility, 'contacts')::text AS last_seen_visibility
+FROM
+    users u
+    LEFT JOIN privacy_settings s ON s.user_pid = u.user_pid
+WHERE
+    u.user_pid = ANY (@user_pids::bigint[]);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS contacts (
+    requester_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    addressee_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    status text NOT NULL DEFAULT 'pending',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (requester_pid, addressee_pid),
+    CONSTRAINT contacts_not_self CHECK (requester_pid <> addressee_pid),
+    CONSTRAINT contacts_status_check CHECK (status IN ('pending', 'accepted', 'declined'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS contacts_pair_idx ON contacts (LEAST(requester_pid, addressee_pid), GREATEST(requester_pid, addressee_pid));
+
+CREATE INDEX IF NOT EXISTS contacts_addressee_idx ON contacts (addressee_pid, status);
+
+CREATE TABLE IF NOT EXISTS blocks (
+    blocker_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    blocked_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (blocker_pid, blocked_pid),
+    CONSTRAINT blocks_not_self CHECK (blocker_pid <> blocked_pid)
+);
+
+CREATE INDEX IF NOT EXISTS blocks_blocked_idx ON blocks (blocked_pid);
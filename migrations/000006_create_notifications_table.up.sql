@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    notification_id bigint PRIMARY KEY DEFAULT next_id (),
+    user_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    actor_pid bigint REFERENCES users ON DELETE CASCADE,
+    kind text NOT NULL,
+    payload jsonb NOT NULL DEFAULT '{}',
+    read_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_idx ON notifications (user_pid, notification_id DESC);
@@ -0,0 +1,4 @@
+DROP FUNCTION IF EXISTS profile_pic_visible (text, bigint, bigint);
+
+DROP TABLE IF EXISTS privacy_settings;
+
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS privacy_settings (
+    user_pid bigint PRIMARY KEY REFERENCES users ON DELETE CASCADE,
+    bio_visibility text NOT NULL DEFAULT 'everyone',
+    last_seen_visibility text NOT NULL DEFAULT 'contacts',
+    profile_pic_visibility text NOT NULL DEFAULT 'everyone',
+    email_visibility text NOT NULL DEFAULT 'nobody',
+    who_can_message text NOT NULL DEFAULT 'everyone',
+    searchable bool NOT NULL DEFAULT TRUE,
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    version int NOT NULL DEFAULT 1,
+    CONSTRAINT privacy_settings_visibility_check CHECK (bio_visibility IN ('everyone', 'contacts', 'nobody')
+        AND last_seen_visibility IN ('everyone', 'contacts', 'nobody')
+        AND profile_pic_visibility IN ('everyone', 'contacts', 'nobody')
+        AND email_visibility IN ('everyone', 'contacts', 'nobody')
+        AND who_can_message IN ('everyone', 'contacts', 'nobody'))
+);
+
+-- profile_pic_visible applies a user's profile picture visibility setting for
+-- a viewer. Users without settings show their picture to everyone.
+CREATE OR REPLACE FUNCTION profile_pic_visible (visibility text, owner_pid bigint, viewer_pid bigint)
+    RETURNS boolean
+    AS $$
+    SELECT
+        owner_pid = viewer_pid
+        OR COALESCE(visibility, 'everyone') = 'everyone'
+        OR (visibility = 'contacts'
+            AND EXISTS (
+                SELECT
+                    1
+                FROM
+                    contacts ct
+                WHERE
+                    ct.status = 'accepted'
+                    AND ((ct.requester_pid = owner_pid
+                            AND ct.addressee_pid = viewer_pid)
+                        OR (ct.requester_pid = viewer_pid
+                            AND ct.addressee_pid = owner_pid))));
+$$
+LANGUAGE SQL
+STABLE;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS conversation_participants;
+
+DROP TABLE IF EXISTS conversations;
+
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS conversations (
+    conversation_id bigint PRIMARY KEY DEFAULT next_id (),
+    kind text NOT NULL DEFAULT 'direct',
+    direct_key text UNIQUE,
+    last_message_id bigint,
+    last_message_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    version int NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS conversation_participants (
+    conversation_id bigint NOT NULL REFERENCES conversations ON DELETE CASCADE,
+    user_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    joined_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (conversation_id, user_pid)
+);
+
+CREATE INDEX IF NOT EXISTS conversation_participants_user_idx ON conversation_participants (user_pid);
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS messages_touch_conversation ON messages;
+
+DROP FUNCTION IF EXISTS touch_conversation ();
+
+DROP TABLE IF EXISTS messages;
+
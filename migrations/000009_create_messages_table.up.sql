@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS messages (
+    message_id bigint PRIMARY KEY DEFAULT next_id (),
+    conversation_id bigint NOT NULL REFERENCES conversations ON DELETE CASCADE,
+    sender_pid bigint REFERENCES users ON DELETE SET NULL,
+    kind text NOT NULL DEFAULT 'text',
+    body text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS messages_conversation_idx ON messages (conversation_id, message_id DESC);
+
+-- System messages don't count as activity, so they neither move the
+-- conversation up the list nor become its last message.
+CREATE OR REPLACE FUNCTION touch_conversation ()
+    RETURNS TRIGGER
+    AS $$
+BEGIN
+    UPDATE
+        conversations
+    SET
+        last_message_id = NEW.message_id,
+        last_message_at = NEW.created_at,
+        updated_at = now()
+    WHERE
+        conversation_id = NEW.conversation_id;
+    RETURN NEW;
+END;
+$$
+LANGUAGE PLPGSQL;
+
+DROP TRIGGER IF EXISTS messages_touch_conversation ON messages;
+
+CREATE TRIGGER messages_touch_conversation
+    AFTER INSERT ON messages
+    FOR EACH ROW
+    WHEN (NEW.kind <> 'system')
+    EXECUTE FUNCTION touch_conversation ();
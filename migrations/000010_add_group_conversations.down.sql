@@ -0,0 +1,10 @@
+ALTER TABLE conversation_participants
+    DROP CONSTRAINT IF EXISTS conversation_participants_role_check,
+    DROP COLUMN IF EXISTS role;
+
+ALTER TABLE conversations
+    DROP CONSTRAINT IF EXISTS conversations_kind_check,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS avatar,
+    DROP COLUMN IF EXISTS title;
+
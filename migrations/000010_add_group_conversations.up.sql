@@ -0,0 +1,19 @@
+ALTER TABLE conversations
+    ADD COLUMN IF NOT EXISTS title text,
+    ADD COLUMN IF NOT EXISTS avatar text,
+    ADD COLUMN IF NOT EXISTS description text;
+
+ALTER TABLE conversations
+    DROP CONSTRAINT IF EXISTS conversations_kind_check;
+
+ALTER TABLE conversations
+    ADD CONSTRAINT conversations_kind_check CHECK (kind IN ('direct', 'group'));
+
+ALTER TABLE conversation_participants
+    ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'member';
+
+ALTER TABLE conversation_participants
+    DROP CONSTRAINT IF EXISTS conversation_participants_role_check;
+
+ALTER TABLE conversation_participants
+    ADD CONSTRAINT conversation_participants_role_check CHECK (role IN ('owner', 'admin', 'member'));
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS message_edits;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS edited_at;
+
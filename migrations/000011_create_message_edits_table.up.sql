@@ -0,0 +1,12 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS edited_at timestamp(0) with time zone,
+    ADD COLUMN IF NOT EXISTS version int NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS message_edits (
+    message_id bigint NOT NULL REFERENCES messages ON DELETE CASCADE,
+    version int NOT NULL,
+    body text NOT NULL,
+    editor_pid bigint REFERENCES users ON DELETE SET NULL,
+    replaced_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (message_id, version)
+);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS hidden_messages;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS deleter_pid,
+    DROP COLUMN IF EXISTS deleted_at;
+
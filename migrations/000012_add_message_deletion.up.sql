@@ -0,0 +1,10 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS deleted_at timestamp(0) with time zone,
+    ADD COLUMN IF NOT EXISTS deleter_pid bigint REFERENCES users ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS hidden_messages (
+    user_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    message_id bigint NOT NULL REFERENCES messages ON DELETE CASCADE,
+    hidden_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_pid, message_id)
+);
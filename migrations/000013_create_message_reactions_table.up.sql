@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS message_reactions (
+    message_id bigint NOT NULL REFERENCES messages ON DELETE CASCADE,
+    user_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    emoji text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (message_id, user_pid, emoji)
+);
+
+CREATE INDEX IF NOT EXISTS message_reactions_emoji_idx ON message_reactions (message_id, emoji);
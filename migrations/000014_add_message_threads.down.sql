@@ -0,0 +1,22 @@
+DROP TABLE IF EXISTS thread_followers;
+
+DROP TRIGGER IF EXISTS messages_touch_thread ON messages;
+
+DROP FUNCTION IF EXISTS touch_thread ();
+
+DROP TRIGGER IF EXISTS messages_touch_conversation ON messages;
+
+CREATE TRIGGER messages_touch_conversation
+    AFTER INSERT ON messages
+    FOR EACH ROW
+    WHEN (NEW.kind <> 'system')
+    EXECUTE FUNCTION touch_conversation ();
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS thread_participant_ids,
+    DROP COLUMN IF EXISTS last_reply_at,
+    DROP COLUMN IF EXISTS reply_count,
+    DROP COLUMN IF EXISTS quote,
+    DROP COLUMN IF EXISTS thread_id,
+    DROP COLUMN IF EXISTS parent_id;
+
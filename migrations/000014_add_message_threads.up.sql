@@ -0,0 +1,58 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS parent_id bigint REFERENCES messages ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS thread_id bigint REFERENCES messages ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS quote jsonb,
+    ADD COLUMN IF NOT EXISTS reply_count int NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_reply_at timestamp(0) with time zone,
+    ADD COLUMN IF NOT EXISTS thread_participant_ids bigint[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS messages_thread_idx ON messages (thread_id, message_id DESC)
+WHERE
+    thread_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION touch_thread ()
+    RETURNS TRIGGER
+    AS $$
+BEGIN
+    UPDATE
+        messages
+    SET
+        reply_count = reply_count + 1,
+        last_reply_at = NEW.created_at,
+        thread_participant_ids = CASE WHEN NEW.sender_pid IS NULL
+            OR NEW.sender_pid = ANY (thread_participant_ids) THEN
+            thread_participant_ids
+        ELSE
+            array_append(thread_participant_ids, NEW.sender_pid)
+        END
+    WHERE
+        message_id = NEW.thread_id;
+    RETURN NEW;
+END;
+$$
+LANGUAGE PLPGSQL;
+
+DROP TRIGGER IF EXISTS messages_touch_thread ON messages;
+
+CREATE TRIGGER messages_touch_thread
+    AFTER INSERT ON messages
+    FOR EACH ROW
+    WHEN (NEW.thread_id IS NOT NULL)
+    EXECUTE FUNCTION touch_thread ();
+
+-- Thread replies only bump their thread, not the conversation.
+DROP TRIGGER IF EXISTS messages_touch_conversation ON messages;
+
+CREATE TRIGGER messages_touch_conversation
+    AFTER INSERT ON messages
+    FOR EACH ROW
+    WHEN (NEW.kind <> 'system' AND NEW.thread_id IS NULL)
+    EXECUTE FUNCTION touch_conversation ();
+
+CREATE TABLE IF NOT EXISTS thread_followers (
+    thread_id bigint NOT NULL REFERENCES messages ON DELETE CASCADE,
+    user_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    following boolean NOT NULL DEFAULT TRUE,
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (thread_id, user_pid)
+);
@@ -0,0 +1,7 @@
+ALTER TABLE privacy_settings
+    DROP COLUMN IF EXISTS read_receipts;
+
+ALTER TABLE conversation_participants
+    DROP COLUMN IF EXISTS last_read_id,
+    DROP COLUMN IF EXISTS last_delivered_id;
+
@@ -0,0 +1,6 @@
+ALTER TABLE conversation_participants
+    ADD COLUMN IF NOT EXISTS last_delivered_id bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_read_id bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE privacy_settings
+    ADD COLUMN IF NOT EXISTS read_receipts bool NOT NULL DEFAULT TRUE;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS attachments;
+
+DROP TABLE IF EXISTS uploads;
+
+DROP TABLE IF EXISTS blobs;
+
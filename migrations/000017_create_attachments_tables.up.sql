@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS blobs (
+    sha256 text PRIMARY KEY,
+    size bigint NOT NULL,
+    content_type text NOT NULL,
+    storage_key text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    last_used_at timestamp(0) with time zone NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS uploads (
+    upload_id bigint PRIMARY KEY DEFAULT next_id (),
+    user_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    filename text NOT NULL,
+    size bigint NOT NULL,
+    upload_offset bigint NOT NULL DEFAULT 0,
+    content_type text NOT NULL DEFAULT 'application/octet-stream',
+    hash_state bytea,
+    part_keys text[] NOT NULL DEFAULT '{}',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    expires_at timestamp(0) with time zone NOT NULL,
+    CONSTRAINT uploads_offset_check CHECK (upload_offset BETWEEN 0 AND size)
+);
+
+CREATE INDEX IF NOT EXISTS uploads_user_idx ON uploads (user_pid);
+
+CREATE TABLE IF NOT EXISTS attachments (
+    attachment_id bigint PRIMARY KEY DEFAULT next_id (),
+    user_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    message_id bigint REFERENCES messages ON DELETE CASCADE,
+    sha256 text NOT NULL REFERENCES blobs,
+    filename text NOT NULL,
+    size bigint NOT NULL,
+    content_type text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS attachments_message_idx ON attachments (message_id);
+
+CREATE INDEX IF NOT EXISTS attachments_user_idx ON attachments (user_pid);
+
+CREATE INDEX IF NOT EXISTS attachments_sha256_idx ON attachments (sha256);
@@ -0,0 +1,11 @@
+ALTER TABLE blobs
+    DROP CONSTRAINT IF EXISTS blobs_status_check;
+
+ALTER TABLE blobs
+    DROP COLUMN IF EXISTS thumbnail_type,
+    DROP COLUMN IF EXISTS thumbnail_sizes,
+    DROP COLUMN IF EXISTS blurhash,
+    DROP COLUMN IF EXISTS duration_ms,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS width,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,14 @@
+ALTER TABLE blobs
+    ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'ready',
+    ADD COLUMN IF NOT EXISTS width int,
+    ADD COLUMN IF NOT EXISTS height int,
+    ADD COLUMN IF NOT EXISTS duration_ms bigint,
+    ADD COLUMN IF NOT EXISTS blurhash text,
+    ADD COLUMN IF NOT EXISTS thumbnail_sizes int[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS thumbnail_type text;
+
+ALTER TABLE blobs
+    DROP CONSTRAINT IF EXISTS blobs_status_check;
+
+ALTER TABLE blobs
+    ADD CONSTRAINT blobs_status_check CHECK (status IN ('pending', 'ready', 'failed'));
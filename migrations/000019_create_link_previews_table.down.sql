@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS link_previews;
+
+ALTER TABLE conversations
+    DROP COLUMN IF EXISTS link_previews;
@@ -0,0 +1,12 @@
+ALTER TABLE conversations
+    ADD COLUMN IF NOT EXISTS link_previews boolean NOT NULL DEFAULT TRUE;
+
+CREATE TABLE IF NOT EXISTS link_previews (
+    message_id bigint PRIMARY KEY REFERENCES messages ON DELETE CASCADE,
+    url text NOT NULL,
+    title text,
+    description text,
+    image_url text,
+    site_name text,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now()
+);
@@ -0,0 +1,13 @@
+ALTER TABLE conversation_participants
+    DROP COLUMN IF EXISTS muted_until;
+
+ALTER TABLE conversations
+    DROP CONSTRAINT IF EXISTS conversations_mention_all_check;
+
+ALTER TABLE conversations
+    DROP COLUMN IF EXISTS mention_all;
+
+DROP TABLE IF EXISTS message_mentions;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS entities;
@@ -0,0 +1,22 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS entities jsonb;
+
+CREATE TABLE IF NOT EXISTS message_mentions (
+    message_id bigint NOT NULL REFERENCES messages ON DELETE CASCADE,
+    user_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    PRIMARY KEY (message_id, user_pid)
+);
+
+CREATE INDEX IF NOT EXISTS message_mentions_user_idx ON message_mentions (user_pid, message_id);
+
+ALTER TABLE conversations
+    ADD COLUMN IF NOT EXISTS mention_all text NOT NULL DEFAULT 'admins';
+
+ALTER TABLE conversations
+    DROP CONSTRAINT IF EXISTS conversations_mention_all_check;
+
+ALTER TABLE conversations
+    ADD CONSTRAINT conversations_mention_all_check CHECK (mention_all IN ('everyone', 'admins'));
+
+ALTER TABLE conversation_participants
+    ADD COLUMN IF NOT EXISTS muted_until timestamp(0) with time zone;
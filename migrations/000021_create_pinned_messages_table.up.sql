@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS pinned_messages (
+    message_id bigint PRIMARY KEY REFERENCES messages ON DELETE CASCADE,
+    conversation_id bigint NOT NULL REFERENCES conversations ON DELETE CASCADE,
+    pinner_pid bigint REFERENCES users ON DELETE SET NULL,
+    pinned_at timestamp(0) with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS pinned_messages_conversation_idx ON pinned_messages (conversation_id, pinned_at);
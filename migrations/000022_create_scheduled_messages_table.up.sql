@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS scheduled_messages (
+    scheduled_id bigint PRIMARY KEY DEFAULT next_id (),
+    conversation_id bigint NOT NULL REFERENCES conversations ON DELETE CASCADE,
+    sender_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    body text NOT NULL,
+    send_at timestamp(0) with time zone NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    error text,
+    claimed_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    version int NOT NULL DEFAULT 1,
+    CONSTRAINT scheduled_messages_status_check CHECK (status IN ('pending', 'sending', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_messages_due_idx ON scheduled_messages (send_at)
+WHERE
+    status <> 'failed';
+
+CREATE INDEX IF NOT EXISTS scheduled_messages_sender_idx ON scheduled_messages (sender_pid, conversation_id);
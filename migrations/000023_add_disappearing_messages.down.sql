@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS messages_expires_at_idx;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS expires_at;
+
+ALTER TABLE conversations
+    DROP CONSTRAINT IF EXISTS conversations_message_ttl_check;
+
+ALTER TABLE conversations
+    DROP COLUMN IF EXISTS message_ttl;
@@ -0,0 +1,15 @@
+ALTER TABLE conversations
+    ADD COLUMN IF NOT EXISTS message_ttl int NOT NULL DEFAULT 0;
+
+ALTER TABLE conversations
+    DROP CONSTRAINT IF EXISTS conversations_message_ttl_check;
+
+ALTER TABLE conversations
+    ADD CONSTRAINT conversations_message_ttl_check CHECK (message_ttl IN (0, 3600, 86400, 604800));
+
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS expires_at timestamp(0) with time zone;
+
+CREATE INDEX IF NOT EXISTS messages_expires_at_idx ON messages (expires_at)
+WHERE
+    expires_at IS NOT NULL;
@@ -0,0 +1,8 @@
+ALTER TABLE privacy_settings
+    DROP COLUMN IF EXISTS forward_attribution;
+
+ALTER TABLE conversations
+    DROP COLUMN IF EXISTS allow_forwarding;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS forward;
@@ -0,0 +1,8 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS forward jsonb;
+
+ALTER TABLE conversations
+    ADD COLUMN IF NOT EXISTS allow_forwarding boolean NOT NULL DEFAULT TRUE;
+
+ALTER TABLE privacy_settings
+    ADD COLUMN IF NOT EXISTS forward_attribution bool NOT NULL DEFAULT TRUE;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS polls (
+    message_id bigint PRIMARY KEY REFERENCES messages ON DELETE CASCADE,
+    conversation_id bigint NOT NULL REFERENCES conversations ON DELETE CASCADE,
+    question text NOT NULL,
+    options text[] NOT NULL,
+    multiple_choice bool NOT NULL DEFAULT FALSE,
+    anonymous bool NOT NULL DEFAULT FALSE,
+    closes_at timestamp(0) with time zone,
+    closed_at timestamp(0) with time zone,
+    CONSTRAINT polls_options_check CHECK (cardinality(options) BETWEEN 2 AND 10)
+);
+
+CREATE INDEX IF NOT EXISTS polls_closes_at_idx ON polls (closes_at)
+WHERE
+    closed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    message_id bigint NOT NULL REFERENCES polls ON DELETE CASCADE,
+    option_id int NOT NULL,
+    user_pid bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (message_id, user_pid, option_id)
+);